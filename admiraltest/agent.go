@@ -0,0 +1,169 @@
+package admiraltest
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	accesstokenv1 "go.admiral.io/sdk/proto/accesstoken/v1"
+	agentv1 "go.admiral.io/sdk/proto/agent/v1"
+	authzv1 "go.admiral.io/sdk/proto/authz/v1"
+)
+
+type agentServer struct {
+	agentv1.UnimplementedAgentAPIServer
+	store *store
+}
+
+func (s *agentServer) RegisterAgent(ctx context.Context, req *agentv1.RegisterAgentRequest) (*agentv1.RegisterAgentResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	token, err := st.callerAgentToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case token.ClusterId != "":
+		cs, err := st.cluster(token.ClusterId)
+		if err != nil {
+			return nil, err
+		}
+		uid := req.GetKubernetes().GetClusterUid()
+		if uid == "" {
+			return nil, status.Error(codes.InvalidArgument, "cluster-bound agents must provide kubernetes metadata")
+		}
+		if cs.cluster.ClusterUid != "" && cs.cluster.ClusterUid != uid {
+			return nil, status.Errorf(codes.Aborted, "cluster %q is already bound to a different cluster_uid", cs.cluster.Id)
+		}
+		cs.cluster.ClusterUid = uid
+	case token.RunnerId != "":
+		if _, err := st.runner(token.RunnerId); err != nil {
+			return nil, err
+		}
+		if req.GetRunner() == nil {
+			return nil, status.Error(codes.InvalidArgument, "runner-bound agents must provide runner metadata")
+		}
+	}
+
+	now := st.now()
+	agent, ok := st.agents[st.agentByToken[token.Id]]
+	if !ok {
+		agent = &agentv1.Agent{
+			Id:        newID(),
+			TenantId:  st.tenantID(),
+			ClusterId: token.ClusterId,
+			RunnerId:  token.RunnerId,
+			CreatedAt: now,
+		}
+		st.agents[agent.Id] = agent
+		st.agentByToken[token.Id] = agent.Id
+	}
+	agent.DisplayName = req.GetDisplayName()
+	agent.Version = req.GetVersion()
+	agent.Status = agentv1.AgentStatus_AGENT_STATUS_ONLINE
+	agent.LastHeartbeatAt = now
+	agent.UpdatedAt = now
+
+	return &agentv1.RegisterAgentResponse{
+		Agent:                     clone(agent),
+		PollIntervalSeconds:       durationSeconds(st.opts.pollInterval),
+		StatusPushIntervalSeconds: durationSeconds(st.opts.statusPushInterval),
+	}, nil
+}
+
+func (s *agentServer) GetAgent(_ context.Context, req *agentv1.GetAgentRequest) (*agentv1.GetAgentResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	agent, ok := st.agents[req.GetAgentId()]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "agent %q not found", req.GetAgentId())
+	}
+	return &agentv1.GetAgentResponse{Agent: st.agentView(agent)}, nil
+}
+
+func (s *agentServer) ListAgents(_ context.Context, req *agentv1.ListAgentsRequest) (*agentv1.ListAgentsResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	items := make([]*agentv1.Agent, 0, len(st.agents))
+	for _, a := range st.agents {
+		items = append(items, st.agentView(a))
+	}
+	page, next, err := listPage(req, items, (*agentv1.Agent).GetCreatedAt, req.GetPageSize(), req.GetPageToken(), req.GetFilter())
+	if err != nil {
+		return nil, err
+	}
+	return &agentv1.ListAgentsResponse{Agents: page, NextPageToken: next}, nil
+}
+
+func (s *agentServer) Heartbeat(ctx context.Context, req *agentv1.HeartbeatRequest) (*agentv1.HeartbeatResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	token, err := st.callerAgentToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	agent, ok := st.agents[req.GetAgentId()]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "agent %q not found", req.GetAgentId())
+	}
+	if st.agentByToken[token.Id] != agent.Id {
+		return nil, status.Errorf(codes.PermissionDenied, "token is not bound to agent %q", agent.Id)
+	}
+
+	now := st.now()
+	if req.GetVersion() != "" {
+		agent.Version = req.GetVersion()
+	}
+	agent.Status = agentv1.AgentStatus_AGENT_STATUS_ONLINE
+	agent.LastHeartbeatAt = now
+	agent.UpdatedAt = now
+
+	return &agentv1.HeartbeatResponse{
+		Ok:                        true,
+		PollIntervalSeconds:       durationSeconds(st.opts.pollInterval),
+		StatusPushIntervalSeconds: durationSeconds(st.opts.statusPushInterval),
+	}, nil
+}
+
+// callerAgentToken resolves the caller's credentials to an agent token. The
+// caller must hold mu.
+func (st *store) callerAgentToken(ctx context.Context) (*accesstokenv1.AccessToken, error) {
+	token, err := st.callerToken(bearerToken(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if token.TokenType != authzv1.TokenType_AGENT_TOKEN {
+		return nil, status.Error(codes.PermissionDenied, "token type not allowed")
+	}
+	return token, nil
+}
+
+// agentStatus reports an ONLINE agent as UNREACHABLE once no heartbeat has
+// arrived within three poll intervals. The caller must hold mu.
+func (st *store) agentStatus(a *agentv1.Agent) agentv1.AgentStatus {
+	if a.Status != agentv1.AgentStatus_AGENT_STATUS_ONLINE {
+		return a.Status
+	}
+	if st.opts.now().Sub(a.LastHeartbeatAt.AsTime()) > 3*st.opts.pollInterval {
+		return agentv1.AgentStatus_AGENT_STATUS_UNREACHABLE
+	}
+	return a.Status
+}
+
+// agentView returns a copy of the agent with its derived status. The caller
+// must hold mu.
+func (st *store) agentView(a *agentv1.Agent) *agentv1.Agent {
+	c := clone(a)
+	c.Status = st.agentStatus(a)
+	return c
+}
@@ -0,0 +1,370 @@
+package admiraltest
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	accesstokenv1 "go.admiral.io/sdk/proto/accesstoken/v1"
+	agentv1 "go.admiral.io/sdk/proto/agent/v1"
+	authzv1 "go.admiral.io/sdk/proto/authz/v1"
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+)
+
+// maxEvents bounds the events retained per cluster.
+const maxEvents = 1000
+
+type clusterServer struct {
+	clusterv1.UnimplementedClusterAPIServer
+	store *store
+}
+
+func (s *clusterServer) CreateCluster(_ context.Context, req *clusterv1.CreateClusterRequest) (*clusterv1.CreateClusterResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	for _, cs := range st.clusters {
+		if cs.cluster.DisplayName == req.GetDisplayName() {
+			return nil, status.Errorf(codes.AlreadyExists, "cluster %q already exists", req.GetDisplayName())
+		}
+	}
+
+	now := st.now()
+	cluster := &clusterv1.Cluster{
+		Id:           newID(),
+		TenantId:     st.tenantID(),
+		DisplayName:  req.GetDisplayName(),
+		Labels:       maps.Clone(req.GetLabels()),
+		HealthStatus: clusterv1.ClusterHealthStatus_CLUSTER_HEALTH_STATUS_PENDING,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	st.clusters[cluster.Id] = &clusterState{cluster: cluster, workloads: map[string]*clusterv1.Workload{}}
+	token := st.issueToken(authzv1.TokenType_AGENT_TOKEN, req.GetDisplayName(), clusterAgentScopes, nil, func(t *accesstokenv1.AccessToken) {
+		t.ClusterId = cluster.Id
+	})
+
+	return &clusterv1.CreateClusterResponse{
+		Cluster:        st.clusterView(st.clusters[cluster.Id]),
+		PlainTextToken: token.secret,
+	}, nil
+}
+
+func (s *clusterServer) GetCluster(_ context.Context, req *clusterv1.GetClusterRequest) (*clusterv1.GetClusterResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	cs, err := st.cluster(req.GetClusterId())
+	if err != nil {
+		return nil, err
+	}
+	return &clusterv1.GetClusterResponse{Cluster: st.clusterView(cs)}, nil
+}
+
+func (s *clusterServer) GetClusterStatus(_ context.Context, req *clusterv1.GetClusterStatusRequest) (*clusterv1.GetClusterStatusResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	cs, err := st.cluster(req.GetClusterId())
+	if err != nil {
+		return nil, err
+	}
+	resp := &clusterv1.GetClusterStatusResponse{
+		HealthStatus: st.clusterView(cs).HealthStatus,
+		ReportedAt:   cs.reportedAt,
+	}
+	if cs.status != nil {
+		resp.Status = clone(cs.status)
+	}
+	return resp, nil
+}
+
+func (s *clusterServer) ListClusters(_ context.Context, req *clusterv1.ListClustersRequest) (*clusterv1.ListClustersResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	items := make([]*clusterv1.Cluster, 0, len(st.clusters))
+	for _, cs := range st.clusters {
+		items = append(items, st.clusterView(cs))
+	}
+	page, next, err := listPage(req, items, (*clusterv1.Cluster).GetCreatedAt, req.GetPageSize(), req.GetPageToken(), req.GetFilter())
+	if err != nil {
+		return nil, err
+	}
+	return &clusterv1.ListClustersResponse{Clusters: page, NextPageToken: next}, nil
+}
+
+func (s *clusterServer) UpdateCluster(_ context.Context, req *clusterv1.UpdateClusterRequest) (*clusterv1.UpdateClusterResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	cs, err := st.cluster(req.GetCluster().GetId())
+	if err != nil {
+		return nil, err
+	}
+	updated := clone(cs.cluster)
+	if err := applyMask(updated, req.GetCluster(), req.GetUpdateMask(), "display_name", "labels"); err != nil {
+		return nil, err
+	}
+	for id, other := range st.clusters {
+		if id != updated.Id && other.cluster.DisplayName == updated.DisplayName {
+			return nil, status.Errorf(codes.AlreadyExists, "cluster %q already exists", updated.DisplayName)
+		}
+	}
+	updated.UpdatedAt = st.now()
+	cs.cluster = updated
+	return &clusterv1.UpdateClusterResponse{Cluster: st.clusterView(cs)}, nil
+}
+
+func (s *clusterServer) DeleteCluster(_ context.Context, req *clusterv1.DeleteClusterRequest) (*clusterv1.DeleteClusterResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if _, err := st.cluster(req.GetClusterId()); err != nil {
+		return nil, err
+	}
+	delete(st.clusters, req.GetClusterId())
+	for _, ts := range st.listTokens(clusterTokens(req.GetClusterId())) {
+		ts.Status = accesstokenv1.AccessTokenStatus_ACCESS_TOKEN_STATUS_REVOKED
+		ts.RevokedAt = st.now()
+	}
+	for id, a := range st.agents {
+		if a.ClusterId == req.GetClusterId() {
+			delete(st.agents, id)
+		}
+	}
+	return &clusterv1.DeleteClusterResponse{}, nil
+}
+
+func (s *clusterServer) CreateClusterToken(_ context.Context, req *clusterv1.CreateClusterTokenRequest) (*clusterv1.CreateClusterTokenResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if _, err := st.cluster(req.GetClusterId()); err != nil {
+		return nil, err
+	}
+	token := st.issueToken(authzv1.TokenType_AGENT_TOKEN, req.GetDisplayName(), clusterAgentScopes, req.GetExpiresAt(), func(t *accesstokenv1.AccessToken) {
+		t.ClusterId = req.GetClusterId()
+	})
+	return &clusterv1.CreateClusterTokenResponse{AccessToken: clone(token.token), PlainTextToken: token.secret}, nil
+}
+
+func (s *clusterServer) ListClusterTokens(_ context.Context, req *clusterv1.ListClusterTokensRequest) (*clusterv1.ListClusterTokensResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if _, err := st.cluster(req.GetClusterId()); err != nil {
+		return nil, err
+	}
+	page, next, err := listPage(req, st.listTokens(clusterTokens(req.GetClusterId())), (*accesstokenv1.AccessToken).GetCreatedAt, req.GetPageSize(), req.GetPageToken(), req.GetFilter())
+	if err != nil {
+		return nil, err
+	}
+	return &clusterv1.ListClusterTokensResponse{AccessTokens: page, NextPageToken: next}, nil
+}
+
+func (s *clusterServer) GetClusterToken(_ context.Context, req *clusterv1.GetClusterTokenRequest) (*clusterv1.GetClusterTokenResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	token, err := st.getToken(req.GetTokenId(), clusterTokens(req.GetClusterId()))
+	if err != nil {
+		return nil, err
+	}
+	return &clusterv1.GetClusterTokenResponse{AccessToken: token}, nil
+}
+
+func (s *clusterServer) RevokeClusterToken(_ context.Context, req *clusterv1.RevokeClusterTokenRequest) (*clusterv1.RevokeClusterTokenResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	token, err := st.revokeToken(req.GetTokenId(), clusterTokens(req.GetClusterId()))
+	if err != nil {
+		return nil, err
+	}
+	return &clusterv1.RevokeClusterTokenResponse{AccessToken: token}, nil
+}
+
+func (s *clusterServer) ReportClusterStatus(ctx context.Context, req *clusterv1.ReportClusterStatusRequest) (*clusterv1.ReportClusterStatusResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	cs, err := st.agentCluster(ctx, req.GetClusterId())
+	if err != nil {
+		return nil, err
+	}
+	reportedAt := req.GetReportedAt()
+	if reportedAt == nil {
+		reportedAt = st.now()
+	}
+	if req.GetStatus() != nil {
+		cs.status = clone(req.GetStatus())
+	}
+	cs.reportedAt = reportedAt
+	st.upsertWorkloads(cs, req.GetWorkloads(), reportedAt)
+	for _, e := range req.GetEvents() {
+		cs.events = append(cs.events, clone(e))
+	}
+	if len(cs.events) > maxEvents {
+		cs.events = slices.Clone(cs.events[len(cs.events)-maxEvents:])
+	}
+	return &clusterv1.ReportClusterStatusResponse{
+		Ack:             true,
+		NextPushSeconds: durationSeconds(st.opts.statusPushInterval),
+	}, nil
+}
+
+func (s *clusterServer) ListWorkloads(_ context.Context, req *clusterv1.ListWorkloadsRequest) (*clusterv1.ListWorkloadsResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	cs, err := st.cluster(req.GetClusterId())
+	if err != nil {
+		return nil, err
+	}
+	items := slices.Collect(maps.Values(cs.workloads))
+	page, next, err := listPage(req, items, (*clusterv1.Workload).GetLastUpdatedAt, req.GetPageSize(), req.GetPageToken(), req.GetFilter())
+	if err != nil {
+		return nil, err
+	}
+	return &clusterv1.ListWorkloadsResponse{Workloads: page, NextPageToken: next}, nil
+}
+
+func (s *clusterServer) ReportWorkloadStatus(ctx context.Context, req *clusterv1.ReportWorkloadStatusRequest) (*clusterv1.ReportWorkloadStatusResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	cs, err := st.agentCluster(ctx, req.GetClusterId())
+	if err != nil {
+		return nil, err
+	}
+	reportedAt := req.GetReportedAt()
+	if reportedAt == nil {
+		reportedAt = st.now()
+	}
+	st.upsertWorkloads(cs, req.GetWorkloads(), reportedAt)
+	return &clusterv1.ReportWorkloadStatusResponse{Ack: true}, nil
+}
+
+// cluster looks up a cluster by ID. The caller must hold mu.
+func (st *store) cluster(id string) (*clusterState, error) {
+	cs, ok := st.clusters[id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "cluster %q not found", id)
+	}
+	return cs, nil
+}
+
+// agentCluster authorizes an agent push for clusterID: the caller must
+// present an agent token bound to that cluster. The caller must hold mu.
+func (st *store) agentCluster(ctx context.Context, clusterID string) (*clusterState, error) {
+	token, err := st.callerToken(bearerToken(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if token.TokenType != authzv1.TokenType_AGENT_TOKEN {
+		return nil, status.Error(codes.PermissionDenied, "token type not allowed")
+	}
+	if token.ClusterId != clusterID {
+		return nil, status.Errorf(codes.PermissionDenied, "token is not bound to cluster %q", clusterID)
+	}
+	return st.cluster(clusterID)
+}
+
+// upsertWorkloads records reported workload state. The caller must hold mu.
+func (st *store) upsertWorkloads(cs *clusterState, workloads []*clusterv1.WorkloadStatus, reportedAt *timestamppb.Timestamp) {
+	for _, w := range workloads {
+		key := w.GetNamespace() + "/" + w.GetKind() + "/" + w.GetName()
+		existing, ok := cs.workloads[key]
+		id := newID()
+		if ok {
+			id = existing.Id
+		}
+		containers := make([]*clusterv1.ContainerStatus, len(w.GetContainers()))
+		for i, c := range w.GetContainers() {
+			containers[i] = clone(c)
+		}
+		cs.workloads[key] = &clusterv1.Workload{
+			Id:                    id,
+			ClusterId:             cs.cluster.Id,
+			Namespace:             w.GetNamespace(),
+			Name:                  w.GetName(),
+			Kind:                  w.GetKind(),
+			Labels:                maps.Clone(w.GetLabels()),
+			HealthStatus:          w.GetHealthStatus(),
+			ReplicasDesired:       w.GetReplicasDesired(),
+			ReplicasReady:         w.GetReplicasReady(),
+			ReplicasAvailable:     w.GetReplicasAvailable(),
+			CpuRequestsMillicores: w.GetCpuRequestsMillicores(),
+			CpuLimitsMillicores:   w.GetCpuLimitsMillicores(),
+			CpuUsedMillicores:     w.GetCpuUsedMillicores(),
+			MemoryRequestsBytes:   w.GetMemoryRequestsBytes(),
+			MemoryLimitsBytes:     w.GetMemoryLimitsBytes(),
+			MemoryUsedBytes:       w.GetMemoryUsedBytes(),
+			Containers:            containers,
+			LastUpdatedAt:         reportedAt,
+		}
+	}
+}
+
+// clusterView returns a copy of the cluster with its derived health status.
+// The caller must hold mu.
+func (st *store) clusterView(cs *clusterState) *clusterv1.Cluster {
+	c := clone(cs.cluster)
+	c.HealthStatus = st.clusterHealth(cs)
+	return c
+}
+
+// clusterHealth derives health the way the control plane documents it:
+// PENDING until an agent registers, UNREACHABLE when its agent stops
+// heartbeating, and otherwise from node readiness and workload health.
+func (st *store) clusterHealth(cs *clusterState) clusterv1.ClusterHealthStatus {
+	if cs.cluster.ClusterUid == "" {
+		return clusterv1.ClusterHealthStatus_CLUSTER_HEALTH_STATUS_PENDING
+	}
+	reachable := false
+	for _, a := range st.agents {
+		if a.ClusterId == cs.cluster.Id && st.agentStatus(a) == agentv1.AgentStatus_AGENT_STATUS_ONLINE {
+			reachable = true
+		}
+	}
+	if !reachable {
+		return clusterv1.ClusterHealthStatus_CLUSTER_HEALTH_STATUS_UNREACHABLE
+	}
+
+	s := cs.status
+	if s == nil {
+		return clusterv1.ClusterHealthStatus_CLUSTER_HEALTH_STATUS_HEALTHY
+	}
+	notReady := s.GetNodeCount() - s.GetNodesReady()
+	switch {
+	case notReady*4 > s.GetNodeCount() || s.GetWorkloadsError()*4 > s.GetWorkloadsTotal():
+		return clusterv1.ClusterHealthStatus_CLUSTER_HEALTH_STATUS_ERROR
+	case notReady > 0 || s.GetWorkloadsError() > 0 || s.GetWorkloadsDegraded()*10 >= s.GetWorkloadsTotal() && s.GetWorkloadsDegraded() > 0:
+		return clusterv1.ClusterHealthStatus_CLUSTER_HEALTH_STATUS_DEGRADED
+	default:
+		return clusterv1.ClusterHealthStatus_CLUSTER_HEALTH_STATUS_HEALTHY
+	}
+}
+
+// clusterTokens matches tokens bound to a cluster.
+func clusterTokens(clusterID string) func(*accesstokenv1.AccessToken) bool {
+	return func(t *accesstokenv1.AccessToken) bool { return t.ClusterId == clusterID }
+}
@@ -0,0 +1,162 @@
+package admiraltest
+
+import (
+	"cmp"
+	"regexp"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.admiral.io/sdk/filter"
+)
+
+// matchFilter evaluates a schema-checked filter expression against msg.
+func matchFilter(expr filter.Expr, msg protoreflect.Message) bool {
+	switch e := expr.(type) {
+	case *filter.AndExpr:
+		for _, sub := range e.Exprs {
+			if !matchFilter(sub, msg) {
+				return false
+			}
+		}
+		return true
+	case *filter.OrExpr:
+		for _, sub := range e.Exprs {
+			if matchFilter(sub, msg) {
+				return true
+			}
+		}
+		return false
+	case *filter.NotExpr:
+		return !matchFilter(e.Expr, msg)
+	case *filter.Comparison:
+		return matchComparison(e, msg)
+	default:
+		return false
+	}
+}
+
+func matchComparison(c *filter.Comparison, msg protoreflect.Message) bool {
+	fd, v, ok := lookupField(msg, c.Field)
+	if !ok {
+		// Unset map keys compare as the empty string.
+		return compareOrdered(strings.Compare("", asString(c.Value)), c.Op)
+	}
+
+	switch {
+	case fd.Message() != nil:
+		ts := v.Message().Interface().(*timestamppb.Timestamp)
+		want, _ := time.Parse(time.RFC3339Nano, asString(c.Value))
+		return compareOrdered(ts.AsTime().Compare(want), c.Op)
+	case fd.Kind() == protoreflect.EnumKind:
+		name := filter.EnumValueName(fd.Enum(), v.Enum())
+		full := ""
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			full = string(ev.Name())
+		}
+		want := asString(c.Value)
+		equal := strings.EqualFold(name, want) || strings.EqualFold(full, want)
+		return equal == (c.Op != filter.OpNe)
+	case fd.Kind() == protoreflect.BoolKind:
+		want, _ := c.Value.(bool)
+		return (v.Bool() == want) == (c.Op != filter.OpNe)
+	case fd.Kind() == protoreflect.StringKind:
+		if c.Op == filter.OpGlob {
+			return globRegexp(asString(c.Value)).MatchString(v.String())
+		}
+		return compareOrdered(strings.Compare(v.String(), asString(c.Value)), c.Op)
+	case fd.Kind() == protoreflect.BytesKind:
+		return compareOrdered(strings.Compare(string(v.Bytes()), asString(c.Value)), c.Op)
+	default:
+		return compareOrdered(cmp.Compare(asFloat(v), numberValue(c.Value)), c.Op)
+	}
+}
+
+// lookupField resolves a dotted path, including map keys, against msg.
+func lookupField(msg protoreflect.Message, path string) (protoreflect.FieldDescriptor, protoreflect.Value, bool) {
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		fd := msg.Descriptor().Fields().ByName(protoreflect.Name(part))
+		if fd == nil {
+			fd = msg.Descriptor().Fields().ByJSONName(part)
+		}
+		if fd == nil {
+			return nil, protoreflect.Value{}, false
+		}
+		if fd.IsMap() {
+			key := protoreflect.ValueOfString(strings.Join(parts[i+1:], ".")).MapKey()
+			v := msg.Get(fd).Map().Get(key)
+			return fd.MapValue(), v, v.IsValid()
+		}
+		if i == len(parts)-1 {
+			if fd.Message() != nil && !msg.Has(fd) {
+				return nil, protoreflect.Value{}, false
+			}
+			return fd, msg.Get(fd), true
+		}
+		msg = msg.Get(fd).Message()
+	}
+	return nil, protoreflect.Value{}, false
+}
+
+func compareOrdered(c int, op filter.Operator) bool {
+	switch op {
+	case filter.OpNe:
+		return c != 0
+	case filter.OpLt:
+		return c < 0
+	case filter.OpLe:
+		return c <= 0
+	case filter.OpGt:
+		return c > 0
+	case filter.OpGe:
+		return c >= 0
+	default:
+		return c == 0
+	}
+}
+
+func globRegexp(pattern string) *regexp.Regexp {
+	parts := strings.Split(pattern, "*")
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$")
+}
+
+func asString(v any) string {
+	s, _ := v.(string)
+	return s
+}
+
+func asFloat(v protoreflect.Value) float64 {
+	switch x := v.Interface().(type) {
+	case int32:
+		return float64(x)
+	case int64:
+		return float64(x)
+	case uint32:
+		return float64(x)
+	case uint64:
+		return float64(x)
+	case float32:
+		return float64(x)
+	case float64:
+		return x
+	default:
+		return 0
+	}
+}
+
+func numberValue(v any) float64 {
+	switch x := v.(type) {
+	case int64:
+		return float64(x)
+	case float64:
+		return x
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,186 @@
+package admiraltest
+
+import (
+	"context"
+	"maps"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	accesstokenv1 "go.admiral.io/sdk/proto/accesstoken/v1"
+	authzv1 "go.admiral.io/sdk/proto/authz/v1"
+	runnerv1 "go.admiral.io/sdk/proto/runner/v1"
+)
+
+type runnerServer struct {
+	runnerv1.UnimplementedRunnerAPIServer
+	store *store
+}
+
+func (s *runnerServer) CreateRunner(_ context.Context, req *runnerv1.CreateRunnerRequest) (*runnerv1.CreateRunnerResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	for _, r := range st.runners {
+		if r.DisplayName == req.GetDisplayName() {
+			return nil, status.Errorf(codes.AlreadyExists, "runner %q already exists", req.GetDisplayName())
+		}
+	}
+
+	now := st.now()
+	runner := &runnerv1.Runner{
+		Id:          newID(),
+		TenantId:    st.tenantID(),
+		DisplayName: req.GetDisplayName(),
+		Kind:        req.GetKind(),
+		Labels:      maps.Clone(req.GetLabels()),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	st.runners[runner.Id] = runner
+	token := st.issueToken(authzv1.TokenType_AGENT_TOKEN, req.GetDisplayName(), runnerAgentScopes, nil, func(t *accesstokenv1.AccessToken) {
+		t.RunnerId = runner.Id
+	})
+
+	return &runnerv1.CreateRunnerResponse{Runner: clone(runner), PlainTextToken: token.secret}, nil
+}
+
+func (s *runnerServer) GetRunner(_ context.Context, req *runnerv1.GetRunnerRequest) (*runnerv1.GetRunnerResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	r, err := st.runner(req.GetRunnerId())
+	if err != nil {
+		return nil, err
+	}
+	return &runnerv1.GetRunnerResponse{Runner: clone(r)}, nil
+}
+
+func (s *runnerServer) ListRunners(_ context.Context, req *runnerv1.ListRunnersRequest) (*runnerv1.ListRunnersResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	items := make([]*runnerv1.Runner, 0, len(st.runners))
+	for _, r := range st.runners {
+		items = append(items, r)
+	}
+	page, next, err := listPage(req, items, (*runnerv1.Runner).GetCreatedAt, req.GetPageSize(), req.GetPageToken(), req.GetFilter())
+	if err != nil {
+		return nil, err
+	}
+	return &runnerv1.ListRunnersResponse{Runners: page, NextPageToken: next}, nil
+}
+
+func (s *runnerServer) UpdateRunner(_ context.Context, req *runnerv1.UpdateRunnerRequest) (*runnerv1.UpdateRunnerResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	r, err := st.runner(req.GetRunner().GetId())
+	if err != nil {
+		return nil, err
+	}
+	updated := clone(r)
+	if err := applyMask(updated, req.GetRunner(), req.GetUpdateMask(), "display_name", "labels"); err != nil {
+		return nil, err
+	}
+	for id, other := range st.runners {
+		if id != updated.Id && other.DisplayName == updated.DisplayName {
+			return nil, status.Errorf(codes.AlreadyExists, "runner %q already exists", updated.DisplayName)
+		}
+	}
+	updated.UpdatedAt = st.now()
+	st.runners[updated.Id] = updated
+	return &runnerv1.UpdateRunnerResponse{Runner: clone(updated)}, nil
+}
+
+func (s *runnerServer) DeleteRunner(_ context.Context, req *runnerv1.DeleteRunnerRequest) (*runnerv1.DeleteRunnerResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if _, err := st.runner(req.GetRunnerId()); err != nil {
+		return nil, err
+	}
+	delete(st.runners, req.GetRunnerId())
+	for _, t := range st.listTokens(runnerTokens(req.GetRunnerId())) {
+		t.Status = accesstokenv1.AccessTokenStatus_ACCESS_TOKEN_STATUS_REVOKED
+		t.RevokedAt = st.now()
+	}
+	for id, a := range st.agents {
+		if a.RunnerId == req.GetRunnerId() {
+			delete(st.agents, id)
+		}
+	}
+	return &runnerv1.DeleteRunnerResponse{}, nil
+}
+
+func (s *runnerServer) CreateRunnerToken(_ context.Context, req *runnerv1.CreateRunnerTokenRequest) (*runnerv1.CreateRunnerTokenResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if _, err := st.runner(req.GetRunnerId()); err != nil {
+		return nil, err
+	}
+	token := st.issueToken(authzv1.TokenType_AGENT_TOKEN, req.GetDisplayName(), runnerAgentScopes, req.GetExpiresAt(), func(t *accesstokenv1.AccessToken) {
+		t.RunnerId = req.GetRunnerId()
+	})
+	return &runnerv1.CreateRunnerTokenResponse{AccessToken: clone(token.token), PlainTextToken: token.secret}, nil
+}
+
+func (s *runnerServer) ListRunnerTokens(_ context.Context, req *runnerv1.ListRunnerTokensRequest) (*runnerv1.ListRunnerTokensResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if _, err := st.runner(req.GetRunnerId()); err != nil {
+		return nil, err
+	}
+	page, next, err := listPage(req, st.listTokens(runnerTokens(req.GetRunnerId())), (*accesstokenv1.AccessToken).GetCreatedAt, req.GetPageSize(), req.GetPageToken(), req.GetFilter())
+	if err != nil {
+		return nil, err
+	}
+	return &runnerv1.ListRunnerTokensResponse{AccessTokens: page, NextPageToken: next}, nil
+}
+
+func (s *runnerServer) GetRunnerToken(_ context.Context, req *runnerv1.GetRunnerTokenRequest) (*runnerv1.GetRunnerTokenResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	token, err := st.getToken(req.GetTokenId(), runnerTokens(req.GetRunnerId()))
+	if err != nil {
+		return nil, err
+	}
+	return &runnerv1.GetRunnerTokenResponse{AccessToken: token}, nil
+}
+
+func (s *runnerServer) RevokeRunnerToken(_ context.Context, req *runnerv1.RevokeRunnerTokenRequest) (*runnerv1.RevokeRunnerTokenResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	token, err := st.revokeToken(req.GetTokenId(), runnerTokens(req.GetRunnerId()))
+	if err != nil {
+		return nil, err
+	}
+	return &runnerv1.RevokeRunnerTokenResponse{AccessToken: token}, nil
+}
+
+// runner looks up a runner by ID. The caller must hold mu.
+func (st *store) runner(id string) (*runnerv1.Runner, error) {
+	r, ok := st.runners[id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "runner %q not found", id)
+	}
+	return r, nil
+}
+
+// runnerTokens matches tokens bound to a runner.
+func runnerTokens(runnerID string) func(*accesstokenv1.AccessToken) bool {
+	return func(t *accesstokenv1.AccessToken) bool { return t.RunnerId == runnerID }
+}
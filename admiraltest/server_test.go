@@ -0,0 +1,324 @@
+package admiraltest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	"go.admiral.io/sdk/client"
+	accesstokenv1 "go.admiral.io/sdk/proto/accesstoken/v1"
+	agentv1 "go.admiral.io/sdk/proto/agent/v1"
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+	healthcheckv1 "go.admiral.io/sdk/proto/healthcheck/v1"
+	runnerv1 "go.admiral.io/sdk/proto/runner/v1"
+	serviceaccountv1 "go.admiral.io/sdk/proto/serviceaccount/v1"
+	userv1 "go.admiral.io/sdk/proto/user/v1"
+)
+
+// fakeClock is a settable time source.
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+func wantCode(t *testing.T, err error, code codes.Code) {
+	t.Helper()
+	if status.Code(err) != code {
+		t.Fatalf("error = %v, want code %v", err, code)
+	}
+}
+
+func TestHealthcheck(t *testing.T) {
+	_, c := Start(t)
+	if _, err := c.Healthcheck().Healthcheck(context.Background(), &healthcheckv1.HealthcheckRequest{}); err != nil {
+		t.Fatalf("Healthcheck() error = %v", err)
+	}
+}
+
+func TestCluster_CRUD(t *testing.T) {
+	_, c := Start(t)
+	ctx := context.Background()
+
+	created, err := c.Cluster().CreateCluster(ctx, &clusterv1.CreateClusterRequest{
+		DisplayName: "prod",
+		Labels:      map[string]string{"env": "prod"},
+	})
+	if err != nil {
+		t.Fatalf("CreateCluster() error = %v", err)
+	}
+	if created.GetPlainTextToken() == "" {
+		t.Error("CreateCluster() returned no agent token")
+	}
+	if got := created.GetCluster().GetHealthStatus(); got != clusterv1.ClusterHealthStatus_CLUSTER_HEALTH_STATUS_PENDING {
+		t.Errorf("HealthStatus = %v, want PENDING", got)
+	}
+
+	_, err = c.Cluster().CreateCluster(ctx, &clusterv1.CreateClusterRequest{DisplayName: "prod"})
+	wantCode(t, err, codes.AlreadyExists)
+
+	id := created.GetCluster().GetId()
+	got, err := c.Cluster().GetCluster(ctx, &clusterv1.GetClusterRequest{ClusterId: id})
+	if err != nil {
+		t.Fatalf("GetCluster() error = %v", err)
+	}
+	if got.GetCluster().GetDisplayName() != "prod" {
+		t.Errorf("DisplayName = %q, want prod", got.GetCluster().GetDisplayName())
+	}
+
+	updated, err := c.Cluster().UpdateCluster(ctx, &clusterv1.UpdateClusterRequest{
+		Cluster:    &clusterv1.Cluster{Id: id, DisplayName: "staging", Labels: map[string]string{"env": "staging"}},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"display_name"}},
+	})
+	if err != nil {
+		t.Fatalf("UpdateCluster() error = %v", err)
+	}
+	if updated.GetCluster().GetDisplayName() != "staging" || updated.GetCluster().GetLabels()["env"] != "prod" {
+		t.Errorf("UpdateCluster() = %v, want only display_name changed", updated.GetCluster())
+	}
+
+	_, err = c.Cluster().UpdateCluster(ctx, &clusterv1.UpdateClusterRequest{
+		Cluster:    &clusterv1.Cluster{Id: id, ClusterUid: "x"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"cluster_uid"}},
+	})
+	wantCode(t, err, codes.InvalidArgument)
+
+	if _, err := c.Cluster().DeleteCluster(ctx, &clusterv1.DeleteClusterRequest{ClusterId: id}); err != nil {
+		t.Fatalf("DeleteCluster() error = %v", err)
+	}
+	_, err = c.Cluster().GetCluster(ctx, &clusterv1.GetClusterRequest{ClusterId: id})
+	wantCode(t, err, codes.NotFound)
+}
+
+func TestListRunners_PaginationAndFilter(t *testing.T) {
+	_, c := Start(t)
+	ctx := context.Background()
+
+	for _, r := range []struct {
+		name string
+		kind runnerv1.RunnerKind
+	}{
+		{"tf-1", runnerv1.RunnerKind_RUNNER_KIND_TERRAFORM},
+		{"tf-2", runnerv1.RunnerKind_RUNNER_KIND_TERRAFORM},
+		{"wf-1", runnerv1.RunnerKind_RUNNER_KIND_WORKFLOW},
+		{"tf-3", runnerv1.RunnerKind_RUNNER_KIND_TERRAFORM},
+	} {
+		_, err := c.Runner().CreateRunner(ctx, &runnerv1.CreateRunnerRequest{DisplayName: r.name, Kind: r.kind})
+		if err != nil {
+			t.Fatalf("CreateRunner(%s) error = %v", r.name, err)
+		}
+	}
+
+	all, err := client.CollectAll(client.ListRunners(ctx, c.Runner(), client.WithPageSize(3)))
+	if err != nil {
+		t.Fatalf("ListRunners() error = %v", err)
+	}
+	if len(all) != 4 {
+		t.Errorf("ListRunners() = %d runners, want 4", len(all))
+	}
+
+	tf, err := client.CollectAll(client.ListRunners(ctx, c.Runner(),
+		client.WithPageSize(1), client.WithFilter(`kind = "TERRAFORM" AND display_name = "tf-*"`)))
+	if err != nil {
+		t.Fatalf("ListRunners(filter) error = %v", err)
+	}
+	if len(tf) != 3 {
+		t.Errorf("ListRunners(filter) = %d runners, want 3", len(tf))
+	}
+
+	_, err = c.Runner().ListRunners(ctx, &runnerv1.ListRunnersRequest{Filter: `colour = "red"`})
+	wantCode(t, err, codes.InvalidArgument)
+
+	_, err = c.Runner().ListRunners(ctx, &runnerv1.ListRunnersRequest{PageToken: "bogus"})
+	wantCode(t, err, codes.InvalidArgument)
+}
+
+func TestPersonalAccessToken_Revoke(t *testing.T) {
+	_, c := Start(t)
+	ctx := context.Background()
+
+	created, err := c.User().CreatePersonalAccessToken(ctx, &userv1.CreatePersonalAccessTokenRequest{
+		DisplayName: "ci",
+		Scopes:      []string{"clusters:read"},
+	})
+	if err != nil {
+		t.Fatalf("CreatePersonalAccessToken() error = %v", err)
+	}
+	id := created.GetAccessToken().GetId()
+
+	revoked, err := c.User().RevokePersonalAccessToken(ctx, &userv1.RevokePersonalAccessTokenRequest{TokenId: id})
+	if err != nil {
+		t.Fatalf("RevokePersonalAccessToken() error = %v", err)
+	}
+	if revoked.GetAccessToken().GetStatus() != accesstokenv1.AccessTokenStatus_ACCESS_TOKEN_STATUS_REVOKED {
+		t.Errorf("Status = %v, want REVOKED", revoked.GetAccessToken().GetStatus())
+	}
+
+	_, err = c.User().RevokePersonalAccessToken(ctx, &userv1.RevokePersonalAccessTokenRequest{TokenId: id})
+	wantCode(t, err, codes.FailedPrecondition)
+
+	active, err := c.User().ListPersonalAccessTokens(ctx, &userv1.ListPersonalAccessTokensRequest{Filter: `status = "ACTIVE"`})
+	if err != nil {
+		t.Fatalf("ListPersonalAccessTokens() error = %v", err)
+	}
+	if len(active.GetAccessTokens()) != 0 {
+		t.Errorf("active tokens = %d, want 0", len(active.GetAccessTokens()))
+	}
+}
+
+func TestServiceAccountToken_ScopesMustBeGranted(t *testing.T) {
+	_, c := Start(t)
+	ctx := context.Background()
+
+	sa, err := c.ServiceAccount().CreateServiceAccount(ctx, &serviceaccountv1.CreateServiceAccountRequest{
+		DisplayName: "deployer",
+		Scopes:      []string{"clusters:read"},
+	})
+	if err != nil {
+		t.Fatalf("CreateServiceAccount() error = %v", err)
+	}
+
+	_, err = c.ServiceAccount().CreateServiceAccountToken(ctx, &serviceaccountv1.CreateServiceAccountTokenRequest{
+		ServiceAccountId: sa.GetServiceAccount().GetId(),
+		DisplayName:      "bad",
+		Scopes:           []string{"clusters:write"},
+	})
+	wantCode(t, err, codes.InvalidArgument)
+
+	tok, err := c.ServiceAccount().CreateServiceAccountToken(ctx, &serviceaccountv1.CreateServiceAccountTokenRequest{
+		ServiceAccountId: sa.GetServiceAccount().GetId(),
+		DisplayName:      "good",
+		Scopes:           []string{"clusters:read"},
+	})
+	if err != nil {
+		t.Fatalf("CreateServiceAccountToken() error = %v", err)
+	}
+	if got := tok.GetAccessToken().GetServiceAccountId(); got != sa.GetServiceAccount().GetId() {
+		t.Errorf("ServiceAccountId = %q, want %q", got, sa.GetServiceAccount().GetId())
+	}
+}
+
+func TestAgentFlow(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	srv, c := Start(t, WithClock(clock.Now), WithIntervals(10*time.Second, 20*time.Second))
+	ctx := context.Background()
+
+	created, err := c.Cluster().CreateCluster(ctx, &clusterv1.CreateClusterRequest{DisplayName: "edge"})
+	if err != nil {
+		t.Fatalf("CreateCluster() error = %v", err)
+	}
+	clusterID := created.GetCluster().GetId()
+
+	agentClient, err := srv.NewClient(ctx, client.Config{AuthToken: created.GetPlainTextToken()})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer agentClient.Close()
+
+	register := &agentv1.RegisterAgentRequest{
+		DisplayName: "edge-agent",
+		Version:     "0.1.0",
+		Metadata: &agentv1.RegisterAgentRequest_Kubernetes{
+			Kubernetes: &agentv1.KubernetesAgentMetadata{ClusterUid: "uid-1"},
+		},
+	}
+	reg, err := agentClient.Agent().RegisterAgent(ctx, register)
+	if err != nil {
+		t.Fatalf("RegisterAgent() error = %v", err)
+	}
+	if reg.GetPollIntervalSeconds() != 10 || reg.GetStatusPushIntervalSeconds() != 20 {
+		t.Errorf("intervals = %d/%d, want 10/20", reg.GetPollIntervalSeconds(), reg.GetStatusPushIntervalSeconds())
+	}
+	agentID := reg.GetAgent().GetId()
+
+	again, err := agentClient.Agent().RegisterAgent(ctx, register)
+	if err != nil {
+		t.Fatalf("RegisterAgent() again error = %v", err)
+	}
+	if again.GetAgent().GetId() != agentID {
+		t.Errorf("re-registration returned agent %q, want %q", again.GetAgent().GetId(), agentID)
+	}
+
+	register.Metadata = &agentv1.RegisterAgentRequest_Kubernetes{
+		Kubernetes: &agentv1.KubernetesAgentMetadata{ClusterUid: "uid-2"},
+	}
+	_, err = agentClient.Agent().RegisterAgent(ctx, register)
+	wantCode(t, err, codes.Aborted)
+
+	_, err = c.Agent().RegisterAgent(ctx, register)
+	wantCode(t, err, codes.Unauthenticated)
+
+	_, err = agentClient.Cluster().ReportClusterStatus(ctx, &clusterv1.ReportClusterStatusRequest{
+		ClusterId: clusterID,
+		Status:    &clusterv1.ClusterStatus{NodeCount: 3, NodesReady: 3},
+		Workloads: []*clusterv1.WorkloadStatus{{
+			Namespace:    "default",
+			Name:         "web",
+			Kind:         "Deployment",
+			HealthStatus: clusterv1.WorkloadHealthStatus_WORKLOAD_HEALTH_STATUS_HEALTHY,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("ReportClusterStatus() error = %v", err)
+	}
+	cluster, err := c.Cluster().GetCluster(ctx, &clusterv1.GetClusterRequest{ClusterId: clusterID})
+	if err != nil {
+		t.Fatalf("GetCluster() error = %v", err)
+	}
+	if got := cluster.GetCluster().GetHealthStatus(); got != clusterv1.ClusterHealthStatus_CLUSTER_HEALTH_STATUS_HEALTHY {
+		t.Errorf("HealthStatus = %v, want HEALTHY", got)
+	}
+	workloads, err := c.Cluster().ListWorkloads(ctx, &clusterv1.ListWorkloadsRequest{ClusterId: clusterID})
+	if err != nil {
+		t.Fatalf("ListWorkloads() error = %v", err)
+	}
+	if len(workloads.GetWorkloads()) != 1 || workloads.GetWorkloads()[0].GetName() != "web" {
+		t.Errorf("ListWorkloads() = %v, want [web]", workloads.GetWorkloads())
+	}
+
+	clock.Advance(31 * time.Second)
+	got, err := c.Agent().GetAgent(ctx, &agentv1.GetAgentRequest{AgentId: agentID})
+	if err != nil {
+		t.Fatalf("GetAgent() error = %v", err)
+	}
+	if got.GetAgent().GetStatus() != agentv1.AgentStatus_AGENT_STATUS_UNREACHABLE {
+		t.Errorf("Status = %v, want UNREACHABLE", got.GetAgent().GetStatus())
+	}
+
+	if _, err := agentClient.Agent().Heartbeat(ctx, &agentv1.HeartbeatRequest{AgentId: agentID}); err != nil {
+		t.Fatalf("Heartbeat() error = %v", err)
+	}
+	got, err = c.Agent().GetAgent(ctx, &agentv1.GetAgentRequest{AgentId: agentID})
+	if err != nil {
+		t.Fatalf("GetAgent() error = %v", err)
+	}
+	if got.GetAgent().GetStatus() != agentv1.AgentStatus_AGENT_STATUS_ONLINE {
+		t.Errorf("Status = %v, want ONLINE", got.GetAgent().GetStatus())
+	}
+}
+
+func TestWithLoopback(t *testing.T) {
+	srv, c := Start(t, WithLoopback())
+	if srv.Addr() == "bufconn:0" {
+		t.Fatalf("Addr() = %q, want a loopback address", srv.Addr())
+	}
+	if _, err := c.User().GetUser(context.Background(), &userv1.GetUserRequest{}); err != nil {
+		t.Fatalf("GetUser() error = %v", err)
+	}
+}
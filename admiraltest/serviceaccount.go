@@ -0,0 +1,192 @@
+package admiraltest
+
+import (
+	"context"
+	"slices"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	accesstokenv1 "go.admiral.io/sdk/proto/accesstoken/v1"
+	authzv1 "go.admiral.io/sdk/proto/authz/v1"
+	serviceaccountv1 "go.admiral.io/sdk/proto/serviceaccount/v1"
+)
+
+type serviceAccountServer struct {
+	serviceaccountv1.UnimplementedServiceAccountAPIServer
+	store *store
+}
+
+func (s *serviceAccountServer) CreateServiceAccount(_ context.Context, req *serviceaccountv1.CreateServiceAccountRequest) (*serviceaccountv1.CreateServiceAccountResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	for _, sa := range st.serviceAccounts {
+		if sa.DisplayName == req.GetDisplayName() {
+			return nil, status.Errorf(codes.AlreadyExists, "service account %q already exists", req.GetDisplayName())
+		}
+	}
+
+	now := st.now()
+	sa := &serviceaccountv1.ServiceAccount{
+		Id:          newID(),
+		TenantId:    st.tenantID(),
+		DisplayName: req.GetDisplayName(),
+		Description: req.GetDescription(),
+		Scopes:      slices.Clone(req.GetScopes()),
+		Status:      serviceaccountv1.ServiceAccountStatus_SERVICE_ACCOUNT_STATUS_ACTIVE,
+		CreatedBy:   st.opts.user.GetId(),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	st.serviceAccounts[sa.Id] = sa
+	return &serviceaccountv1.CreateServiceAccountResponse{ServiceAccount: clone(sa)}, nil
+}
+
+func (s *serviceAccountServer) GetServiceAccount(_ context.Context, req *serviceaccountv1.GetServiceAccountRequest) (*serviceaccountv1.GetServiceAccountResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	sa, err := st.serviceAccount(req.GetServiceAccountId())
+	if err != nil {
+		return nil, err
+	}
+	return &serviceaccountv1.GetServiceAccountResponse{ServiceAccount: clone(sa)}, nil
+}
+
+func (s *serviceAccountServer) ListServiceAccounts(_ context.Context, req *serviceaccountv1.ListServiceAccountsRequest) (*serviceaccountv1.ListServiceAccountsResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	items := make([]*serviceaccountv1.ServiceAccount, 0, len(st.serviceAccounts))
+	for _, sa := range st.serviceAccounts {
+		items = append(items, sa)
+	}
+	page, next, err := listPage(req, items, (*serviceaccountv1.ServiceAccount).GetCreatedAt, req.GetPageSize(), req.GetPageToken(), req.GetFilter())
+	if err != nil {
+		return nil, err
+	}
+	return &serviceaccountv1.ListServiceAccountsResponse{ServiceAccounts: page, NextPageToken: next}, nil
+}
+
+func (s *serviceAccountServer) UpdateServiceAccount(_ context.Context, req *serviceaccountv1.UpdateServiceAccountRequest) (*serviceaccountv1.UpdateServiceAccountResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	sa, err := st.serviceAccount(req.GetServiceAccount().GetId())
+	if err != nil {
+		return nil, err
+	}
+	updated := clone(sa)
+	if err := applyMask(updated, req.GetServiceAccount(), req.GetUpdateMask(), "display_name", "description", "scopes", "status"); err != nil {
+		return nil, err
+	}
+	for id, other := range st.serviceAccounts {
+		if id != updated.Id && other.DisplayName == updated.DisplayName {
+			return nil, status.Errorf(codes.AlreadyExists, "service account %q already exists", updated.DisplayName)
+		}
+	}
+	updated.UpdatedAt = st.now()
+	st.serviceAccounts[updated.Id] = updated
+	return &serviceaccountv1.UpdateServiceAccountResponse{ServiceAccount: clone(updated)}, nil
+}
+
+func (s *serviceAccountServer) DeleteServiceAccount(_ context.Context, req *serviceaccountv1.DeleteServiceAccountRequest) (*serviceaccountv1.DeleteServiceAccountResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if _, err := st.serviceAccount(req.GetServiceAccountId()); err != nil {
+		return nil, err
+	}
+	delete(st.serviceAccounts, req.GetServiceAccountId())
+	for _, t := range st.listTokens(serviceAccountTokens(req.GetServiceAccountId())) {
+		t.Status = accesstokenv1.AccessTokenStatus_ACCESS_TOKEN_STATUS_REVOKED
+		t.RevokedAt = st.now()
+	}
+	return &serviceaccountv1.DeleteServiceAccountResponse{}, nil
+}
+
+func (s *serviceAccountServer) CreateServiceAccountToken(_ context.Context, req *serviceaccountv1.CreateServiceAccountTokenRequest) (*serviceaccountv1.CreateServiceAccountTokenResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	sa, err := st.serviceAccount(req.GetServiceAccountId())
+	if err != nil {
+		return nil, err
+	}
+	if sa.Status == serviceaccountv1.ServiceAccountStatus_SERVICE_ACCOUNT_STATUS_DISABLED {
+		return nil, status.Errorf(codes.FailedPrecondition, "service account %q is disabled", sa.Id)
+	}
+	scopes := req.GetScopes()
+	if len(scopes) == 0 {
+		scopes = sa.Scopes
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(sa.Scopes, scope) {
+			return nil, status.Errorf(codes.InvalidArgument, "scope %q is not granted to service account %q", scope, sa.Id)
+		}
+	}
+	token := st.issueToken(authzv1.TokenType_SERVICE_ACCOUNT_TOKEN, req.GetDisplayName(), scopes, req.GetExpiresAt(), func(t *accesstokenv1.AccessToken) {
+		t.ServiceAccountId = sa.Id
+	})
+	return &serviceaccountv1.CreateServiceAccountTokenResponse{AccessToken: clone(token.token), PlainTextToken: token.secret}, nil
+}
+
+func (s *serviceAccountServer) ListServiceAccountTokens(_ context.Context, req *serviceaccountv1.ListServiceAccountTokensRequest) (*serviceaccountv1.ListServiceAccountTokensResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if _, err := st.serviceAccount(req.GetServiceAccountId()); err != nil {
+		return nil, err
+	}
+	page, next, err := listPage(req, st.listTokens(serviceAccountTokens(req.GetServiceAccountId())), (*accesstokenv1.AccessToken).GetCreatedAt, req.GetPageSize(), req.GetPageToken(), req.GetFilter())
+	if err != nil {
+		return nil, err
+	}
+	return &serviceaccountv1.ListServiceAccountTokensResponse{AccessTokens: page, NextPageToken: next}, nil
+}
+
+func (s *serviceAccountServer) GetServiceAccountToken(_ context.Context, req *serviceaccountv1.GetServiceAccountTokenRequest) (*serviceaccountv1.GetServiceAccountTokenResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	token, err := st.getToken(req.GetTokenId(), serviceAccountTokens(req.GetServiceAccountId()))
+	if err != nil {
+		return nil, err
+	}
+	return &serviceaccountv1.GetServiceAccountTokenResponse{AccessToken: token}, nil
+}
+
+func (s *serviceAccountServer) RevokeServiceAccountToken(_ context.Context, req *serviceaccountv1.RevokeServiceAccountTokenRequest) (*serviceaccountv1.RevokeServiceAccountTokenResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	token, err := st.revokeToken(req.GetTokenId(), serviceAccountTokens(req.GetServiceAccountId()))
+	if err != nil {
+		return nil, err
+	}
+	return &serviceaccountv1.RevokeServiceAccountTokenResponse{AccessToken: token}, nil
+}
+
+// serviceAccount looks up a service account by ID. The caller must hold mu.
+func (st *store) serviceAccount(id string) (*serviceaccountv1.ServiceAccount, error) {
+	sa, ok := st.serviceAccounts[id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "service account %q not found", id)
+	}
+	return sa, nil
+}
+
+// serviceAccountTokens matches tokens bound to a service account.
+func serviceAccountTokens(serviceAccountID string) func(*accesstokenv1.AccessToken) bool {
+	return func(t *accesstokenv1.AccessToken) bool { return t.ServiceAccountId == serviceAccountID }
+}
@@ -0,0 +1,304 @@
+package admiraltest
+
+import (
+	"cmp"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.admiral.io/sdk/filter"
+	accesstokenv1 "go.admiral.io/sdk/proto/accesstoken/v1"
+	agentv1 "go.admiral.io/sdk/proto/agent/v1"
+	authzv1 "go.admiral.io/sdk/proto/authz/v1"
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+	runnerv1 "go.admiral.io/sdk/proto/runner/v1"
+	serviceaccountv1 "go.admiral.io/sdk/proto/serviceaccount/v1"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 1000
+)
+
+// Scopes auto-assigned to agent tokens.
+var (
+	clusterAgentScopes = []string{"agents:write", "agents:heartbeat", "clusters:status"}
+	runnerAgentScopes  = []string{"agents:write", "agents:heartbeat"}
+)
+
+// clusterState holds a cluster and everything reported about it.
+type clusterState struct {
+	cluster    *clusterv1.Cluster
+	status     *clusterv1.ClusterStatus
+	reportedAt *timestamppb.Timestamp
+	workloads  map[string]*clusterv1.Workload // keyed by namespace/kind/name
+	events     []*clusterv1.WorkloadEvent
+}
+
+// tokenState pairs token metadata with its secret.
+type tokenState struct {
+	token  *accesstokenv1.AccessToken
+	secret string
+}
+
+// store is the shared state behind every service. All access goes through
+// mu; values handed to callers are clones.
+type store struct {
+	mu   sync.Mutex
+	opts options
+
+	clusters        map[string]*clusterState
+	runners         map[string]*runnerv1.Runner
+	agents          map[string]*agentv1.Agent
+	serviceAccounts map[string]*serviceaccountv1.ServiceAccount
+	tokens          map[string]*tokenState // keyed by token ID
+	secrets         map[string]string      // secret -> token ID
+	agentByToken    map[string]string      // token ID -> agent ID
+}
+
+func newStore(opts options) *store {
+	return &store{
+		opts:            opts,
+		clusters:        map[string]*clusterState{},
+		runners:         map[string]*runnerv1.Runner{},
+		agents:          map[string]*agentv1.Agent{},
+		serviceAccounts: map[string]*serviceaccountv1.ServiceAccount{},
+		tokens:          map[string]*tokenState{},
+		secrets:         map[string]string{},
+		agentByToken:    map[string]string{},
+	}
+}
+
+func (s *store) now() *timestamppb.Timestamp {
+	return timestamppb.New(s.opts.now())
+}
+
+func (s *store) tenantID() string {
+	return s.opts.user.GetTenantId()
+}
+
+// issueToken creates an active token and returns its metadata and secret.
+// The caller must hold mu.
+func (s *store) issueToken(tokenType authzv1.TokenType, displayName string, scopes []string, expiresAt *timestamppb.Timestamp, bind func(*accesstokenv1.AccessToken)) *tokenState {
+	secret := tokenSecretPrefix(tokenType) + randomString(24)
+	t := &accesstokenv1.AccessToken{
+		Id:          newID(),
+		TenantId:    s.tenantID(),
+		DisplayName: displayName,
+		TokenPrefix: secret[:12],
+		TokenType:   tokenType,
+		Scopes:      slices.Clone(scopes),
+		Status:      accesstokenv1.AccessTokenStatus_ACCESS_TOKEN_STATUS_ACTIVE,
+		CreatedBy:   s.opts.user.GetId(),
+		ExpiresAt:   expiresAt,
+		CreatedAt:   s.now(),
+	}
+	if bind != nil {
+		bind(t)
+	}
+	ts := &tokenState{token: t, secret: secret}
+	s.tokens[t.Id] = ts
+	s.secrets[secret] = t.Id
+	return ts
+}
+
+// revokeToken marks a token revoked. match reports whether the token
+// belongs to the parent resource named in the request. The caller must
+// hold mu.
+func (s *store) revokeToken(tokenID string, match func(*accesstokenv1.AccessToken) bool) (*accesstokenv1.AccessToken, error) {
+	ts, ok := s.tokens[tokenID]
+	if !ok || !match(ts.token) {
+		return nil, status.Errorf(codes.NotFound, "token %q not found", tokenID)
+	}
+	if ts.token.Status == accesstokenv1.AccessTokenStatus_ACCESS_TOKEN_STATUS_REVOKED {
+		return nil, status.Errorf(codes.FailedPrecondition, "token %q is already revoked", tokenID)
+	}
+	ts.token.Status = accesstokenv1.AccessTokenStatus_ACCESS_TOKEN_STATUS_REVOKED
+	ts.token.RevokedAt = s.now()
+	return clone(ts.token), nil
+}
+
+// getToken returns a token belonging to the parent resource. The caller
+// must hold mu.
+func (s *store) getToken(tokenID string, match func(*accesstokenv1.AccessToken) bool) (*accesstokenv1.AccessToken, error) {
+	ts, ok := s.tokens[tokenID]
+	if !ok || !match(ts.token) {
+		return nil, status.Errorf(codes.NotFound, "token %q not found", tokenID)
+	}
+	return clone(ts.token), nil
+}
+
+// listTokens returns the tokens matching a parent resource. The caller must
+// hold mu.
+func (s *store) listTokens(match func(*accesstokenv1.AccessToken) bool) []*accesstokenv1.AccessToken {
+	var out []*accesstokenv1.AccessToken
+	for _, ts := range s.tokens {
+		if match(ts.token) {
+			out = append(out, ts.token)
+		}
+	}
+	return out
+}
+
+// callerToken resolves the secret presented by the caller to an active,
+// unexpired token. The caller must hold mu.
+func (s *store) callerToken(secret string) (*accesstokenv1.AccessToken, error) {
+	id, ok := s.secrets[secret]
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	t := s.tokens[id].token
+	if t.Status == accesstokenv1.AccessTokenStatus_ACCESS_TOKEN_STATUS_REVOKED {
+		return nil, status.Error(codes.Unauthenticated, "token has been revoked")
+	}
+	if t.ExpiresAt != nil && !s.opts.now().Before(t.ExpiresAt.AsTime()) {
+		return nil, status.Error(codes.Unauthenticated, "token has expired")
+	}
+	return t, nil
+}
+
+func tokenSecretPrefix(t authzv1.TokenType) string {
+	switch t {
+	case authzv1.TokenType_PERSONAL_ACCESS_TOKEN:
+		return "adm_pat_"
+	case authzv1.TokenType_SERVICE_ACCOUNT_TOKEN:
+		return "adm_sat_"
+	default:
+		return "adm_agt_"
+	}
+}
+
+// listPage validates the filter against req, sorts items by creation time,
+// applies the filter and returns one page of clones.
+func listPage[T proto.Message](req proto.Message, items []T, createdAt func(T) *timestamppb.Timestamp, pageSize int32, pageToken, filterStr string) ([]T, string, error) {
+	var expr filter.Expr
+	if filterStr != "" {
+		schema, err := filter.SchemaFor(req)
+		if err != nil {
+			return nil, "", status.Error(codes.Internal, err.Error())
+		}
+		expr, err = filter.Parse(filterStr)
+		if err == nil {
+			err = schema.Check(expr)
+		}
+		if err != nil {
+			return nil, "", status.Errorf(codes.InvalidArgument, "invalid filter: %v", err)
+		}
+	}
+
+	slices.SortFunc(items, func(a, b T) int {
+		return cmp.Or(
+			createdAt(a).AsTime().Compare(createdAt(b).AsTime()),
+			cmp.Compare(resourceID(a), resourceID(b)),
+		)
+	})
+
+	matched := items[:0:0]
+	for _, item := range items {
+		if expr == nil || matchFilter(expr, item.ProtoReflect()) {
+			matched = append(matched, item)
+		}
+	}
+
+	offset := 0
+	if pageToken != "" {
+		decoded, err := base64.RawURLEncoding.DecodeString(pageToken)
+		if err == nil {
+			offset, err = strconv.Atoi(string(decoded))
+		}
+		if err != nil || offset < 0 || offset > len(matched) {
+			return nil, "", status.Error(codes.InvalidArgument, "invalid page token")
+		}
+	}
+
+	size := int(pageSize)
+	switch {
+	case size < 0:
+		return nil, "", status.Error(codes.InvalidArgument, "page_size must not be negative")
+	case size == 0:
+		size = defaultPageSize
+	case size > maxPageSize:
+		size = maxPageSize
+	}
+
+	end := min(offset+size, len(matched))
+	page := make([]T, 0, end-offset)
+	for _, item := range matched[offset:end] {
+		page = append(page, clone(item))
+	}
+	next := ""
+	if end < len(matched) {
+		next = base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(end)))
+	}
+	return page, next, nil
+}
+
+// applyMask copies the fields named in mask from src to dst. An empty mask
+// copies every mutable field. Paths outside mutable are rejected.
+func applyMask(dst, src proto.Message, mask *fieldmaskpb.FieldMask, mutable ...string) error {
+	paths := mask.GetPaths()
+	if len(paths) == 0 {
+		paths = mutable
+	}
+	d, s := dst.ProtoReflect(), src.ProtoReflect()
+	fields := d.Descriptor().Fields()
+	for _, path := range paths {
+		if !slices.Contains(mutable, path) {
+			return status.Errorf(codes.InvalidArgument, "field %q cannot be updated", path)
+		}
+		fd := fields.ByName(protoreflect.Name(path))
+		if s.Has(fd) {
+			d.Set(fd, s.Get(fd))
+		} else {
+			d.Clear(fd)
+		}
+	}
+	return nil
+}
+
+// resourceID returns the "id" field of a resource, used to order items
+// created at the same instant.
+func resourceID(m proto.Message) string {
+	msg := m.ProtoReflect()
+	fd := msg.Descriptor().Fields().ByName("id")
+	if fd == nil {
+		return ""
+	}
+	return msg.Get(fd).String()
+}
+
+func clone[T proto.Message](m T) T {
+	return proto.Clone(m).(T)
+}
+
+// newID returns a random RFC 4122 version 4 UUID.
+func newID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	h := hex.EncodeToString(b[:])
+	return fmt.Sprintf("%s-%s-%s-%s-%s", h[0:8], h[8:12], h[12:16], h[16:20], h[20:32])
+}
+
+func randomString(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)[:n]
+}
+
+func durationSeconds(d time.Duration) int32 {
+	return int32(d / time.Second)
+}
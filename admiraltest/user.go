@@ -0,0 +1,79 @@
+package admiraltest
+
+import (
+	"context"
+
+	accesstokenv1 "go.admiral.io/sdk/proto/accesstoken/v1"
+	authzv1 "go.admiral.io/sdk/proto/authz/v1"
+	healthcheckv1 "go.admiral.io/sdk/proto/healthcheck/v1"
+	userv1 "go.admiral.io/sdk/proto/user/v1"
+)
+
+type userServer struct {
+	userv1.UnimplementedUserAPIServer
+	store *store
+}
+
+func (s *userServer) GetUser(_ context.Context, _ *userv1.GetUserRequest) (*userv1.GetUserResponse, error) {
+	return clone(s.store.opts.user), nil
+}
+
+func (s *userServer) CreatePersonalAccessToken(_ context.Context, req *userv1.CreatePersonalAccessTokenRequest) (*userv1.CreatePersonalAccessTokenResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	token := st.issueToken(authzv1.TokenType_PERSONAL_ACCESS_TOKEN, req.GetDisplayName(), req.GetScopes(), req.GetExpiresAt(), func(t *accesstokenv1.AccessToken) {
+		t.UserId = st.opts.user.GetId()
+	})
+	return &userv1.CreatePersonalAccessTokenResponse{AccessToken: clone(token.token), PlainTextToken: token.secret}, nil
+}
+
+func (s *userServer) ListPersonalAccessTokens(_ context.Context, req *userv1.ListPersonalAccessTokensRequest) (*userv1.ListPersonalAccessTokensResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	page, next, err := listPage(req, st.listTokens(st.userTokens), (*accesstokenv1.AccessToken).GetCreatedAt, req.GetPageSize(), req.GetPageToken(), req.GetFilter())
+	if err != nil {
+		return nil, err
+	}
+	return &userv1.ListPersonalAccessTokensResponse{AccessTokens: page, NextPageToken: next}, nil
+}
+
+func (s *userServer) GetPersonalAccessToken(_ context.Context, req *userv1.GetPersonalAccessTokenRequest) (*userv1.GetPersonalAccessTokenResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	token, err := st.getToken(req.GetTokenId(), st.userTokens)
+	if err != nil {
+		return nil, err
+	}
+	return &userv1.GetPersonalAccessTokenResponse{AccessToken: token}, nil
+}
+
+func (s *userServer) RevokePersonalAccessToken(_ context.Context, req *userv1.RevokePersonalAccessTokenRequest) (*userv1.RevokePersonalAccessTokenResponse, error) {
+	st := s.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	token, err := st.revokeToken(req.GetTokenId(), st.userTokens)
+	if err != nil {
+		return nil, err
+	}
+	return &userv1.RevokePersonalAccessTokenResponse{AccessToken: token}, nil
+}
+
+// userTokens matches personal access tokens of the configured user.
+func (st *store) userTokens(t *accesstokenv1.AccessToken) bool {
+	return t.TokenType == authzv1.TokenType_PERSONAL_ACCESS_TOKEN && t.UserId == st.opts.user.GetId()
+}
+
+type healthcheckServer struct {
+	healthcheckv1.UnimplementedHealthcheckAPIServer
+}
+
+func (healthcheckServer) Healthcheck(context.Context, *healthcheckv1.HealthcheckRequest) (*healthcheckv1.HealthcheckResponse, error) {
+	return &healthcheckv1.HealthcheckResponse{}, nil
+}
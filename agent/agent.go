@@ -0,0 +1,409 @@
+// Package agent runs the control-plane side of an Admiral agent: it
+// registers with AgentAPI, keeps the agent ONLINE with heartbeats at the
+// interval the server asks for, and invokes a status hook at the server's
+// status push interval.
+//
+// # Running an Agent
+//
+// Authenticate the client with the agent token (AGT) issued for the cluster
+// or runner, then run the Runtime until the process is asked to stop:
+//
+//	c, err := client.New(ctx, client.Config{TokenSource: client.FileTokenSource(tokenPath)})
+//	rt, err := agent.New(c.Agent(), agent.Config{
+//	    DisplayName: "prod-us-east-1-agent",
+//	    Version:     version,
+//	    Kubernetes:  &agentv1.KubernetesAgentMetadata{ClusterUid: kubeSystemUID},
+//	    Status: agent.StatusPusherFunc(func(ctx context.Context, a *agentv1.Agent) error {
+//	        _, err := c.Cluster().ReportClusterStatus(ctx, collect(a.GetClusterId()))
+//	        return err
+//	    }),
+//	})
+//	ctx, stop := signal.NotifyContext(ctx, syscall.SIGTERM, os.Interrupt)
+//	defer stop()
+//	err = rt.Run(ctx) // returns nil once ctx is canceled
+//
+// # Failure Handling
+//
+// Transient failures of RegisterAgent and Heartbeat are retried with
+// exponential backoff. A heartbeat rejected with NotFound re-registers the
+// agent. Errors that retrying cannot fix, such as an invalid token or a
+// cluster_uid conflict, stop Run and are returned to the caller.
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.admiral.io/sdk/client"
+	agentv1 "go.admiral.io/sdk/proto/agent/v1"
+)
+
+const (
+	// DefaultPollInterval is the heartbeat interval used until the server
+	// provides one.
+	DefaultPollInterval = 30 * time.Second
+	// DefaultStatusPushInterval is the status push interval used until the
+	// server provides one.
+	DefaultStatusPushInterval = 60 * time.Second
+	// DefaultInitialBackoff is the delay before the first retry of a failed
+	// RegisterAgent or Heartbeat call.
+	DefaultInitialBackoff = time.Second
+	// DefaultMaxBackoff caps the delay between retries.
+	DefaultMaxBackoff = time.Minute
+)
+
+// ErrConflict is returned by Run when the server refuses registration
+// because the cluster is already bound to a different cluster_uid.
+var ErrConflict = errors.New("agent: cluster is bound to a different cluster_uid")
+
+// StatusPusher reports agent-specific status to the control plane, such as
+// ReportClusterStatus for Kubernetes agents. It is called with the
+// registered agent at every status push interval.
+type StatusPusher interface {
+	PushStatus(ctx context.Context, agent *agentv1.Agent) error
+}
+
+// StatusPusherFunc adapts a function to a StatusPusher.
+type StatusPusherFunc func(ctx context.Context, agent *agentv1.Agent) error
+
+// PushStatus calls f(ctx, agent).
+func (f StatusPusherFunc) PushStatus(ctx context.Context, agent *agentv1.Agent) error {
+	return f(ctx, agent)
+}
+
+// Config configures a Runtime.
+type Config struct {
+	// DisplayName is the human-readable name of the agent. Required.
+	DisplayName string
+	// Version is the agent software version, sent on registration and with
+	// every heartbeat.
+	Version string
+	// Kubernetes is the registration metadata for cluster-bound agents.
+	// Exactly one of Kubernetes and Runner must be set, matching the binding
+	// of the agent token.
+	Kubernetes *agentv1.KubernetesAgentMetadata
+	// Runner is the registration metadata for runner-bound agents.
+	Runner *agentv1.RunnerAgentMetadata
+	// Status is called at the status push interval. Optional.
+	Status StatusPusher
+	// InitialBackoff and MaxBackoff bound the delay between retries of
+	// failed calls. Default to DefaultInitialBackoff and DefaultMaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Logger for the runtime. Silent by default.
+	Logger client.Logger
+}
+
+// CheckAndSetDefaults validates the config and fills in defaults.
+func (c *Config) CheckAndSetDefaults() error {
+	if c.DisplayName == "" {
+		return errors.New("agent: DisplayName is required")
+	}
+	if (c.Kubernetes == nil) == (c.Runner == nil) {
+		return errors.New("agent: exactly one of Kubernetes and Runner metadata is required")
+	}
+	if c.Kubernetes != nil && c.Kubernetes.GetClusterUid() == "" {
+		return errors.New("agent: Kubernetes.ClusterUid is required")
+	}
+	if c.InitialBackoff <= 0 {
+		c.InitialBackoff = DefaultInitialBackoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = DefaultMaxBackoff
+	}
+	if c.MaxBackoff < c.InitialBackoff {
+		return fmt.Errorf("agent: MaxBackoff %v is less than InitialBackoff %v", c.MaxBackoff, c.InitialBackoff)
+	}
+	if c.Logger == nil {
+		c.Logger = client.NewNoOpLogger()
+	}
+	return nil
+}
+
+// Runtime registers an agent and keeps it alive. Create one with New and
+// start it with Run.
+type Runtime struct {
+	api agentv1.AgentAPIClient
+	cfg Config
+
+	// second is the unit of the server's interval fields. Tests shrink it.
+	second time.Duration
+
+	mu                 sync.Mutex
+	agent              *agentv1.Agent
+	pollInterval       time.Duration
+	statusPushInterval time.Duration
+	started            time.Time
+	cancel             context.CancelFunc
+	done               chan struct{}
+	registered         chan struct{}
+}
+
+// New returns a Runtime that talks to api, typically client.Agent() of a
+// client authenticated with an agent token. A conflict is recognised by
+// client.ErrConflict, so api must return the SDK's *client.Error values.
+func New(api agentv1.AgentAPIClient, cfg Config) (*Runtime, error) {
+	if api == nil {
+		return nil, errors.New("agent: AgentAPIClient is required")
+	}
+	if err := cfg.CheckAndSetDefaults(); err != nil {
+		return nil, err
+	}
+	return &Runtime{
+		api:                api,
+		cfg:                cfg,
+		second:             time.Second,
+		pollInterval:       DefaultPollInterval,
+		statusPushInterval: DefaultStatusPushInterval,
+		registered:         make(chan struct{}),
+	}, nil
+}
+
+// Run registers the agent, then sends heartbeats and pushes status until
+// ctx is canceled or Shutdown is called, at which point it waits for
+// in-flight calls and returns nil. It returns an error if registration or a
+// heartbeat fails permanently. Run may only be called once.
+func (r *Runtime) Run(ctx context.Context) error {
+	r.mu.Lock()
+	if r.done != nil {
+		r.mu.Unlock()
+		return errors.New("agent: Run called more than once")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	r.cancel = cancel
+	r.done = make(chan struct{})
+	r.started = time.Now()
+	r.mu.Unlock()
+	defer close(r.done)
+	defer cancel()
+
+	if err := r.register(ctx); err != nil {
+		return ignoreCanceled(ctx, err)
+	}
+
+	var wg sync.WaitGroup
+	if r.cfg.Status != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.statusLoop(ctx)
+		}()
+	}
+	err := r.heartbeatLoop(ctx)
+	cancel()
+	wg.Wait()
+	r.cfg.Logger.Infof("agent %s stopped", r.Agent().GetId())
+	return ignoreCanceled(ctx, err)
+}
+
+// Shutdown stops a running Runtime and waits for Run to return or ctx to
+// expire.
+func (r *Runtime) Shutdown(ctx context.Context) error {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Agent returns the registered agent, or nil before registration completes.
+func (r *Runtime) Agent() *agentv1.Agent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.agent
+}
+
+// Registered returns a channel that is closed once the agent has registered.
+func (r *Runtime) Registered() <-chan struct{} {
+	return r.registered
+}
+
+// Intervals returns the heartbeat and status push intervals currently in
+// effect.
+func (r *Runtime) Intervals() (poll, statusPush time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.pollInterval, r.statusPushInterval
+}
+
+// Uptime returns how long Run has been running.
+func (r *Runtime) Uptime() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.started.IsZero() {
+		return 0
+	}
+	return time.Since(r.started)
+}
+
+// register calls RegisterAgent until it succeeds or fails permanently.
+func (r *Runtime) register(ctx context.Context) error {
+	req := &agentv1.RegisterAgentRequest{
+		DisplayName: r.cfg.DisplayName,
+		Version:     r.cfg.Version,
+	}
+	if r.cfg.Kubernetes != nil {
+		req.Metadata = &agentv1.RegisterAgentRequest_Kubernetes{Kubernetes: r.cfg.Kubernetes}
+	} else {
+		req.Metadata = &agentv1.RegisterAgentRequest_Runner{Runner: r.cfg.Runner}
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := r.api.RegisterAgent(ctx, req)
+		if err == nil {
+			r.mu.Lock()
+			first := r.agent == nil
+			r.agent = resp.GetAgent()
+			r.setIntervalsLocked(resp.GetPollIntervalSeconds(), resp.GetStatusPushIntervalSeconds())
+			r.mu.Unlock()
+			if first {
+				close(r.registered)
+			}
+			r.cfg.Logger.Infof("agent %s registered", resp.GetAgent().GetId())
+			return nil
+		}
+		if err := permanent(err); err != nil {
+			return fmt.Errorf("agent: register: %w", err)
+		}
+		delay := r.backoff(attempt)
+		r.cfg.Logger.Warnf("agent registration failed, retrying in %v: %v", delay, err)
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// heartbeatLoop sends a heartbeat every poll interval. Failed heartbeats are
+// retried with backoff instead of waiting for the next interval.
+func (r *Runtime) heartbeatLoop(ctx context.Context) error {
+	failures := 0
+	for {
+		delay, _ := r.Intervals()
+		if failures > 0 {
+			delay = r.backoff(failures - 1)
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+
+		resp, err := r.api.Heartbeat(ctx, &agentv1.HeartbeatRequest{
+			AgentId:       r.Agent().GetId(),
+			Version:       r.cfg.Version,
+			UptimeSeconds: int64(r.Uptime() / time.Second),
+		})
+		switch {
+		case err == nil:
+			failures = 0
+			r.mu.Lock()
+			r.setIntervalsLocked(resp.GetPollIntervalSeconds(), resp.GetStatusPushIntervalSeconds())
+			r.mu.Unlock()
+			continue
+		case status.Code(err) == codes.NotFound:
+			// The server no longer knows the agent; register it again.
+			r.cfg.Logger.Warnf("agent %s not found, re-registering", r.Agent().GetId())
+			if err := r.register(ctx); err != nil {
+				return err
+			}
+			failures = 0
+			continue
+		}
+		if err := permanent(err); err != nil {
+			return fmt.Errorf("agent: heartbeat: %w", err)
+		}
+		failures++
+		r.cfg.Logger.Warnf("agent heartbeat failed (%d consecutive): %v", failures, err)
+	}
+}
+
+// statusLoop calls the status hook every status push interval. Errors are
+// logged; the next push is still attempted on schedule.
+func (r *Runtime) statusLoop(ctx context.Context) {
+	for {
+		if err := r.pushStatus(ctx); err != nil && ctx.Err() == nil {
+			r.cfg.Logger.Warnf("agent status push failed: %v", err)
+		}
+		_, delay := r.Intervals()
+		if err := sleep(ctx, delay); err != nil {
+			return
+		}
+	}
+}
+
+func (r *Runtime) pushStatus(ctx context.Context) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("status hook panicked: %v", p)
+		}
+	}()
+	return r.cfg.Status.PushStatus(ctx, r.Agent())
+}
+
+// setIntervalsLocked applies the intervals from a server response. Zero
+// values keep the current interval. The caller must hold mu.
+func (r *Runtime) setIntervalsLocked(poll, statusPush int32) {
+	if poll > 0 {
+		r.pollInterval = time.Duration(poll) * r.second
+	}
+	if statusPush > 0 {
+		r.statusPushInterval = time.Duration(statusPush) * r.second
+	}
+}
+
+// backoff returns the delay before retry attempt n (0-based): equal jitter,
+// so at least half the exponential delay and at most all of it.
+func (r *Runtime) backoff(n int) time.Duration {
+	d := r.cfg.InitialBackoff << min(n, 30)
+	if d <= 0 || d > r.cfg.MaxBackoff {
+		d = r.cfg.MaxBackoff
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// permanent returns err, translated where useful, if retrying cannot make
+// the call succeed, or nil for transient errors.
+func permanent(err error) error {
+	if errors.Is(err, client.ErrConflict) {
+		return fmt.Errorf("%w: %v", ErrConflict, status.Convert(err).Message())
+	}
+	switch status.Code(err) {
+	case codes.Unauthenticated, codes.PermissionDenied, codes.InvalidArgument,
+		codes.FailedPrecondition, codes.Unimplemented:
+		return err
+	}
+	return nil
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ignoreCanceled reports a nil error for a shutdown requested through ctx.
+func ignoreCanceled(ctx context.Context, err error) error {
+	if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		return nil
+	}
+	return err
+}
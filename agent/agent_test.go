@@ -0,0 +1,251 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.admiral.io/sdk/admiraltest"
+	"go.admiral.io/sdk/client"
+	agentv1 "go.admiral.io/sdk/proto/agent/v1"
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+)
+
+// newAgentClient creates a cluster on srv and returns a client authenticated
+// with its agent token.
+func newAgentClient(t *testing.T, srv *admiraltest.Server, c *client.Client) (*client.Client, string) {
+	t.Helper()
+	ctx := context.Background()
+	created, err := c.Cluster().CreateCluster(ctx, &clusterv1.CreateClusterRequest{DisplayName: "prod"})
+	if err != nil {
+		t.Fatalf("CreateCluster() error = %v", err)
+	}
+	ac, err := srv.NewClient(ctx, client.Config{AuthToken: created.GetPlainTextToken()})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { _ = ac.Close() })
+	return ac, created.GetCluster().GetId()
+}
+
+// newTestRuntime returns a Runtime whose server intervals are read as
+// milliseconds, so tests run quickly.
+func newTestRuntime(t *testing.T, api agentv1.AgentAPIClient, cfg Config) *Runtime {
+	t.Helper()
+	if cfg.DisplayName == "" {
+		cfg.DisplayName = "test-agent"
+	}
+	if cfg.Kubernetes == nil && cfg.Runner == nil {
+		cfg.Kubernetes = &agentv1.KubernetesAgentMetadata{ClusterUid: "uid-1"}
+	}
+	cfg.InitialBackoff = time.Millisecond
+	cfg.MaxBackoff = 5 * time.Millisecond
+	rt, err := New(api, cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	rt.second = time.Millisecond
+	return rt
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRuntime_RegistersAndHeartbeats(t *testing.T) {
+	srv, c := admiraltest.Start(t, admiraltest.WithIntervals(5*time.Second, 10*time.Second))
+	ac, clusterID := newAgentClient(t, srv, c)
+
+	var pushes atomic.Int32
+	var pushedFor atomic.Value
+	rt := newTestRuntime(t, ac.Agent(), Config{
+		Version: "1.2.3",
+		Status: StatusPusherFunc(func(_ context.Context, a *agentv1.Agent) error {
+			pushedFor.Store(a.GetClusterId())
+			pushes.Add(1)
+			return nil
+		}),
+	})
+
+	errc := make(chan error, 1)
+	go func() { errc <- rt.Run(context.Background()) }()
+
+	select {
+	case <-rt.Registered():
+	case err := <-errc:
+		t.Fatalf("Run() error = %v", err)
+	}
+	if rt.Agent().GetClusterId() != clusterID {
+		t.Errorf("Agent().ClusterId = %q, want %q", rt.Agent().GetClusterId(), clusterID)
+	}
+	if poll, push := rt.Intervals(); poll != 5*time.Millisecond || push != 10*time.Millisecond {
+		t.Errorf("Intervals() = %v, %v, want server intervals", poll, push)
+	}
+
+	registeredAt := rt.Agent().GetLastHeartbeatAt().AsTime()
+	waitFor(t, "heartbeat", func() bool {
+		resp, err := c.Agent().GetAgent(context.Background(), &agentv1.GetAgentRequest{AgentId: rt.Agent().GetId()})
+		return err == nil && resp.GetAgent().GetLastHeartbeatAt().AsTime().After(registeredAt)
+	})
+	waitFor(t, "status pushes", func() bool { return pushes.Load() >= 2 })
+	if pushedFor.Load() != clusterID {
+		t.Errorf("status hook got cluster %v, want %q", pushedFor.Load(), clusterID)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := rt.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := <-errc; err != nil {
+		t.Errorf("Run() error = %v after Shutdown, want nil", err)
+	}
+}
+
+func TestRuntime_ClusterUIDConflict(t *testing.T) {
+	srv, c := admiraltest.Start(t)
+	ac, _ := newAgentClient(t, srv, c)
+
+	first := newTestRuntime(t, ac.Agent(), Config{})
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() { _ = first.Run(ctx) }()
+	<-first.Registered()
+	cancel()
+
+	second := newTestRuntime(t, ac.Agent(), Config{
+		Kubernetes: &agentv1.KubernetesAgentMetadata{ClusterUid: "uid-2"},
+	})
+	if err := second.Run(context.Background()); !errors.Is(err, ErrConflict) {
+		t.Errorf("Run() error = %v, want ErrConflict", err)
+	}
+}
+
+// scriptedAgentAPI answers RegisterAgent and Heartbeat from a script of
+// errors, then succeeds.
+type scriptedAgentAPI struct {
+	agentv1.AgentAPIClient
+
+	mu             sync.Mutex
+	registerErrs   []error
+	heartbeatErrs  []error
+	registers      int
+	heartbeats     int
+	pollSeconds    int32
+	lastHeartbeats []*agentv1.HeartbeatRequest
+}
+
+func (s *scriptedAgentAPI) RegisterAgent(context.Context, *agentv1.RegisterAgentRequest, ...grpc.CallOption) (*agentv1.RegisterAgentResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.registers++
+	if len(s.registerErrs) > 0 {
+		err := s.registerErrs[0]
+		s.registerErrs = s.registerErrs[1:]
+		return nil, err
+	}
+	return &agentv1.RegisterAgentResponse{
+		Agent:               &agentv1.Agent{Id: "agent-1"},
+		PollIntervalSeconds: 2,
+	}, nil
+}
+
+func (s *scriptedAgentAPI) Heartbeat(_ context.Context, req *agentv1.HeartbeatRequest, _ ...grpc.CallOption) (*agentv1.HeartbeatResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.heartbeats++
+	if len(s.heartbeatErrs) > 0 {
+		err := s.heartbeatErrs[0]
+		s.heartbeatErrs = s.heartbeatErrs[1:]
+		return nil, err
+	}
+	s.lastHeartbeats = append(s.lastHeartbeats, req)
+	return &agentv1.HeartbeatResponse{Ok: true, PollIntervalSeconds: s.pollSeconds}, nil
+}
+
+func (s *scriptedAgentAPI) counts() (registers, heartbeats, ok int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.registers, s.heartbeats, len(s.lastHeartbeats)
+}
+
+func TestRuntime_RetriesTransientFailures(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "control plane restarting")
+	api := &scriptedAgentAPI{
+		registerErrs:  []error{unavailable, unavailable},
+		heartbeatErrs: []error{unavailable, status.Error(codes.NotFound, "agent not found")},
+		pollSeconds:   3,
+	}
+	rt := newTestRuntime(t, api, Config{Version: "1.2.3"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() { errc <- rt.Run(ctx) }()
+
+	waitFor(t, "successful heartbeats", func() bool {
+		_, _, ok := api.counts()
+		return ok >= 2
+	})
+	cancel()
+	if err := <-errc; err != nil {
+		t.Fatalf("Run() error = %v, want nil on cancel", err)
+	}
+
+	registers, _, _ := api.counts()
+	if registers != 4 {
+		t.Errorf("RegisterAgent calls = %d, want 4 (2 failures, 1 success, 1 after NotFound)", registers)
+	}
+	hb := api.lastHeartbeats[0]
+	if hb.GetAgentId() != "agent-1" || hb.GetVersion() != "1.2.3" {
+		t.Errorf("Heartbeat request = %v", hb)
+	}
+	if poll, _ := rt.Intervals(); poll != 3*time.Millisecond {
+		t.Errorf("poll interval = %v, want 3ms from the heartbeat response", poll)
+	}
+}
+
+func TestRuntime_PermanentHeartbeatError(t *testing.T) {
+	api := &scriptedAgentAPI{heartbeatErrs: []error{status.Error(codes.Unauthenticated, "token revoked")}}
+	rt := newTestRuntime(t, api, Config{})
+
+	err := rt.Run(context.Background())
+	if status.Code(errors.Unwrap(err)) != codes.Unauthenticated {
+		t.Errorf("Run() error = %v, want Unauthenticated", err)
+	}
+	if err := rt.Run(context.Background()); err == nil {
+		t.Error("second Run() succeeded")
+	}
+}
+
+func TestConfig_CheckAndSetDefaults(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"no display name", Config{Runner: &agentv1.RunnerAgentMetadata{}}},
+		{"no metadata", Config{DisplayName: "a"}},
+		{"both metadata", Config{DisplayName: "a", Runner: &agentv1.RunnerAgentMetadata{}, Kubernetes: &agentv1.KubernetesAgentMetadata{ClusterUid: "u"}}},
+		{"no cluster uid", Config{DisplayName: "a", Kubernetes: &agentv1.KubernetesAgentMetadata{}}},
+		{"backoff inverted", Config{DisplayName: "a", Runner: &agentv1.RunnerAgentMetadata{}, InitialBackoff: time.Minute, MaxBackoff: time.Second}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.CheckAndSetDefaults(); err == nil {
+				t.Error("CheckAndSetDefaults() = nil, want error")
+			}
+		})
+	}
+}
@@ -29,6 +29,12 @@ type JWTClaims struct {
 	NotBefore      int64  `json:"nbf,omitempty"`
 	IssuedAt       int64  `json:"iat,omitempty"`
 	JWTId          string `json:"jti,omitempty"`
+	// Scope is the space-separated list of granted scopes, if the issuer
+	// includes one.
+	Scope string `json:"scope,omitempty"`
+	// TokenType is one of the TokenType constants, if the issuer includes
+	// it.
+	TokenType string `json:"token_type,omitempty"`
 }
 
 // IsExpired checks if the token is expired based on the exp claim
@@ -58,7 +64,7 @@ func (c *JWTClaims) ExpiresIn() time.Duration {
 
 // ParseJWTToken parses a JWT token and extracts claims without validating the signature.
 // This is sufficient for basic format validation and expiration checking.
-// For production use, you should also validate the signature with the appropriate key.
+// Use a Verifier to also check the signature against the issuer's JWKS.
 func ParseJWTToken(token string) (*JWTClaims, error) {
 	// JWT tokens have 3 parts separated by dots: header.payload.signature
 	parts := strings.Split(token, ".")
@@ -219,7 +219,7 @@ func TestTokenAuth_AuthScheme(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ta := tokenAuth{
-				token:  "my-token",
+				source: StaticTokenSource("my-token"),
 				scheme: tt.scheme,
 			}
 			md, err := ta.GetRequestMetadata(context.Background())
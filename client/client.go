@@ -4,16 +4,17 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"net/http"
 
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/credentials/insecure"
 	agentv1 "go.admiral.io/sdk/proto/agent/v1"
 	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
 	healthcheckv1 "go.admiral.io/sdk/proto/healthcheck/v1"
 	runnerv1 "go.admiral.io/sdk/proto/runner/v1"
 	serviceaccountv1 "go.admiral.io/sdk/proto/serviceaccount/v1"
 	userv1 "go.admiral.io/sdk/proto/user/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 // Compile-time check that Client implements AdmiralClient
@@ -21,15 +22,17 @@ var _ AdmiralClient = (*Client)(nil)
 
 // Client is the Admiral API client.
 type Client struct {
-	conn      *grpc.ClientConn
-	logger    Logger
-	authToken string
-	agent agentv1.AgentAPIClient
-	cluster clusterv1.ClusterAPIClient
-	healthcheck healthcheckv1.HealthcheckAPIClient
-	runner runnerv1.RunnerAPIClient
+	conn           *grpc.ClientConn
+	pool           *endpointPool
+	httpClient     *http.Client
+	logger         Logger
+	tokens         TokenSource
+	agent          agentv1.AgentAPIClient
+	cluster        clusterv1.ClusterAPIClient
+	healthcheck    healthcheckv1.HealthcheckAPIClient
+	runner         runnerv1.RunnerAPIClient
 	serviceAccount serviceaccountv1.ServiceAccountAPIClient
-	user userv1.UserAPIClient
+	user           userv1.UserAPIClient
 }
 
 // New creates a new Admiral client with the given configuration.
@@ -38,21 +41,18 @@ func New(ctx context.Context, cfg Config) (*Client, error) {
 		return nil, fmt.Errorf("invalid config: %w", err)
 	}
 
-	dialOpts := cfg.ConnectionOptions.DialOptions
+	if len(cfg.Endpoints) > 0 {
+		return newBalancedClient(ctx, cfg)
+	}
 
-	// Configure transport credentials
-	if cfg.ConnectionOptions.Insecure {
-		dialOpts = append(dialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	} else {
-		tlsConfig := cfg.ConnectionOptions.TLSConfig
-		if tlsConfig == nil {
-			tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
-		}
-		dialOpts = append(dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	switch cfg.Transport {
+	case TransportConnect:
+		return newConnectClient(cfg), nil
+	case TransportREST:
+		return newRESTClient(cfg), nil
 	}
 
-	// Add user agent
-	dialOpts = append(dialOpts, grpc.WithUserAgent(ClientUserAgent()))
+	dialOpts := grpcDialOptions(cfg)
 
 	// Dial with timeout
 	dialCtx, cancel := context.WithTimeout(ctx, cfg.ConnectionOptions.DialTimeout)
@@ -66,18 +66,42 @@ func New(ctx context.Context, cfg Config) (*Client, error) {
 	cfg.Logger.Debugf("connected to Admiral API at %s", cfg.HostPort)
 
 	return &Client{
-		conn:      conn,
-		logger:    cfg.Logger,
-		authToken: cfg.AuthToken,
-		agent: agentv1.NewAgentAPIClient(conn),
-		cluster: clusterv1.NewClusterAPIClient(conn),
-		healthcheck: healthcheckv1.NewHealthcheckAPIClient(conn),
-		runner: runnerv1.NewRunnerAPIClient(conn),
+		conn:           conn,
+		logger:         cfg.Logger,
+		tokens:         cfg.TokenSource,
+		agent:          agentv1.NewAgentAPIClient(conn),
+		cluster:        clusterv1.NewClusterAPIClient(conn),
+		healthcheck:    healthcheckv1.NewHealthcheckAPIClient(conn),
+		runner:         runnerv1.NewRunnerAPIClient(conn),
 		serviceAccount: serviceaccountv1.NewServiceAccountAPIClient(conn),
-		user: userv1.NewUserAPIClient(conn),
+		user:           userv1.NewUserAPIClient(conn),
 	}, nil
 }
 
+// grpcDialOptions returns the options for dialing the API with gRPC.
+func grpcDialOptions(cfg Config) []grpc.DialOption {
+	// The SDK dialer handles proxies and Unix sockets; a dialer in
+	// DialOptions replaces it.
+	dialOpts := append([]grpc.DialOption{
+		grpc.WithContextDialer(cfg.dialer()),
+		grpc.WithNoProxy(),
+	}, cfg.ConnectionOptions.DialOptions...)
+
+	// Configure transport credentials
+	if cfg.ConnectionOptions.Insecure {
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	} else {
+		tlsConfig := cfg.ConnectionOptions.TLSConfig
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	}
+
+	// Add user agent
+	return append(dialOpts, grpc.WithUserAgent(ClientUserAgent()))
+}
+
 // Agent returns the AgentAPI client.
 func (c *Client) Agent() agentv1.AgentAPIClient {
 	return c.agent
@@ -108,14 +132,23 @@ func (c *Client) User() userv1.UserAPIClient {
 	return c.user
 }
 
-// ValidateToken validates the client's auth token format and expiration.
+// ValidateToken validates the format and expiration of the client's current
+// auth token.
 func (c *Client) ValidateToken() error {
-	return ValidateAuthToken(c.authToken)
+	token, err := c.tokens.Token(context.Background())
+	if err != nil {
+		return err
+	}
+	return ValidateAuthToken(token.Value)
 }
 
-// GetTokenInfo returns information about the client's auth token.
+// GetTokenInfo returns information about the client's current auth token.
 func (c *Client) GetTokenInfo() (*TokenInfo, error) {
-	claims, err := ParseJWTToken(c.authToken)
+	token, err := c.tokens.Token(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	claims, err := ParseJWTToken(token.Value)
 	if err != nil {
 		return nil, err
 	}
@@ -127,11 +160,20 @@ func (c *Client) Version() string {
 	return Version()
 }
 
-// Close closes the underlying gRPC connection.
+// Close closes the underlying gRPC connection, or releases idle HTTP
+// connections when using the Connect or REST transport.
 func (c *Client) Close() error {
+	if c.pool != nil {
+		c.logger.Debugf("closing endpoint connections")
+		c.pool.close()
+	}
 	if c.conn != nil {
 		c.logger.Debugf("closing connection")
 		return c.conn.Close()
 	}
+	if c.httpClient != nil {
+		c.logger.Debugf("closing idle HTTP connections")
+		c.httpClient.CloseIdleConnections()
+	}
 	return nil
 }
@@ -0,0 +1,396 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"connectrpc.com/connect"
+	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	grpcstatus "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	agentv1 "go.admiral.io/sdk/proto/agent/v1"
+	"go.admiral.io/sdk/proto/agent/v1/agentv1connect"
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+	"go.admiral.io/sdk/proto/cluster/v1/clusterv1connect"
+	healthcheckv1 "go.admiral.io/sdk/proto/healthcheck/v1"
+	"go.admiral.io/sdk/proto/healthcheck/v1/healthcheckv1connect"
+	runnerv1 "go.admiral.io/sdk/proto/runner/v1"
+	"go.admiral.io/sdk/proto/runner/v1/runnerv1connect"
+	serviceaccountv1 "go.admiral.io/sdk/proto/serviceaccount/v1"
+	"go.admiral.io/sdk/proto/serviceaccount/v1/serviceaccountv1connect"
+	userv1 "go.admiral.io/sdk/proto/user/v1"
+	"go.admiral.io/sdk/proto/user/v1/userv1connect"
+)
+
+// newConnectClient builds a Client whose service accessors speak the
+// Connect protocol. The accessors still return the gRPC client interfaces,
+// so callers are unaware of the transport in use.
+func newConnectClient(cfg Config) *Client {
+	httpClient := cfg.ConnectionOptions.HTTPClient
+	if httpClient == nil {
+		httpClient = newHTTPClient(cfg)
+	}
+
+	baseURL := baseURL(cfg)
+	interceptors := []connect.Interceptor{newConnectHeaderInterceptor(cfg.perRPCCredentials())}
+	if r := cfg.retrier(); r != nil {
+		interceptors = append([]connect.Interceptor{r.connectInterceptor()}, interceptors...)
+	}
+	if t := cfg.telemetry(); t != nil {
+		interceptors = append([]connect.Interceptor{t.connectInterceptor()}, interceptors...)
+	}
+	if s := cfg.scopeChecker(); s != nil {
+		interceptors = append([]connect.Interceptor{s.connectInterceptor()}, interceptors...)
+	}
+	if cfg.ConnectionOptions.ValidateRequests {
+		interceptors = append([]connect.Interceptor{validateConnectInterceptor()}, interceptors...)
+	}
+	opts := []connect.ClientOption{connect.WithInterceptors(interceptors...)}
+	if cfg.ConnectionOptions.Encoding == EncodingJSON {
+		opts = append(opts, connect.WithProtoJSON())
+	}
+	opts = append(opts, cfg.ConnectionOptions.ConnectOptions...)
+
+	cfg.Logger.Debugf("using Connect transport for Admiral API at %s", baseURL)
+
+	return &Client{
+		httpClient:     httpClient,
+		logger:         cfg.Logger,
+		tokens:         cfg.TokenSource,
+		agent:          &connectAgentClient{c: agentv1connect.NewAgentAPIClient(httpClient, baseURL, opts...)},
+		cluster:        &connectClusterClient{c: clusterv1connect.NewClusterAPIClient(httpClient, baseURL, opts...)},
+		healthcheck:    &connectHealthcheckClient{c: healthcheckv1connect.NewHealthcheckAPIClient(httpClient, baseURL, opts...)},
+		runner:         &connectRunnerClient{c: runnerv1connect.NewRunnerAPIClient(httpClient, baseURL, opts...)},
+		serviceAccount: &connectServiceAccountClient{c: serviceaccountv1connect.NewServiceAccountAPIClient(httpClient, baseURL, opts...)},
+		user:           &connectUserClient{c: userv1connect.NewUserAPIClient(httpClient, baseURL, opts...)},
+	}
+}
+
+// baseURL returns the HTTP base URL for the configured endpoint. Unix
+// socket targets are addressed as localhost.
+func baseURL(cfg Config) string {
+	host := cfg.HostPort
+	if _, ok := unixSocketPath(host); ok {
+		host = "localhost"
+	}
+	if cfg.ConnectionOptions.Insecure {
+		return "http://" + host
+	}
+	return "https://" + host
+}
+
+// newHTTPClient builds the default HTTP client for HTTP-based transports.
+func newHTTPClient(cfg Config) *http.Client {
+	dial := cfg.dialer()
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, addr string) (net.Conn, error) {
+				return dial(ctx, addr)
+			},
+			TLSClientConfig:   cfg.ConnectionOptions.TLSConfig,
+			ForceAttemptHTTP2: true,
+		},
+	}
+}
+
+// newConnectHeaderInterceptor attaches credentials, the SDK user agent and
+// any outgoing gRPC metadata to each Connect request.
+func newConnectHeaderInterceptor(creds credentials.PerRPCCredentials) connect.UnaryInterceptorFunc {
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			md, err := creds.GetRequestMetadata(ctx, req.Spec().Procedure)
+			if err != nil {
+				return nil, connect.NewError(connect.CodeUnauthenticated, err)
+			}
+			for k, v := range md {
+				req.Header().Set(k, v)
+			}
+			req.Header().Set("User-Agent", ClientUserAgent())
+			return next(ctx, req)
+		}
+	}
+}
+
+// connectUnary invokes a Connect unary method with gRPC calling conventions:
+// outgoing metadata becomes request headers, response headers and trailers
+// are delivered to grpc.Header and grpc.Trailer call options, and errors are
+// returned as gRPC status errors.
+func connectUnary[Req, Res any](
+	ctx context.Context,
+	call func(context.Context, *connect.Request[Req]) (*connect.Response[Res], error),
+	in *Req,
+	opts []grpc.CallOption,
+) (*Res, error) {
+	req := connect.NewRequest(in)
+	if md, ok := metadata.FromOutgoingContext(ctx); ok {
+		for k, vs := range md {
+			for _, v := range vs {
+				req.Header().Add(k, v)
+			}
+		}
+	}
+
+	if hasRetryOption(opts) {
+		ctx = context.WithValue(ctx, forceRetryKey{}, true)
+	}
+
+	resp, err := call(ctx, req)
+	if err != nil {
+		return nil, wrapError(connectErrorToStatus(err))
+	}
+
+	for _, opt := range opts {
+		switch o := opt.(type) {
+		case grpc.HeaderCallOption:
+			*o.HeaderAddr = headerToMD(resp.Header())
+		case grpc.TrailerCallOption:
+			*o.TrailerAddr = headerToMD(resp.Trailer())
+		}
+	}
+	return resp.Msg, nil
+}
+
+// connectErrorToStatus converts a Connect error into a gRPC status error,
+// preserving the code, message and error details.
+func connectErrorToStatus(err error) error {
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) {
+		return grpcstatus.Error(codes.Unknown, err.Error())
+	}
+	st := &status.Status{
+		Code:    int32(connectErr.Code()),
+		Message: connectErr.Message(),
+	}
+	for _, d := range connectErr.Details() {
+		st.Details = append(st.Details, &anypb.Any{
+			TypeUrl: "type.googleapis.com/" + d.Type(),
+			Value:   d.Bytes(),
+		})
+	}
+	return withRetryAfter(grpcstatus.FromProto(st).Err(), connectErr.Meta().Get("Retry-After"))
+}
+
+// statusToConnectError converts a gRPC status into a Connect error,
+// preserving the code, message and error details.
+func statusToConnectError(st *grpcstatus.Status) *connect.Error {
+	err := connect.NewError(connect.Code(st.Code()), errors.New(st.Message()))
+	for _, d := range st.Details() {
+		if msg, ok := d.(proto.Message); ok {
+			if detail, derr := connect.NewErrorDetail(msg); derr == nil {
+				err.AddDetail(detail)
+			}
+		}
+	}
+	return err
+}
+
+func headerToMD(h http.Header) metadata.MD {
+	md := metadata.MD{}
+	for k, vs := range h {
+		md.Append(strings.ToLower(k), vs...)
+	}
+	return md
+}
+
+// connectAgentClient adapts the generated Connect client to agentv1.AgentAPIClient.
+type connectAgentClient struct {
+	c agentv1connect.AgentAPIClient
+}
+
+func (a *connectAgentClient) RegisterAgent(ctx context.Context, in *agentv1.RegisterAgentRequest, opts ...grpc.CallOption) (*agentv1.RegisterAgentResponse, error) {
+	return connectUnary(ctx, a.c.RegisterAgent, in, opts)
+}
+
+func (a *connectAgentClient) GetAgent(ctx context.Context, in *agentv1.GetAgentRequest, opts ...grpc.CallOption) (*agentv1.GetAgentResponse, error) {
+	return connectUnary(ctx, a.c.GetAgent, in, opts)
+}
+
+func (a *connectAgentClient) ListAgents(ctx context.Context, in *agentv1.ListAgentsRequest, opts ...grpc.CallOption) (*agentv1.ListAgentsResponse, error) {
+	return connectUnary(ctx, a.c.ListAgents, in, opts)
+}
+
+func (a *connectAgentClient) Heartbeat(ctx context.Context, in *agentv1.HeartbeatRequest, opts ...grpc.CallOption) (*agentv1.HeartbeatResponse, error) {
+	return connectUnary(ctx, a.c.Heartbeat, in, opts)
+}
+
+// connectClusterClient adapts the generated Connect client to clusterv1.ClusterAPIClient.
+type connectClusterClient struct {
+	c clusterv1connect.ClusterAPIClient
+}
+
+func (a *connectClusterClient) CreateCluster(ctx context.Context, in *clusterv1.CreateClusterRequest, opts ...grpc.CallOption) (*clusterv1.CreateClusterResponse, error) {
+	return connectUnary(ctx, a.c.CreateCluster, in, opts)
+}
+
+func (a *connectClusterClient) GetCluster(ctx context.Context, in *clusterv1.GetClusterRequest, opts ...grpc.CallOption) (*clusterv1.GetClusterResponse, error) {
+	return connectUnary(ctx, a.c.GetCluster, in, opts)
+}
+
+func (a *connectClusterClient) GetClusterStatus(ctx context.Context, in *clusterv1.GetClusterStatusRequest, opts ...grpc.CallOption) (*clusterv1.GetClusterStatusResponse, error) {
+	return connectUnary(ctx, a.c.GetClusterStatus, in, opts)
+}
+
+func (a *connectClusterClient) ListClusters(ctx context.Context, in *clusterv1.ListClustersRequest, opts ...grpc.CallOption) (*clusterv1.ListClustersResponse, error) {
+	return connectUnary(ctx, a.c.ListClusters, in, opts)
+}
+
+func (a *connectClusterClient) UpdateCluster(ctx context.Context, in *clusterv1.UpdateClusterRequest, opts ...grpc.CallOption) (*clusterv1.UpdateClusterResponse, error) {
+	return connectUnary(ctx, a.c.UpdateCluster, in, opts)
+}
+
+func (a *connectClusterClient) DeleteCluster(ctx context.Context, in *clusterv1.DeleteClusterRequest, opts ...grpc.CallOption) (*clusterv1.DeleteClusterResponse, error) {
+	return connectUnary(ctx, a.c.DeleteCluster, in, opts)
+}
+
+func (a *connectClusterClient) CreateClusterToken(ctx context.Context, in *clusterv1.CreateClusterTokenRequest, opts ...grpc.CallOption) (*clusterv1.CreateClusterTokenResponse, error) {
+	return connectUnary(ctx, a.c.CreateClusterToken, in, opts)
+}
+
+func (a *connectClusterClient) ListClusterTokens(ctx context.Context, in *clusterv1.ListClusterTokensRequest, opts ...grpc.CallOption) (*clusterv1.ListClusterTokensResponse, error) {
+	return connectUnary(ctx, a.c.ListClusterTokens, in, opts)
+}
+
+func (a *connectClusterClient) GetClusterToken(ctx context.Context, in *clusterv1.GetClusterTokenRequest, opts ...grpc.CallOption) (*clusterv1.GetClusterTokenResponse, error) {
+	return connectUnary(ctx, a.c.GetClusterToken, in, opts)
+}
+
+func (a *connectClusterClient) RevokeClusterToken(ctx context.Context, in *clusterv1.RevokeClusterTokenRequest, opts ...grpc.CallOption) (*clusterv1.RevokeClusterTokenResponse, error) {
+	return connectUnary(ctx, a.c.RevokeClusterToken, in, opts)
+}
+
+func (a *connectClusterClient) ReportClusterStatus(ctx context.Context, in *clusterv1.ReportClusterStatusRequest, opts ...grpc.CallOption) (*clusterv1.ReportClusterStatusResponse, error) {
+	return connectUnary(ctx, a.c.ReportClusterStatus, in, opts)
+}
+
+func (a *connectClusterClient) ListWorkloads(ctx context.Context, in *clusterv1.ListWorkloadsRequest, opts ...grpc.CallOption) (*clusterv1.ListWorkloadsResponse, error) {
+	return connectUnary(ctx, a.c.ListWorkloads, in, opts)
+}
+
+func (a *connectClusterClient) ReportWorkloadStatus(ctx context.Context, in *clusterv1.ReportWorkloadStatusRequest, opts ...grpc.CallOption) (*clusterv1.ReportWorkloadStatusResponse, error) {
+	return connectUnary(ctx, a.c.ReportWorkloadStatus, in, opts)
+}
+
+// connectHealthcheckClient adapts the generated Connect client to healthcheckv1.HealthcheckAPIClient.
+type connectHealthcheckClient struct {
+	c healthcheckv1connect.HealthcheckAPIClient
+}
+
+func (a *connectHealthcheckClient) Healthcheck(ctx context.Context, in *healthcheckv1.HealthcheckRequest, opts ...grpc.CallOption) (*healthcheckv1.HealthcheckResponse, error) {
+	return connectUnary(ctx, a.c.Healthcheck, in, opts)
+}
+
+// connectRunnerClient adapts the generated Connect client to runnerv1.RunnerAPIClient.
+type connectRunnerClient struct {
+	c runnerv1connect.RunnerAPIClient
+}
+
+func (a *connectRunnerClient) CreateRunner(ctx context.Context, in *runnerv1.CreateRunnerRequest, opts ...grpc.CallOption) (*runnerv1.CreateRunnerResponse, error) {
+	return connectUnary(ctx, a.c.CreateRunner, in, opts)
+}
+
+func (a *connectRunnerClient) GetRunner(ctx context.Context, in *runnerv1.GetRunnerRequest, opts ...grpc.CallOption) (*runnerv1.GetRunnerResponse, error) {
+	return connectUnary(ctx, a.c.GetRunner, in, opts)
+}
+
+func (a *connectRunnerClient) ListRunners(ctx context.Context, in *runnerv1.ListRunnersRequest, opts ...grpc.CallOption) (*runnerv1.ListRunnersResponse, error) {
+	return connectUnary(ctx, a.c.ListRunners, in, opts)
+}
+
+func (a *connectRunnerClient) UpdateRunner(ctx context.Context, in *runnerv1.UpdateRunnerRequest, opts ...grpc.CallOption) (*runnerv1.UpdateRunnerResponse, error) {
+	return connectUnary(ctx, a.c.UpdateRunner, in, opts)
+}
+
+func (a *connectRunnerClient) DeleteRunner(ctx context.Context, in *runnerv1.DeleteRunnerRequest, opts ...grpc.CallOption) (*runnerv1.DeleteRunnerResponse, error) {
+	return connectUnary(ctx, a.c.DeleteRunner, in, opts)
+}
+
+func (a *connectRunnerClient) CreateRunnerToken(ctx context.Context, in *runnerv1.CreateRunnerTokenRequest, opts ...grpc.CallOption) (*runnerv1.CreateRunnerTokenResponse, error) {
+	return connectUnary(ctx, a.c.CreateRunnerToken, in, opts)
+}
+
+func (a *connectRunnerClient) ListRunnerTokens(ctx context.Context, in *runnerv1.ListRunnerTokensRequest, opts ...grpc.CallOption) (*runnerv1.ListRunnerTokensResponse, error) {
+	return connectUnary(ctx, a.c.ListRunnerTokens, in, opts)
+}
+
+func (a *connectRunnerClient) GetRunnerToken(ctx context.Context, in *runnerv1.GetRunnerTokenRequest, opts ...grpc.CallOption) (*runnerv1.GetRunnerTokenResponse, error) {
+	return connectUnary(ctx, a.c.GetRunnerToken, in, opts)
+}
+
+func (a *connectRunnerClient) RevokeRunnerToken(ctx context.Context, in *runnerv1.RevokeRunnerTokenRequest, opts ...grpc.CallOption) (*runnerv1.RevokeRunnerTokenResponse, error) {
+	return connectUnary(ctx, a.c.RevokeRunnerToken, in, opts)
+}
+
+// connectServiceAccountClient adapts the generated Connect client to serviceaccountv1.ServiceAccountAPIClient.
+type connectServiceAccountClient struct {
+	c serviceaccountv1connect.ServiceAccountAPIClient
+}
+
+func (a *connectServiceAccountClient) CreateServiceAccount(ctx context.Context, in *serviceaccountv1.CreateServiceAccountRequest, opts ...grpc.CallOption) (*serviceaccountv1.CreateServiceAccountResponse, error) {
+	return connectUnary(ctx, a.c.CreateServiceAccount, in, opts)
+}
+
+func (a *connectServiceAccountClient) GetServiceAccount(ctx context.Context, in *serviceaccountv1.GetServiceAccountRequest, opts ...grpc.CallOption) (*serviceaccountv1.GetServiceAccountResponse, error) {
+	return connectUnary(ctx, a.c.GetServiceAccount, in, opts)
+}
+
+func (a *connectServiceAccountClient) ListServiceAccounts(ctx context.Context, in *serviceaccountv1.ListServiceAccountsRequest, opts ...grpc.CallOption) (*serviceaccountv1.ListServiceAccountsResponse, error) {
+	return connectUnary(ctx, a.c.ListServiceAccounts, in, opts)
+}
+
+func (a *connectServiceAccountClient) UpdateServiceAccount(ctx context.Context, in *serviceaccountv1.UpdateServiceAccountRequest, opts ...grpc.CallOption) (*serviceaccountv1.UpdateServiceAccountResponse, error) {
+	return connectUnary(ctx, a.c.UpdateServiceAccount, in, opts)
+}
+
+func (a *connectServiceAccountClient) DeleteServiceAccount(ctx context.Context, in *serviceaccountv1.DeleteServiceAccountRequest, opts ...grpc.CallOption) (*serviceaccountv1.DeleteServiceAccountResponse, error) {
+	return connectUnary(ctx, a.c.DeleteServiceAccount, in, opts)
+}
+
+func (a *connectServiceAccountClient) CreateServiceAccountToken(ctx context.Context, in *serviceaccountv1.CreateServiceAccountTokenRequest, opts ...grpc.CallOption) (*serviceaccountv1.CreateServiceAccountTokenResponse, error) {
+	return connectUnary(ctx, a.c.CreateServiceAccountToken, in, opts)
+}
+
+func (a *connectServiceAccountClient) ListServiceAccountTokens(ctx context.Context, in *serviceaccountv1.ListServiceAccountTokensRequest, opts ...grpc.CallOption) (*serviceaccountv1.ListServiceAccountTokensResponse, error) {
+	return connectUnary(ctx, a.c.ListServiceAccountTokens, in, opts)
+}
+
+func (a *connectServiceAccountClient) GetServiceAccountToken(ctx context.Context, in *serviceaccountv1.GetServiceAccountTokenRequest, opts ...grpc.CallOption) (*serviceaccountv1.GetServiceAccountTokenResponse, error) {
+	return connectUnary(ctx, a.c.GetServiceAccountToken, in, opts)
+}
+
+func (a *connectServiceAccountClient) RevokeServiceAccountToken(ctx context.Context, in *serviceaccountv1.RevokeServiceAccountTokenRequest, opts ...grpc.CallOption) (*serviceaccountv1.RevokeServiceAccountTokenResponse, error) {
+	return connectUnary(ctx, a.c.RevokeServiceAccountToken, in, opts)
+}
+
+// connectUserClient adapts the generated Connect client to userv1.UserAPIClient.
+type connectUserClient struct {
+	c userv1connect.UserAPIClient
+}
+
+func (a *connectUserClient) GetUser(ctx context.Context, in *userv1.GetUserRequest, opts ...grpc.CallOption) (*userv1.GetUserResponse, error) {
+	return connectUnary(ctx, a.c.GetUser, in, opts)
+}
+
+func (a *connectUserClient) CreatePersonalAccessToken(ctx context.Context, in *userv1.CreatePersonalAccessTokenRequest, opts ...grpc.CallOption) (*userv1.CreatePersonalAccessTokenResponse, error) {
+	return connectUnary(ctx, a.c.CreatePersonalAccessToken, in, opts)
+}
+
+func (a *connectUserClient) ListPersonalAccessTokens(ctx context.Context, in *userv1.ListPersonalAccessTokensRequest, opts ...grpc.CallOption) (*userv1.ListPersonalAccessTokensResponse, error) {
+	return connectUnary(ctx, a.c.ListPersonalAccessTokens, in, opts)
+}
+
+func (a *connectUserClient) GetPersonalAccessToken(ctx context.Context, in *userv1.GetPersonalAccessTokenRequest, opts ...grpc.CallOption) (*userv1.GetPersonalAccessTokenResponse, error) {
+	return connectUnary(ctx, a.c.GetPersonalAccessToken, in, opts)
+}
+
+func (a *connectUserClient) RevokePersonalAccessToken(ctx context.Context, in *userv1.RevokePersonalAccessTokenRequest, opts ...grpc.CallOption) (*userv1.RevokePersonalAccessTokenResponse, error) {
+	return connectUnary(ctx, a.c.RevokePersonalAccessToken, in, opts)
+}
@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"connectrpc.com/connect"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	agentv1 "go.admiral.io/sdk/proto/agent/v1"
+	"go.admiral.io/sdk/proto/agent/v1/agentv1connect"
+)
+
+type testAgentHandler struct {
+	agentv1connect.UnimplementedAgentAPIHandler
+	lastHeader http.Header
+}
+
+func (h *testAgentHandler) GetAgent(_ context.Context, req *connect.Request[agentv1.GetAgentRequest]) (*connect.Response[agentv1.GetAgentResponse], error) {
+	h.lastHeader = req.Header().Clone()
+	if req.Msg.GetAgentId() == "missing" {
+		return nil, connect.NewError(connect.CodeNotFound, errors.New("agent not found"))
+	}
+	resp := connect.NewResponse(&agentv1.GetAgentResponse{
+		Agent: &agentv1.Agent{Id: req.Msg.GetAgentId(), DisplayName: "test-agent"},
+	})
+	resp.Header().Set("X-Request-Id", "req-123")
+	return resp, nil
+}
+
+func newTestConnectClient(t *testing.T, handler *testAgentHandler, encoding Encoding, configure ...func(*Config)) *Client {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.Handle(agentv1connect.NewAgentAPIHandler(handler))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	cfg := Config{
+		HostPort:  strings.TrimPrefix(srv.URL, "http://"),
+		AuthToken: "this-is-a-valid-opaque-token-12345",
+		Transport: TransportConnect,
+		ConnectionOptions: ConnectionOptions{
+			Insecure: true,
+			Encoding: encoding,
+		},
+	}
+	for _, f := range configure {
+		f(&cfg)
+	}
+	c, err := New(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func TestConnectTransport_Unary(t *testing.T) {
+	for _, encoding := range []Encoding{EncodingProto, EncodingJSON} {
+		t.Run(encoding.String(), func(t *testing.T) {
+			handler := &testAgentHandler{}
+			c := newTestConnectClient(t, handler, encoding)
+
+			ctx := metadata.AppendToOutgoingContext(context.Background(), "x-trace", "abc")
+			var header metadata.MD
+			resp, err := c.Agent().GetAgent(ctx, &agentv1.GetAgentRequest{AgentId: "agent-1"}, grpc.Header(&header))
+			if err != nil {
+				t.Fatalf("GetAgent() error = %v", err)
+			}
+			if got := resp.GetAgent().GetId(); got != "agent-1" {
+				t.Errorf("agent id = %q, want %q", got, "agent-1")
+			}
+			if got := handler.lastHeader.Get("Authorization"); got != "Bearer this-is-a-valid-opaque-token-12345" {
+				t.Errorf("Authorization = %q", got)
+			}
+			if got := handler.lastHeader.Get("User-Agent"); got != ClientUserAgent() {
+				t.Errorf("User-Agent = %q, want %q", got, ClientUserAgent())
+			}
+			if got := handler.lastHeader.Get("X-Trace"); got != "abc" {
+				t.Errorf("X-Trace = %q, want %q", got, "abc")
+			}
+			if got := header.Get("x-request-id"); len(got) != 1 || got[0] != "req-123" {
+				t.Errorf("response header x-request-id = %v, want [req-123]", got)
+			}
+		})
+	}
+}
+
+func TestConnectTransport_ErrorsAreGRPCStatus(t *testing.T) {
+	c := newTestConnectClient(t, &testAgentHandler{}, EncodingProto)
+
+	_, err := c.Agent().GetAgent(context.Background(), &agentv1.GetAgentRequest{AgentId: "missing"})
+	if got := status.Code(err); got != codes.NotFound {
+		t.Fatalf("status.Code() = %v, want %v (err = %v)", got, codes.NotFound, err)
+	}
+	if got := status.Convert(err).Message(); got != "agent not found" {
+		t.Errorf("message = %q, want %q", got, "agent not found")
+	}
+
+	_, err = c.Cluster().GetCluster(context.Background(), nil)
+	if got := status.Code(err); got != codes.Unimplemented {
+		t.Errorf("status.Code() = %v, want %v", got, codes.Unimplemented)
+	}
+}
+
+func TestConfig_InvalidTransport(t *testing.T) {
+	cfg := Config{
+		AuthToken: "this-is-a-valid-opaque-token-12345",
+		Transport: Transport(42),
+	}
+	if err := cfg.CheckAndSetDefaults(); err == nil {
+		t.Fatal("CheckAndSetDefaults() expected error for unknown transport")
+	}
+}
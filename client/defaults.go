@@ -13,3 +13,34 @@ const DefaultKeepAliveTime = 30 * time.Second
 
 // DefaultKeepAliveTimeout is the default timeout for keepalive ping responses.
 const DefaultKeepAliveTimeout = 90 * time.Second
+
+// DefaultHealthCheckInterval is the default interval between health probes
+// of each endpoint when Config.Endpoints is set.
+const DefaultHealthCheckInterval = 10 * time.Second
+
+// DefaultRESTPathPrefix is the path the HTTP/JSON gateway is mounted under.
+const DefaultRESTPathPrefix = "/api"
+
+// DefaultRetryMaxAttempts is the default total number of attempts per RPC
+// when a RetryPolicy is configured.
+const DefaultRetryMaxAttempts = 4
+
+// DefaultRetryInitialBackoff is the default delay before the first retry.
+const DefaultRetryInitialBackoff = 100 * time.Millisecond
+
+// DefaultRetryMaxBackoff is the default cap on the delay between retries.
+const DefaultRetryMaxBackoff = 5 * time.Second
+
+// DefaultRetryBackoffMultiplier is the default growth factor between retries.
+const DefaultRetryBackoffMultiplier = 2.0
+
+// DefaultRetryJitter is the default fraction by which retry delays are
+// randomized.
+const DefaultRetryJitter = 0.2
+
+// DefaultJWKSCacheTTL is how long a Verifier trusts a fetched key set.
+const DefaultJWKSCacheTTL = time.Hour
+
+// DefaultJWKSMinRefreshInterval is the minimum time between key set fetches
+// triggered by an unknown key ID.
+const DefaultJWKSMinRefreshInterval = 30 * time.Second
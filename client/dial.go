@@ -0,0 +1,207 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/net/proxy"
+)
+
+// unixSocketPath returns the socket path of a unix:///path or unix:path
+// target, and whether hostPort is a Unix socket target at all.
+func unixSocketPath(hostPort string) (string, bool) {
+	rest, ok := strings.CutPrefix(hostPort, "unix:")
+	if !ok {
+		return "", false
+	}
+	return strings.TrimPrefix(rest, "//"), true
+}
+
+// validateTarget checks that hostPort is host:port or a Unix socket target.
+func validateTarget(hostPort string) error {
+	if path, ok := unixSocketPath(hostPort); ok {
+		if path == "" {
+			return fmt.Errorf("invalid target %q: unix socket path required", hostPort)
+		}
+		return nil
+	}
+	if scheme, _, ok := strings.Cut(hostPort, "://"); ok {
+		return fmt.Errorf("unsupported target scheme %q in %q (want host:port or unix:///path)", scheme, hostPort)
+	}
+	if _, _, err := net.SplitHostPort(hostPort); err != nil {
+		return fmt.Errorf("invalid host:port format %q (both host and port required): %w", hostPort, err)
+	}
+	return nil
+}
+
+// validateProxy checks that rawURL is an HTTP CONNECT or SOCKS5 proxy URL.
+func validateProxy(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return fmt.Errorf("unsupported proxy scheme %q (want http, https, socks5 or socks5h)", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return fmt.Errorf("invalid proxy URL %q: host required", u.Redacted())
+	}
+	return nil
+}
+
+// dialer returns the function that opens connections to the API for every
+// transport: to the Unix socket for unix targets, otherwise through the
+// configured or environment proxy, or directly.
+func (c *Config) dialer() func(ctx context.Context, addr string) (net.Conn, error) {
+	opts := c.ConnectionOptions
+	d := &net.Dialer{Timeout: opts.DialTimeout}
+	if opts.EnableKeepAliveCheck {
+		d.KeepAlive = opts.KeepAliveTime
+	}
+
+	if path, ok := unixSocketPath(c.HostPort); ok {
+		return func(ctx context.Context, _ string) (net.Conn, error) {
+			return d.DialContext(ctx, "unix", path)
+		}
+	}
+
+	env := httpproxy.FromEnvironment()
+	if opts.Proxy != "" {
+		env.HTTPProxy, env.HTTPSProxy = opts.Proxy, opts.Proxy
+	}
+	proxyFor := env.ProxyFunc()
+	scheme := "https"
+	if opts.Insecure {
+		scheme = "http"
+	}
+	return func(ctx context.Context, addr string) (net.Conn, error) {
+		proxyURL, err := proxyFor(&url.URL{Scheme: scheme, Host: addr})
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy configuration: %w", err)
+		}
+		if proxyURL == nil {
+			return d.DialContext(ctx, "tcp", addr)
+		}
+		return dialProxy(ctx, d, proxyURL, addr)
+	}
+}
+
+// dialProxy opens a tunnel to addr through the proxy at proxyURL.
+func dialProxy(ctx context.Context, d *net.Dialer, proxyURL *url.URL, addr string) (net.Conn, error) {
+	switch proxyURL.Scheme {
+	case "socks5", "socks5h":
+		var auth *proxy.Auth
+		if u := proxyURL.User; u != nil {
+			password, _ := u.Password()
+			auth = &proxy.Auth{User: u.Username(), Password: password}
+		}
+		socks, err := proxy.SOCKS5("tcp", proxyAddr(proxyURL), auth, d)
+		if err != nil {
+			return nil, err
+		}
+		conn, err := socks.(proxy.ContextDialer).DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return nil, fmt.Errorf("SOCKS5 proxy %s: %w", proxyURL.Redacted(), err)
+		}
+		return conn, nil
+	case "http", "https":
+		return dialConnect(ctx, d, proxyURL, addr)
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", proxyURL.Scheme)
+	}
+}
+
+// dialConnect opens a tunnel to addr with an HTTP CONNECT request, sending
+// the proxy URL's user info as Basic credentials.
+func dialConnect(ctx context.Context, d *net.Dialer, proxyURL *url.URL, addr string) (_ net.Conn, err error) {
+	conn, err := d.DialContext(ctx, "tcp", proxyAddr(proxyURL))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = conn.Close()
+		}
+	}()
+
+	// Bound the handshake by ctx; net.Conn has no context of its own.
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Unix(1, 0)) })
+	defer stop()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+		defer func() { _ = conn.SetDeadline(time.Time{}) }()
+	}
+
+	if proxyURL.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: proxyURL.Hostname(), MinVersion: tls.VersionTLS12})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return nil, fmt.Errorf("TLS handshake with proxy %s: %w", proxyURL.Redacted(), err)
+		}
+		conn = tlsConn
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: http.Header{"User-Agent": {ClientUserAgent()}},
+	}
+	if u := proxyURL.User; u != nil {
+		password, _ := u.Password()
+		req.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(u.Username()+":"+password)))
+	}
+	if err := req.Write(conn); err != nil {
+		return nil, fmt.Errorf("proxy %s: %w", proxyURL.Redacted(), err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, fmt.Errorf("proxy %s: %w", proxyURL.Redacted(), err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("proxy %s refused CONNECT to %s: %s", proxyURL.Redacted(), addr, resp.Status)
+	}
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+// proxyAddr returns the proxy's host:port, adding the scheme's default
+// port when the URL has none.
+func proxyAddr(u *url.URL) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	port := "1080"
+	switch u.Scheme {
+	case "http":
+		port = "80"
+	case "https":
+		port = "443"
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// bufferedConn returns bytes the proxy sent after its CONNECT response
+// before reading from the connection itself.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
@@ -0,0 +1,319 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+
+	healthcheckv1 "go.admiral.io/sdk/proto/healthcheck/v1"
+)
+
+type testHealthcheckServer struct {
+	healthcheckv1.UnimplementedHealthcheckAPIServer
+}
+
+func (testHealthcheckServer) Healthcheck(context.Context, *healthcheckv1.HealthcheckRequest) (*healthcheckv1.HealthcheckResponse, error) {
+	return &healthcheckv1.HealthcheckResponse{}, nil
+}
+
+// serveHealthcheck serves the HealthcheckAPI over gRPC on lis.
+func serveHealthcheck(t *testing.T, lis net.Listener) {
+	t.Helper()
+	gs := grpc.NewServer()
+	healthcheckv1.RegisterHealthcheckAPIServer(gs, testHealthcheckServer{})
+	go func() { _ = gs.Serve(lis) }()
+	t.Cleanup(gs.Stop)
+}
+
+// serveHealthcheckREST serves the HealthcheckAPI gateway routes under /api
+// on lis.
+func serveHealthcheckREST(t *testing.T, lis net.Listener) {
+	t.Helper()
+	gw := runtime.NewServeMux()
+	if err := healthcheckv1.RegisterHealthcheckAPIHandlerServer(context.Background(), gw, testHealthcheckServer{}); err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: http.StripPrefix("/api", gw)}
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(func() { _ = srv.Close() })
+}
+
+func listen(t *testing.T, network, addr string) net.Listener {
+	t.Helper()
+	lis, err := net.Listen(network, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = lis.Close() })
+	return lis
+}
+
+// testProxy is an HTTP CONNECT or SOCKS5 proxy that tunnels every
+// connection to backend, recording the destination clients asked for.
+type testProxy struct {
+	backend string
+
+	mu      sync.Mutex
+	targets []string
+}
+
+func (p *testProxy) record(target string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.targets = append(p.targets, target)
+}
+
+func (p *testProxy) Targets() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.targets...)
+}
+
+func (p *testProxy) tunnel(client net.Conn, r io.Reader) {
+	backend, err := net.Dial("tcp", p.backend)
+	if err != nil {
+		_ = client.Close()
+		return
+	}
+	go func() { _, _ = io.Copy(backend, r); _ = backend.Close() }()
+	_, _ = io.Copy(client, backend)
+	_ = client.Close()
+}
+
+// startConnectProxy starts an HTTP CONNECT proxy that requires the Basic
+// credentials user:secret.
+func startConnectProxy(t *testing.T, backend string) (*testProxy, string) {
+	t.Helper()
+	p := &testProxy{backend: backend}
+	lis := listen(t, "tcp", "127.0.0.1:0")
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := parseProxyAuth(r.Header.Get("Proxy-Authorization")); r.Method != http.MethodConnect || !ok || user != "user" || pass != "secret" {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		p.record(r.Host)
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			return
+		}
+		_, _ = conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+		p.tunnel(conn, rw)
+	})}
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(func() { _ = srv.Close() })
+	return p, lis.Addr().String()
+}
+
+func parseProxyAuth(header string) (user, pass string, ok bool) {
+	r := &http.Request{Header: http.Header{"Authorization": {header}}}
+	return r.BasicAuth()
+}
+
+// startSOCKS5Proxy starts a SOCKS5 proxy that requires the credentials
+// user:secret.
+func startSOCKS5Proxy(t *testing.T, backend string) (*testProxy, string) {
+	t.Helper()
+	p := &testProxy{backend: backend}
+	lis := listen(t, "tcp", "127.0.0.1:0")
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go p.serveSOCKS5(conn)
+		}
+	}()
+	return p, lis.Addr().String()
+}
+
+func (p *testProxy) serveSOCKS5(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	readN := func(n int) []byte {
+		b := make([]byte, n)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil
+		}
+		return b
+	}
+	// Greeting: offer username/password authentication.
+	if hdr := readN(2); hdr == nil || readN(int(hdr[1])) == nil {
+		_ = conn.Close()
+		return
+	}
+	_, _ = conn.Write([]byte{5, 2})
+	// RFC 1929 username/password.
+	ver := readN(2)
+	user := string(readN(int(ver[1])))
+	pass := string(readN(int(readN(1)[0])))
+	if user != "user" || pass != "secret" {
+		_, _ = conn.Write([]byte{1, 1})
+		_ = conn.Close()
+		return
+	}
+	_, _ = conn.Write([]byte{1, 0})
+	// CONNECT request; the SDK sends domain names for the proxy to resolve.
+	req := readN(4)
+	var host string
+	switch req[3] {
+	case 3:
+		host = string(readN(int(readN(1)[0])))
+	case 1:
+		host = net.IP(readN(4)).String()
+	}
+	port := binary.BigEndian.Uint16(readN(2))
+	p.record(net.JoinHostPort(host, strconv.Itoa(int(port))))
+	_, _ = conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+	p.tunnel(conn, r)
+}
+
+func healthcheck(t *testing.T, cfg Config) error {
+	t.Helper()
+	cfg.AuthToken = "this-is-a-valid-opaque-token-12345"
+	c, err := New(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = c.Healthcheck().Healthcheck(ctx, &healthcheckv1.HealthcheckRequest{})
+	return err
+}
+
+func TestDial_HTTPConnectProxy(t *testing.T) {
+	grpcLis := listen(t, "tcp", "127.0.0.1:0")
+	serveHealthcheck(t, grpcLis)
+	restLis := listen(t, "tcp", "127.0.0.1:0")
+	serveHealthcheckREST(t, restLis)
+
+	for name, tt := range map[string]struct {
+		transport Transport
+		backend   string
+	}{
+		"grpc": {TransportGRPC, grpcLis.Addr().String()},
+		"rest": {TransportREST, restLis.Addr().String()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			proxy, addr := startConnectProxy(t, tt.backend)
+			err := healthcheck(t, Config{
+				HostPort:  "admiral.test:8443",
+				Transport: tt.transport,
+				ConnectionOptions: ConnectionOptions{
+					Insecure: true,
+					Proxy:    "http://user:secret@" + addr,
+				},
+			})
+			if err != nil {
+				t.Fatalf("Healthcheck() through proxy error = %v", err)
+			}
+			if got := proxy.Targets(); len(got) == 0 || got[0] != "admiral.test:8443" {
+				t.Errorf("proxy CONNECT targets = %v, want admiral.test:8443", got)
+			}
+		})
+	}
+
+	t.Run("wrong credentials", func(t *testing.T) {
+		_, addr := startConnectProxy(t, grpcLis.Addr().String())
+		err := healthcheck(t, Config{
+			HostPort:          "admiral.test:8443",
+			Transport:         TransportREST,
+			ConnectionOptions: ConnectionOptions{Insecure: true, Proxy: "http://user:wrong@" + addr},
+		})
+		if err == nil || !strings.Contains(err.Error(), "407") {
+			t.Errorf("Healthcheck() error = %v, want the proxy's 407", err)
+		}
+	})
+}
+
+func TestDial_SOCKS5Proxy(t *testing.T) {
+	lis := listen(t, "tcp", "127.0.0.1:0")
+	serveHealthcheck(t, lis)
+	proxy, addr := startSOCKS5Proxy(t, lis.Addr().String())
+
+	err := healthcheck(t, Config{
+		HostPort:          "admiral.test:8443",
+		ConnectionOptions: ConnectionOptions{Insecure: true, Proxy: "socks5://user:secret@" + addr},
+	})
+	if err != nil {
+		t.Fatalf("Healthcheck() through SOCKS5 proxy error = %v", err)
+	}
+	if got := proxy.Targets(); len(got) == 0 || got[0] != "admiral.test:8443" {
+		t.Errorf("SOCKS5 targets = %v, want admiral.test:8443", got)
+	}
+}
+
+func TestDial_ProxyFromEnvironment(t *testing.T) {
+	lis := listen(t, "tcp", "127.0.0.1:0")
+	serveHealthcheck(t, lis)
+	proxy, addr := startConnectProxy(t, lis.Addr().String())
+	t.Setenv("HTTP_PROXY", "http://user:secret@"+addr)
+	t.Setenv("NO_PROXY", "")
+
+	err := healthcheck(t, Config{HostPort: "admiral.test:8443", ConnectionOptions: ConnectionOptions{Insecure: true}})
+	if err != nil {
+		t.Fatalf("Healthcheck() through HTTP_PROXY error = %v", err)
+	}
+	if len(proxy.Targets()) == 0 {
+		t.Error("HTTP_PROXY was not used")
+	}
+}
+
+func TestDial_UnixSocket(t *testing.T) {
+	dir := t.TempDir()
+	grpcSock, restSock := filepath.Join(dir, "grpc.sock"), filepath.Join(dir, "rest.sock")
+	serveHealthcheck(t, listen(t, "unix", grpcSock))
+	serveHealthcheckREST(t, listen(t, "unix", restSock))
+
+	for name, cfg := range map[string]Config{
+		"grpc": {HostPort: "unix://" + grpcSock},
+		"rest": {HostPort: "unix:" + restSock, Transport: TransportREST},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg.ConnectionOptions.Insecure = true
+			if err := healthcheck(t, cfg); err != nil {
+				t.Errorf("Healthcheck() over %s error = %v", cfg.HostPort, err)
+			}
+		})
+	}
+}
+
+func TestConfig_TargetValidation(t *testing.T) {
+	tests := []struct {
+		hostPort string
+		proxy    string
+		want     string
+	}{
+		{hostPort: "api.admiral.io:443"},
+		{hostPort: "unix:///var/run/admiral.sock"},
+		{hostPort: "api.admiral.io:443", proxy: "socks5://proxy.internal"},
+		{hostPort: "api.admiral.io", want: "invalid host:port"},
+		{hostPort: "unix://", want: "unix socket path required"},
+		{hostPort: "https://api.admiral.io:443", want: "unsupported target scheme"},
+		{hostPort: "unix:///var/run/admiral.sock", proxy: "http://proxy:3128", want: "unix socket target"},
+		{hostPort: "api.admiral.io:443", proxy: "ftp://proxy", want: "unsupported proxy scheme"},
+		{hostPort: "api.admiral.io:443", proxy: "http://", want: "host required"},
+	}
+	for _, tt := range tests {
+		cfg := Config{HostPort: tt.hostPort, AuthToken: "test-token-value", ConnectionOptions: ConnectionOptions{Proxy: tt.proxy}}
+		err := cfg.CheckAndSetDefaults()
+		if tt.want == "" && err != nil {
+			t.Errorf("CheckAndSetDefaults(%q, proxy %q) error = %v", tt.hostPort, tt.proxy, err)
+		}
+		if tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+			t.Errorf("CheckAndSetDefaults(%q, proxy %q) error = %v, want %q", tt.hostPort, tt.proxy, err, tt.want)
+		}
+	}
+}
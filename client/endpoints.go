@@ -0,0 +1,521 @@
+package client
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+	"google.golang.org/grpc/status"
+
+	agentv1 "go.admiral.io/sdk/proto/agent/v1"
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+	healthcheckv1 "go.admiral.io/sdk/proto/healthcheck/v1"
+	runnerv1 "go.admiral.io/sdk/proto/runner/v1"
+	serviceaccountv1 "go.admiral.io/sdk/proto/serviceaccount/v1"
+	userv1 "go.admiral.io/sdk/proto/user/v1"
+)
+
+// dnsScheme prefixes an endpoint whose name is resolved to every address.
+const dnsScheme = "dns:///"
+
+// lookupHost resolves dns:/// endpoints; tests replace it.
+var lookupHost = net.DefaultResolver.LookupHost
+
+// checkEndpoints validates Endpoints and LoadBalancing and sets the health
+// check default.
+func (c *Config) checkEndpoints() error {
+	if c.HostPort != "" {
+		return errors.New("HostPort and Endpoints are mutually exclusive")
+	}
+	for _, ep := range c.Endpoints {
+		hostPort := strings.TrimPrefix(ep, dnsScheme)
+		if _, ok := unixSocketPath(hostPort); ok {
+			return fmt.Errorf("invalid endpoint %q: unix socket targets cannot be balanced; use HostPort", ep)
+		}
+		if err := validateTarget(hostPort); err != nil {
+			return fmt.Errorf("invalid endpoint: %w", err)
+		}
+	}
+	switch c.LoadBalancing {
+	case LoadBalancingPickFirst, LoadBalancingRoundRobin:
+	default:
+		return fmt.Errorf("unsupported load balancing policy %s", c.LoadBalancing)
+	}
+	if c.ConnectionOptions.HealthCheckInterval == 0 {
+		c.ConnectionOptions.HealthCheckInterval = DefaultHealthCheckInterval
+	}
+	return nil
+}
+
+// endpoint is one address of a client with several endpoints.
+type endpoint struct {
+	// entry is the Config.Endpoints entry the address came from.
+	entry string
+	addr  string
+	// serverName is the host the address was listed or resolved as, used
+	// for the :authority and to verify the server certificate.
+	serverName string
+	healthy    atomic.Bool
+
+	// probe is a connection to this address alone, for health checks over
+	// gRPC.
+	probe *grpc.ClientConn
+	// transport carries HTTP requests to this address.
+	transport http.RoundTripper
+}
+
+// close releases the endpoint's connections.
+func (e *endpoint) close() {
+	if e.probe != nil {
+		_ = e.probe.Close()
+	}
+	if t, ok := e.transport.(interface{ CloseIdleConnections() }); ok {
+		t.CloseIdleConnections()
+	}
+}
+
+// resolveEndpoints expands the configured endpoints into addresses,
+// resolving dns:/// entries.
+func resolveEndpoints(ctx context.Context, entries []string) ([]*endpoint, error) {
+	var endpoints []*endpoint
+	for _, entry := range entries {
+		hostPort, isDNS := strings.CutPrefix(entry, dnsScheme)
+		host, port, _ := net.SplitHostPort(hostPort)
+		if !isDNS {
+			endpoints = append(endpoints, &endpoint{entry: entry, addr: hostPort, serverName: host})
+			continue
+		}
+		addrs, err := lookupHost(ctx, host)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve endpoint %s: %w", entry, err)
+		}
+		for _, addr := range addrs {
+			endpoints = append(endpoints, &endpoint{entry: entry, addr: net.JoinHostPort(addr, port), serverName: host})
+		}
+	}
+	if len(endpoints) == 0 {
+		return nil, errors.New("endpoints resolved to no addresses")
+	}
+	for _, e := range endpoints {
+		e.healthy.Store(true)
+	}
+	return endpoints, nil
+}
+
+// endpointPool tracks the health of a client's endpoints and chooses one
+// for each HTTP request. The gRPC transport leaves the choice to the
+// channel's balancer, which it updates through onChange. dns:/// entries
+// are resolved again on every health check, so the endpoints follow DNS.
+type endpointPool struct {
+	policy  LoadBalancing
+	entries []string
+	logger  Logger
+	next    atomic.Uint64
+	// connect sets up the probe or transport of an endpoint added by
+	// refresh.
+	connect func(e *endpoint) error
+	// onChange is called after an endpoint's health changes or the
+	// endpoints change.
+	onChange func()
+
+	mu sync.Mutex
+	// endpoints is replaced, never modified, when DNS changes.
+	endpoints []*endpoint
+
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+type endpointKey struct{}
+
+// withEndpoint pins calls made with the returned context to e, for health
+// probes over the HTTP transports.
+func withEndpoint(ctx context.Context, e *endpoint) context.Context {
+	return context.WithValue(ctx, endpointKey{}, e)
+}
+
+// pick returns the endpoint for a request: the first healthy one for
+// LoadBalancingPickFirst, or the next healthy one for
+// LoadBalancingRoundRobin. When none is known to be healthy, it picks as if
+// all were, so calls still try to get through.
+func (p *endpointPool) pick(ctx context.Context) *endpoint {
+	if e, ok := ctx.Value(endpointKey{}).(*endpoint); ok {
+		return e
+	}
+	endpoints := p.list()
+	n := len(endpoints)
+	start := 0
+	if p.policy == LoadBalancingRoundRobin {
+		start = int((p.next.Add(1) - 1) % uint64(n))
+	}
+	for i := range n {
+		if e := endpoints[(start+i)%n]; e.healthy.Load() {
+			return e
+		}
+	}
+	return endpoints[start]
+}
+
+// list returns the current endpoints.
+func (p *endpointPool) list() []*endpoint {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.endpoints
+}
+
+// healthyAddresses returns the gRPC addresses of the healthy endpoints in
+// order, or of all endpoints when none is healthy.
+func (p *endpointPool) healthyAddresses() []resolver.Address {
+	var healthy, all []resolver.Address
+	for _, e := range p.list() {
+		addr := resolver.Address{Addr: e.addr, ServerName: e.serverName}
+		all = append(all, addr)
+		if e.healthy.Load() {
+			healthy = append(healthy, addr)
+		}
+	}
+	if len(healthy) == 0 {
+		return all
+	}
+	return healthy
+}
+
+// report records the outcome of a call to e. Failures that show the
+// endpoint could not be reached mark it unhealthy; any answer from the
+// server marks it healthy.
+func (p *endpointPool) report(ctx context.Context, e *endpoint, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		p.setHealthy(e, false, err)
+	default:
+		p.setHealthy(e, true, nil)
+	}
+}
+
+func (p *endpointPool) setHealthy(e *endpoint, healthy bool, err error) {
+	if e.healthy.Swap(healthy) == healthy {
+		return
+	}
+	if healthy {
+		p.logger.Infof("endpoint %s is healthy", e.addr)
+	} else {
+		p.logger.Warnf("endpoint %s is unhealthy: %v", e.addr, err)
+	}
+	if p.onChange != nil {
+		p.onChange()
+	}
+}
+
+// refresh resolves the dns:/// entries again and replaces the endpoints
+// whose addresses changed. Endpoints that are still listed keep their
+// connections and health; new ones start healthy. A failed lookup keeps the
+// current endpoints.
+func (p *endpointPool) refresh(ctx context.Context) {
+	if !slices.ContainsFunc(p.entries, func(entry string) bool { return strings.HasPrefix(entry, dnsScheme) }) {
+		return
+	}
+	resolved, err := resolveEndpoints(ctx, p.entries)
+	if err != nil {
+		if ctx.Err() == nil {
+			p.logger.Warnf("keeping current endpoints: %v", err)
+		}
+		return
+	}
+
+	type key struct{ entry, addr string }
+	current := p.list()
+	have := make(map[key]bool, len(current))
+	for _, e := range current {
+		have[key{e.entry, e.addr}] = true
+	}
+	want := make(map[key]bool, len(resolved))
+	for _, e := range resolved {
+		want[key{e.entry, e.addr}] = true
+	}
+
+	// Keep the configured order of entries, and within an entry put the
+	// addresses already in use first, so pick_first does not move.
+	var next, removed []*endpoint
+	added := 0
+	for i, entry := range p.entries {
+		if slices.Contains(p.entries[:i], entry) {
+			continue
+		}
+		for _, e := range current {
+			if e.entry == entry && want[key{e.entry, e.addr}] {
+				next = append(next, e)
+			}
+		}
+		for _, e := range resolved {
+			if e.entry != entry || have[key{e.entry, e.addr}] {
+				continue
+			}
+			if err := p.connect(e); err != nil {
+				p.logger.Warnf("skipping endpoint %s: %v", e.addr, err)
+				continue
+			}
+			next = append(next, e)
+			added++
+		}
+	}
+	for _, e := range current {
+		if !want[key{e.entry, e.addr}] {
+			removed = append(removed, e)
+		}
+	}
+	if added == 0 && len(removed) == 0 {
+		return
+	}
+	if len(next) == 0 {
+		p.logger.Warnf("keeping current endpoints: none of the resolved addresses could be used")
+		return
+	}
+
+	p.mu.Lock()
+	p.endpoints = next
+	p.mu.Unlock()
+	for _, e := range removed {
+		e.close()
+	}
+	p.logger.Infof("endpoints changed: %d added, %d removed, %d in use", added, len(removed), len(next))
+	if p.onChange != nil {
+		p.onChange()
+	}
+}
+
+// watch calls probe for every endpoint each interval until close, and
+// records the results. Between rounds it refreshes the endpoints.
+func (p *endpointPool) watch(interval time.Duration, probe func(ctx context.Context, e *endpoint) error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.stop = cancel
+	p.done = make(chan struct{})
+	go func() {
+		defer close(p.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			var wg sync.WaitGroup
+			for _, e := range p.list() {
+				wg.Go(func() {
+					probeCtx, cancel := context.WithTimeout(withEndpoint(ctx, e), interval)
+					defer cancel()
+					err := probe(probeCtx, e)
+					if ctx.Err() == nil {
+						p.report(probeCtx, e, err)
+					}
+				})
+			}
+			wg.Wait()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			refreshCtx, cancel := context.WithTimeout(ctx, interval)
+			p.refresh(refreshCtx)
+			cancel()
+		}
+	}()
+}
+
+// close stops health checks and closes the per-endpoint connections.
+func (p *endpointPool) close() {
+	if p.stop != nil {
+		p.stop()
+		<-p.done
+	}
+	for _, e := range p.list() {
+		e.close()
+	}
+}
+
+// newBalancedClient builds a Client that spreads calls over cfg.Endpoints.
+func newBalancedClient(ctx context.Context, cfg Config) (*Client, error) {
+	endpoints, err := resolveEndpoints(ctx, cfg.Endpoints)
+	if err != nil {
+		return nil, err
+	}
+	pool := &endpointPool{policy: cfg.LoadBalancing, entries: cfg.Endpoints, endpoints: endpoints, logger: cfg.Logger}
+	// HTTP base URLs name the first endpoint; each request is rewritten to
+	// the endpoint picked.
+	cfg.HostPort = endpoints[0].addr
+
+	var c *Client
+	switch cfg.Transport {
+	case TransportConnect, TransportREST:
+		c, err = newBalancedHTTPClient(cfg, pool)
+	default:
+		c, err = newBalancedGRPCClient(ctx, cfg, pool)
+	}
+	if err != nil {
+		pool.close()
+		return nil, err
+	}
+	c.pool = pool
+	cfg.Logger.Debugf("balancing Admiral API calls over %d endpoints (%s)", len(endpoints), cfg.LoadBalancing)
+	return c, nil
+}
+
+// newBalancedGRPCClient dials every endpoint on one channel, balanced by
+// gRPC's pick_first or round_robin policy. Unhealthy endpoints are removed
+// from the channel's addresses until they recover.
+func newBalancedGRPCClient(ctx context.Context, cfg Config, pool *endpointPool) (*Client, error) {
+	res := manual.NewBuilderWithScheme("admiral-endpoints")
+	res.InitialState(resolver.State{Addresses: pool.healthyAddresses()})
+
+	var (
+		mu    sync.Mutex
+		first = pool.endpoints[0].addr
+	)
+	pool.onChange = func() {
+		mu.Lock()
+		defer mu.Unlock()
+		addrs := pool.healthyAddresses()
+		if cfg.LoadBalancing == LoadBalancingPickFirst && addrs[0].Addr != first {
+			// pick_first stays on a connected address that is still
+			// listed. Offer the preferred endpoint alone first, so the
+			// channel fails back to it once it recovers.
+			res.UpdateState(resolver.State{Addresses: addrs[:1]})
+		}
+		first = addrs[0].Addr
+		res.UpdateState(resolver.State{Addresses: addrs})
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, cfg.ConnectionOptions.DialTimeout)
+	defer cancel()
+	dialOpts := append(grpcDialOptions(cfg),
+		grpc.WithResolvers(res),
+		grpc.WithDefaultServiceConfig(fmt.Sprintf(`{"loadBalancingConfig":[{%q:{}}]}`, cfg.LoadBalancing)))
+	conn, err := grpc.DialContext(dialCtx, res.Scheme()+":///admiral", dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial endpoints: %w", err)
+	}
+
+	if cfg.ConnectionOptions.HealthCheckInterval > 0 {
+		pool.connect = func(e *endpoint) error {
+			ctx, cancel := context.WithTimeout(context.Background(), cfg.ConnectionOptions.DialTimeout)
+			defer cancel()
+			ecfg := cfg
+			ecfg.HostPort = e.addr
+			var err error
+			e.probe, err = grpc.DialContext(ctx, e.addr, append(grpcDialOptions(ecfg), grpc.WithAuthority(e.serverName))...)
+			if err != nil {
+				return fmt.Errorf("failed to dial %s: %w", e.addr, err)
+			}
+			return nil
+		}
+		for _, e := range pool.endpoints {
+			if err := pool.connect(e); err != nil {
+				_ = conn.Close()
+				return nil, err
+			}
+		}
+		pool.watch(cfg.ConnectionOptions.HealthCheckInterval, func(ctx context.Context, e *endpoint) error {
+			_, err := healthcheckv1.NewHealthcheckAPIClient(e.probe).Healthcheck(ctx, &healthcheckv1.HealthcheckRequest{})
+			return err
+		})
+	}
+
+	return &Client{
+		conn:           conn,
+		logger:         cfg.Logger,
+		tokens:         cfg.TokenSource,
+		agent:          agentv1.NewAgentAPIClient(conn),
+		cluster:        clusterv1.NewClusterAPIClient(conn),
+		healthcheck:    healthcheckv1.NewHealthcheckAPIClient(conn),
+		runner:         runnerv1.NewRunnerAPIClient(conn),
+		serviceAccount: serviceaccountv1.NewServiceAccountAPIClient(conn),
+		user:           userv1.NewUserAPIClient(conn),
+	}, nil
+}
+
+// newBalancedHTTPClient builds a Connect or REST client whose requests are
+// sent to the endpoint the pool picks, with one HTTP transport per
+// endpoint.
+func newBalancedHTTPClient(cfg Config, pool *endpointPool) (*Client, error) {
+	httpClient := &http.Client{}
+	if cfg.ConnectionOptions.HTTPClient != nil {
+		*httpClient = *cfg.ConnectionOptions.HTTPClient
+	}
+	base := cfg // cfg is changed below to use the balanced transport
+	pool.connect = func(e *endpoint) error {
+		if base.ConnectionOptions.HTTPClient != nil {
+			e.transport = cmp.Or(base.ConnectionOptions.HTTPClient.Transport, http.DefaultTransport)
+			return nil
+		}
+		ecfg := base
+		ecfg.HostPort = e.addr
+		if tlsConfig := ecfg.ConnectionOptions.TLSConfig; tlsConfig != nil && tlsConfig.ServerName == "" {
+			tlsConfig = tlsConfig.Clone()
+			tlsConfig.ServerName = e.serverName
+			ecfg.ConnectionOptions.TLSConfig = tlsConfig
+		}
+		if ecfg.Transport == TransportREST {
+			e.transport = newHTTP1Client(ecfg).Transport
+		} else {
+			e.transport = newHTTPClient(ecfg).Transport
+		}
+		return nil
+	}
+	for _, e := range pool.endpoints {
+		_ = pool.connect(e)
+	}
+	httpClient.Transport = &balancedTransport{pool: pool}
+	cfg.ConnectionOptions.HTTPClient = httpClient
+
+	var c *Client
+	if cfg.Transport == TransportREST {
+		c = newRESTClient(cfg)
+	} else {
+		c = newConnectClient(cfg)
+	}
+	if cfg.ConnectionOptions.HealthCheckInterval > 0 {
+		pool.watch(cfg.ConnectionOptions.HealthCheckInterval, func(ctx context.Context, _ *endpoint) error {
+			// ctx pins the call to the endpoint being probed.
+			_, err := c.Healthcheck().Healthcheck(ctx, &healthcheckv1.HealthcheckRequest{})
+			return err
+		})
+	}
+	return c, nil
+}
+
+// balancedTransport sends each request to the endpoint the pool picks,
+// and reports failures to reach it.
+type balancedTransport struct {
+	pool *endpointPool
+}
+
+func (t *balancedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	e := t.pool.pick(ctx)
+	out := req.Clone(ctx)
+	out.URL.Host = e.addr
+	if _, port, err := net.SplitHostPort(e.addr); err == nil {
+		out.Host = net.JoinHostPort(e.serverName, port)
+	}
+	resp, err := e.transport.RoundTrip(out)
+	switch {
+	case err != nil:
+		t.pool.report(ctx, e, status.Error(codes.Unavailable, err.Error()))
+	case resp.StatusCode == http.StatusBadGateway || resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == http.StatusGatewayTimeout:
+		t.pool.report(ctx, e, status.Error(codes.Unavailable, resp.Status))
+	default:
+		t.pool.report(ctx, e, nil)
+	}
+	return resp, err
+}
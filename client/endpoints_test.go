@@ -0,0 +1,235 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+	healthcheckv1 "go.admiral.io/sdk/proto/healthcheck/v1"
+)
+
+// regionServer is one region's API. GetCluster answers with the region's
+// name as the cluster ID, so tests can tell which endpoint served a call.
+type regionServer struct {
+	clusterv1.UnimplementedClusterAPIServer
+	healthcheckv1.UnimplementedHealthcheckAPIServer
+	name      string
+	unhealthy atomic.Bool
+}
+
+func (s *regionServer) GetCluster(context.Context, *clusterv1.GetClusterRequest) (*clusterv1.GetClusterResponse, error) {
+	return &clusterv1.GetClusterResponse{Cluster: &clusterv1.Cluster{Id: s.name}}, nil
+}
+
+func (s *regionServer) Healthcheck(context.Context, *healthcheckv1.HealthcheckRequest) (*healthcheckv1.HealthcheckResponse, error) {
+	if s.unhealthy.Load() {
+		return nil, status.Error(codes.Unavailable, "draining")
+	}
+	return &healthcheckv1.HealthcheckResponse{}, nil
+}
+
+// startRegion serves a region over gRPC or, for rest, the HTTP/JSON
+// gateway, and returns a function that stops it.
+func startRegion(t *testing.T, name string, rest bool) (*regionServer, string, func()) {
+	t.Helper()
+	return serveRegion(t, name, listen(t, "tcp", "127.0.0.1:0"), rest)
+}
+
+func serveRegion(t *testing.T, name string, lis net.Listener, rest bool) (*regionServer, string, func()) {
+	t.Helper()
+	s := &regionServer{name: name}
+	if !rest {
+		gs := grpc.NewServer()
+		clusterv1.RegisterClusterAPIServer(gs, s)
+		healthcheckv1.RegisterHealthcheckAPIServer(gs, s)
+		go func() { _ = gs.Serve(lis) }()
+		t.Cleanup(gs.Stop)
+		return s, lis.Addr().String(), gs.Stop
+	}
+	gw := runtime.NewServeMux()
+	if err := clusterv1.RegisterClusterAPIHandlerServer(context.Background(), gw, s); err != nil {
+		t.Fatal(err)
+	}
+	if err := healthcheckv1.RegisterHealthcheckAPIHandlerServer(context.Background(), gw, s); err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: http.StripPrefix("/api", gw)}
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(func() { _ = srv.Close() })
+	return s, lis.Addr().String(), func() { _ = srv.Close() }
+}
+
+func newBalancedTestClient(t *testing.T, cfg Config) *Client {
+	t.Helper()
+	cfg.AuthToken = "this-is-a-valid-opaque-token-12345"
+	cfg.ConnectionOptions.Insecure = true
+	cfg.ConnectionOptions.HealthCheckInterval = 20 * time.Millisecond
+	cfg.ConnectionOptions.DialTimeout = time.Second
+	c, err := New(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+// servedBy returns the region that answered a GetCluster call, or "" if
+// the call failed.
+func servedBy(c *Client) string {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, err := c.Cluster().GetCluster(ctx, &clusterv1.GetClusterRequest{ClusterId: "c"})
+	if err != nil {
+		return ""
+	}
+	return resp.GetCluster().GetId()
+}
+
+// eventuallyServedBy waits until calls are answered by want.
+func eventuallyServedBy(t *testing.T, c *Client, want string) {
+	t.Helper()
+	var got string
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if got = servedBy(c); got == want {
+			return
+		}
+	}
+	t.Fatalf("calls served by %q, want %q", got, want)
+}
+
+func TestEndpoints_PickFirstFailover(t *testing.T) {
+	for name, transport := range map[string]Transport{"grpc": TransportGRPC, "rest": TransportREST} {
+		t.Run(name, func(t *testing.T) {
+			rest := transport == TransportREST
+			us, usAddr, _ := startRegion(t, "us", rest)
+			_, euAddr, _ := startRegion(t, "eu", rest)
+			ap, apAddr, stopAP := startRegion(t, "ap", rest)
+			c := newBalancedTestClient(t, Config{
+				Endpoints: []string{apAddr, usAddr, euAddr},
+				Transport: transport,
+			})
+			eventuallyServedBy(t, c, "ap")
+
+			// An endpoint that goes down is skipped.
+			stopAP()
+			ap.unhealthy.Store(true)
+			eventuallyServedBy(t, c, "us")
+
+			// An endpoint whose Healthcheck fails is skipped until it
+			// recovers.
+			us.unhealthy.Store(true)
+			eventuallyServedBy(t, c, "eu")
+			us.unhealthy.Store(false)
+			eventuallyServedBy(t, c, "us")
+		})
+	}
+}
+
+func TestEndpoints_RoundRobin(t *testing.T) {
+	for name, transport := range map[string]Transport{"grpc": TransportGRPC, "rest": TransportREST} {
+		t.Run(name, func(t *testing.T) {
+			rest := transport == TransportREST
+			_, usAddr, _ := startRegion(t, "us", rest)
+			eu, euAddr, _ := startRegion(t, "eu", rest)
+			c := newBalancedTestClient(t, Config{
+				Endpoints:     []string{usAddr, euAddr},
+				Transport:     transport,
+				LoadBalancing: LoadBalancingRoundRobin,
+			})
+
+			seen := map[string]bool{}
+			for deadline := time.Now().Add(5 * time.Second); len(seen) < 2 && time.Now().Before(deadline); {
+				seen[servedBy(c)] = true
+			}
+			if !seen["us"] || !seen["eu"] {
+				t.Fatalf("round robin served by %v, want us and eu", seen)
+			}
+
+			eu.unhealthy.Store(true)
+			time.Sleep(100 * time.Millisecond)
+			for range 10 {
+				if got := servedBy(c); got != "us" {
+					t.Fatalf("call served by %q with eu unhealthy, want us", got)
+				}
+			}
+		})
+	}
+}
+
+func TestEndpoints_DNS(t *testing.T) {
+	_, addr, _ := startRegion(t, "local", false)
+	_, port, _ := net.SplitHostPort(addr)
+	c := newBalancedTestClient(t, Config{HostPort: "dns:///localhost:" + port})
+	eventuallyServedBy(t, c, "local")
+}
+
+func TestEndpoints_DNSChange(t *testing.T) {
+	for name, transport := range map[string]Transport{"grpc": TransportGRPC, "rest": TransportREST} {
+		t.Run(name, func(t *testing.T) {
+			rest := transport == TransportREST
+			_, usAddr, _ := startRegion(t, "us", rest)
+			_, port, _ := net.SplitHostPort(usAddr)
+			lis, err := net.Listen("tcp", net.JoinHostPort("127.0.0.2", port))
+			if err != nil {
+				t.Skipf("cannot listen on 127.0.0.2: %v", err)
+			}
+			serveRegion(t, "eu", lis, rest)
+
+			var ip atomic.Value
+			ip.Store("127.0.0.1")
+			lookup := lookupHost
+			lookupHost = func(context.Context, string) ([]string, error) { return []string{ip.Load().(string)}, nil }
+			t.Cleanup(func() { lookupHost = lookup })
+
+			c := newBalancedTestClient(t, Config{
+				Endpoints: []string{"dns:///api.admiral.test:" + port},
+				Transport: transport,
+			})
+			eventuallyServedBy(t, c, "us")
+
+			// The name moves to another address without a restart.
+			ip.Store("127.0.0.2")
+			eventuallyServedBy(t, c, "eu")
+		})
+	}
+}
+
+func TestConfig_EndpointsValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{"with HostPort", Config{HostPort: "a:1", Endpoints: []string{"b:1"}}, "mutually exclusive"},
+		{"missing port", Config{Endpoints: []string{"a:1", "b"}}, "invalid endpoint"},
+		{"unix socket", Config{Endpoints: []string{"unix:///tmp/a.sock"}}, "cannot be balanced"},
+		{"bad DNS entry", Config{Endpoints: []string{"dns:///admiral"}}, "invalid endpoint"},
+		{"bad policy", Config{Endpoints: []string{"a:1"}, LoadBalancing: 7}, "unsupported load balancing policy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.AuthToken = "test-token-value"
+			if err := tt.cfg.CheckAndSetDefaults(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("CheckAndSetDefaults() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	cfg := Config{HostPort: "dns:///api.admiral.io:443", AuthToken: "test-token-value"}
+	if err := cfg.CheckAndSetDefaults(); err != nil {
+		t.Fatalf("CheckAndSetDefaults() error = %v", err)
+	}
+	if cfg.HostPort != "" || len(cfg.Endpoints) != 1 || cfg.ConnectionOptions.HealthCheckInterval != DefaultHealthCheckInterval {
+		t.Errorf("dns:/// HostPort = %q, Endpoints %v, interval %v", cfg.HostPort, cfg.Endpoints, cfg.ConnectionOptions.HealthCheckInterval)
+	}
+}
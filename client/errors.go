@@ -0,0 +1,158 @@
+package client
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Errors matched by the *Error returned from failed RPCs, on every
+// transport. Use errors.Is to test for them and errors.As to get the
+// *Error with the details.
+var (
+	ErrNotFound         = errors.New("not found")
+	ErrAlreadyExists    = errors.New("already exists")
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrConflict         = errors.New("conflict")
+	ErrUnauthenticated  = errors.New("unauthenticated")
+	ErrInvalidArgument  = errors.New("invalid argument")
+)
+
+// ErrorReasonInsufficientScope is the google.rpc.ErrorInfo reason on a
+// PermissionDenied error caused by a token that lacks a scope. The scope is
+// in the "scope" metadata entry.
+const ErrorReasonInsufficientScope = "INSUFFICIENT_SCOPE"
+
+// Error is a failed RPC. It carries the gRPC status, so status.Code and
+// status.FromError keep working, and matches the sentinel error for its
+// code:
+//
+//	codes.NotFound         ErrNotFound
+//	codes.AlreadyExists    ErrAlreadyExists and ErrConflict
+//	codes.PermissionDenied ErrPermissionDenied
+//	codes.Aborted          ErrConflict
+//	codes.Unauthenticated  ErrUnauthenticated
+//	codes.InvalidArgument  ErrInvalidArgument
+//
+// ErrConflict matches every code the API returns as HTTP 409 Conflict, such
+// as a RegisterAgent call with a different ClusterUid. The REST transport
+// cannot tell the two apart when a 409 carries no status body.
+type Error struct {
+	// Code is the gRPC status code.
+	Code codes.Code
+	// Message is the server's error message.
+	Message string
+	// MissingScope is the scope the token lacked, for PermissionDenied
+	// errors that report one.
+	MissingScope string
+	// FieldViolations lists the invalid request fields, for
+	// InvalidArgument errors that report them.
+	FieldViolations []*errdetails.BadRequest_FieldViolation
+
+	status *status.Status
+}
+
+// Error returns the same text as the underlying gRPC status error.
+func (e *Error) Error() string {
+	return e.status.Err().Error()
+}
+
+// GRPCStatus returns the underlying gRPC status, including its details.
+func (e *Error) GRPCStatus() *status.Status {
+	return e.status
+}
+
+// Is reports whether target is the sentinel error for e's code.
+func (e *Error) Is(target error) bool {
+	switch e.Code {
+	case codes.NotFound:
+		return target == ErrNotFound
+	case codes.AlreadyExists:
+		return target == ErrAlreadyExists || target == ErrConflict
+	case codes.PermissionDenied:
+		return target == ErrPermissionDenied
+	case codes.Aborted:
+		return target == ErrConflict
+	case codes.Unauthenticated:
+		return target == ErrUnauthenticated
+	case codes.InvalidArgument:
+		return target == ErrInvalidArgument
+	}
+	return false
+}
+
+// wrapError converts a gRPC status error into an *Error. Other errors,
+// including io.EOF at the end of a stream, are returned unchanged.
+func wrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+	st, ok := status.FromError(err)
+	if !ok || st.Code() == codes.OK {
+		return err
+	}
+	e = &Error{Code: st.Code(), Message: st.Message(), status: st}
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			if d.GetReason() == ErrorReasonInsufficientScope {
+				e.MissingScope = d.GetMetadata()["scope"]
+			}
+		case *errdetails.BadRequest:
+			e.FieldViolations = append(e.FieldViolations, d.GetFieldViolations()...)
+		}
+	}
+	return e
+}
+
+// errorUnaryInterceptor wraps errors from unary gRPC calls.
+func errorUnaryInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return wrapError(invoker(ctx, method, req, reply, cc, opts...))
+}
+
+// errorStreamInterceptor wraps errors from streaming gRPC calls.
+func errorStreamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	s, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return &errorStream{ClientStream: s}, nil
+}
+
+type errorStream struct {
+	grpc.ClientStream
+}
+
+func (s *errorStream) SendMsg(m any) error {
+	return wrapError(s.ClientStream.SendMsg(m))
+}
+
+func (s *errorStream) RecvMsg(m any) error {
+	return wrapError(s.ClientStream.RecvMsg(m))
+}
+
+// errorConn wraps errors from a grpc.ClientConnInterface, for the REST
+// transport.
+type errorConn struct {
+	grpc.ClientConnInterface
+}
+
+func (c *errorConn) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	return wrapError(c.ClientConnInterface.Invoke(ctx, method, args, reply, opts...))
+}
+
+func (c *errorConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	s, err := c.ClientConnInterface.NewStream(ctx, desc, method, opts...)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return &errorStream{ClientStream: s}, nil
+}
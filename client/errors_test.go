@@ -0,0 +1,173 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	agentv1 "go.admiral.io/sdk/proto/agent/v1"
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+)
+
+func withDetails(t *testing.T, st *status.Status, details ...*errdetails.ErrorInfo) error {
+	t.Helper()
+	for _, d := range details {
+		var err error
+		if st, err = st.WithDetails(d); err != nil {
+			t.Fatalf("WithDetails() error = %v", err)
+		}
+	}
+	return st.Err()
+}
+
+func TestWrapError(t *testing.T) {
+	tests := []struct {
+		code codes.Code
+		want []error
+	}{
+		{codes.NotFound, []error{ErrNotFound}},
+		{codes.AlreadyExists, []error{ErrAlreadyExists, ErrConflict}},
+		{codes.PermissionDenied, []error{ErrPermissionDenied}},
+		{codes.Aborted, []error{ErrConflict}},
+		{codes.Unauthenticated, []error{ErrUnauthenticated}},
+		{codes.InvalidArgument, []error{ErrInvalidArgument}},
+	}
+	sentinels := []error{ErrNotFound, ErrAlreadyExists, ErrPermissionDenied, ErrConflict, ErrUnauthenticated, ErrInvalidArgument}
+	for _, tt := range tests {
+		t.Run(tt.code.String(), func(t *testing.T) {
+			err := wrapError(status.Error(tt.code, "boom"))
+			for _, s := range sentinels {
+				if got := errors.Is(err, s); got != slices.Contains(tt.want, s) {
+					t.Errorf("errors.Is(%v, %v) = %v", err, s, got)
+				}
+			}
+			if status.Code(err) != tt.code || err.Error() != status.Error(tt.code, "boom").Error() {
+				t.Errorf("wrapped error = %v, want the original status", err)
+			}
+		})
+	}
+
+	if err := wrapError(status.Error(codes.Unavailable, "down")); errors.Is(err, ErrNotFound) || status.Code(err) != codes.Unavailable {
+		t.Errorf("wrapError(Unavailable) = %v", err)
+	}
+	if err := wrapError(io.EOF); err != io.EOF {
+		t.Errorf("wrapError(io.EOF) = %v, want io.EOF", err)
+	}
+	if err := wrapError(nil); err != nil {
+		t.Errorf("wrapError(nil) = %v", err)
+	}
+}
+
+func TestWrapError_Details(t *testing.T) {
+	st, err := status.New(codes.InvalidArgument, "invalid request").WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: "display_name", Description: "must not be empty"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("WithDetails() error = %v", err)
+	}
+	var e *Error
+	if !errors.As(wrapError(st.Err()), &e) {
+		t.Fatal("errors.As() = false, want *Error")
+	}
+	if len(e.FieldViolations) != 1 || e.FieldViolations[0].GetField() != "display_name" {
+		t.Errorf("FieldViolations = %v", e.FieldViolations)
+	}
+
+	denied := withDetails(t, status.New(codes.PermissionDenied, "missing scope"), &errdetails.ErrorInfo{
+		Reason:   ErrorReasonInsufficientScope,
+		Metadata: map[string]string{"scope": "clusters:write"},
+	})
+	if !errors.As(wrapError(denied), &e) || e.MissingScope != "clusters:write" {
+		t.Errorf("MissingScope = %q, want clusters:write", e.MissingScope)
+	}
+}
+
+// deniedClusterServer rejects every GetCluster call for a missing scope.
+type deniedClusterServer struct {
+	clusterv1.UnimplementedClusterAPIServer
+	err error
+}
+
+func (s *deniedClusterServer) GetCluster(context.Context, *clusterv1.GetClusterRequest) (*clusterv1.GetClusterResponse, error) {
+	return nil, s.err
+}
+
+func TestError_Transports(t *testing.T) {
+	srv := &deniedClusterServer{err: withDetails(t, status.New(codes.PermissionDenied, "token lacks clusters:read"), &errdetails.ErrorInfo{
+		Reason:   ErrorReasonInsufficientScope,
+		Metadata: map[string]string{"scope": "clusters:read"},
+	})}
+
+	lis := bufconn.Listen(1024 * 1024)
+	gs := grpc.NewServer()
+	clusterv1.RegisterClusterAPIServer(gs, srv)
+	go func() { _ = gs.Serve(lis) }()
+	t.Cleanup(gs.Stop)
+
+	gw := runtime.NewServeMux()
+	if err := clusterv1.RegisterClusterAPIHandlerServer(context.Background(), gw, srv); err != nil {
+		t.Fatalf("RegisterClusterAPIHandlerServer() error = %v", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/api/", http.StripPrefix("/api", gw))
+	httpSrv := httptest.NewServer(mux)
+	t.Cleanup(httpSrv.Close)
+
+	configs := map[string]Config{
+		"grpc": {
+			HostPort: "bufconn:0",
+			ConnectionOptions: ConnectionOptions{
+				DialOptions: []grpc.DialOption{grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+					return lis.DialContext(ctx)
+				})},
+			},
+		},
+		"rest": {
+			HostPort:  strings.TrimPrefix(httpSrv.URL, "http://"),
+			Transport: TransportREST,
+		},
+	}
+	for name, cfg := range configs {
+		t.Run(name, func(t *testing.T) {
+			cfg.AuthToken = "this-is-a-valid-opaque-token-12345"
+			cfg.ConnectionOptions.Insecure = true
+			c, err := New(context.Background(), cfg)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			t.Cleanup(func() { _ = c.Close() })
+
+			_, err = c.Cluster().GetCluster(context.Background(), &clusterv1.GetClusterRequest{ClusterId: "c1"})
+			var e *Error
+			if !errors.Is(err, ErrPermissionDenied) || !errors.As(err, &e) {
+				t.Fatalf("GetCluster() error = %v, want ErrPermissionDenied", err)
+			}
+			if e.MissingScope != "clusters:read" || e.Message != "token lacks clusters:read" {
+				t.Errorf("Error = %+v", e)
+			}
+		})
+	}
+
+	t.Run("connect", func(t *testing.T) {
+		c := newTestConnectClient(t, &testAgentHandler{}, EncodingProto)
+		_, err := c.Agent().GetAgent(context.Background(), &agentv1.GetAgentRequest{AgentId: "missing"})
+		if !errors.Is(err, ErrNotFound) || status.Code(err) != codes.NotFound {
+			t.Errorf("GetAgent() error = %v, want ErrNotFound", err)
+		}
+	})
+}
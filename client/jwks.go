@@ -0,0 +1,453 @@
+package client
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Signature verification errors. Expired tokens report ErrTokenExpired and
+// malformed tokens ErrInvalidTokenFormat or ErrInvalidClaims.
+var (
+	ErrInvalidSignature     = errors.New("invalid token signature")
+	ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+	ErrUnknownSigningKey    = errors.New("unknown signing key")
+	ErrInvalidIssuer        = errors.New("invalid token issuer")
+	ErrInvalidAudience      = errors.New("invalid token audience")
+	ErrTokenNotYetValid     = errors.New("token is not yet valid")
+	ErrJWKSUnavailable      = errors.New("JWKS unavailable")
+)
+
+// Supported JWS signing algorithms.
+const (
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+	AlgEdDSA = "EdDSA"
+)
+
+// VerifierConfig configures a Verifier.
+type VerifierConfig struct {
+	// JWKSURL is the URL of the issuer's JSON Web Key Set. Required.
+	JWKSURL string
+	// Issuer, when set, must equal the token's iss claim.
+	Issuer string
+	// Audience, when set, must be one of the token's aud values.
+	Audience string
+	// Algorithms restricts the accepted signing algorithms. Defaults to
+	// RS256, ES256 and EdDSA.
+	Algorithms []string
+	// HTTPClient fetches the JWKS. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+	// CacheTTL is how long a fetched key set is trusted before it is
+	// fetched again. Defaults to DefaultJWKSCacheTTL.
+	CacheTTL time.Duration
+	// MinRefreshInterval limits how often the key set is fetched: an
+	// unknown key ID triggers an early refetch, and a failed fetch is
+	// retried, at most once per interval. Defaults to
+	// DefaultJWKSMinRefreshInterval.
+	MinRefreshInterval time.Duration
+	// Leeway is the clock skew tolerated for exp and nbf.
+	Leeway time.Duration
+}
+
+// VerifiedToken holds the claims of a token whose signature, issuer,
+// audience and validity period have been checked.
+type VerifiedToken struct {
+	*JWTClaims
+	// Audiences lists every aud value; JWTClaims.Audience holds the first.
+	Audiences []string
+	// Algorithm and KeyID identify the key that signed the token.
+	Algorithm string
+	KeyID     string
+
+	payload []byte
+}
+
+// UnmarshalClaims decodes the full claim set into v, for claims beyond the
+// registered ones in JWTClaims.
+func (t *VerifiedToken) UnmarshalClaims(v any) error {
+	return json.Unmarshal(t.payload, v)
+}
+
+// Verifier checks JWT signatures against keys from a JWKS endpoint. Keys are
+// cached for CacheTTL and refetched early when a token names an unknown key
+// ID, so issuer key rotation is picked up without a restart. A Verifier is
+// safe for concurrent use.
+type Verifier struct {
+	cfg VerifierConfig
+
+	mu        sync.Mutex
+	keys      []jwk
+	fetchedAt time.Time
+	fetch     *jwksFetch // latest fetch attempt, possibly in flight
+}
+
+// jwksFetch is one fetch of the key set, shared by every caller that needs
+// it while it runs. done is closed when err is set.
+type jwksFetch struct {
+	at   time.Time
+	done chan struct{}
+	err  error
+}
+
+// NewVerifier returns a Verifier. Keys are fetched on first use.
+func NewVerifier(cfg VerifierConfig) (*Verifier, error) {
+	if cfg.JWKSURL == "" {
+		return nil, errors.New("JWKSURL is required")
+	}
+	if len(cfg.Algorithms) == 0 {
+		cfg.Algorithms = []string{AlgRS256, AlgES256, AlgEdDSA}
+	}
+	for _, alg := range cfg.Algorithms {
+		if alg != AlgRS256 && alg != AlgES256 && alg != AlgEdDSA {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, alg)
+		}
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	if cfg.CacheTTL == 0 {
+		cfg.CacheTTL = DefaultJWKSCacheTTL
+	}
+	if cfg.MinRefreshInterval == 0 {
+		cfg.MinRefreshInterval = DefaultJWKSMinRefreshInterval
+	}
+	return &Verifier{cfg: cfg}, nil
+}
+
+// Verify checks the token's signature and claims and returns them.
+func (v *Verifier) Verify(ctx context.Context, token string) (*VerifiedToken, error) {
+	token = strings.TrimPrefix(token, "Bearer ")
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: expected 3 parts separated by '.', got %d", ErrInvalidTokenFormat, len(parts))
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse header: %v", ErrInvalidTokenFormat, err)
+	}
+	if !slices.Contains(v.cfg.Algorithms, header.Alg) {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, header.Alg)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decode signature: %v", ErrInvalidTokenFormat, err)
+	}
+
+	keys, err := v.keysFor(ctx, header.Kid, header.Alg)
+	if err != nil {
+		return nil, err
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+	var verified bool
+	for _, k := range keys {
+		if verifySignature(header.Alg, k.key, signed, sig) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, ErrInvalidSignature
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decode payload: %v", ErrInvalidTokenFormat, err)
+	}
+	var claims struct {
+		JWTClaims
+		Audience audienceClaim `json:"aud,omitempty"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse claims: %v", ErrInvalidClaims, err)
+	}
+	if len(claims.Audience) > 0 {
+		claims.JWTClaims.Audience = claims.Audience[0]
+	}
+	if err := v.checkClaims(&claims.JWTClaims, claims.Audience); err != nil {
+		return nil, err
+	}
+
+	return &VerifiedToken{
+		JWTClaims: &claims.JWTClaims,
+		Audiences: claims.Audience,
+		Algorithm: header.Alg,
+		KeyID:     header.Kid,
+		payload:   payload,
+	}, nil
+}
+
+func (v *Verifier) checkClaims(c *JWTClaims, aud []string) error {
+	now := time.Now()
+	if c.ExpirationTime != 0 && !now.Before(time.Unix(c.ExpirationTime, 0).Add(v.cfg.Leeway)) {
+		return fmt.Errorf("%w: token expired at %v", ErrTokenExpired, time.Unix(c.ExpirationTime, 0))
+	}
+	if c.NotBefore != 0 && now.Add(v.cfg.Leeway).Before(time.Unix(c.NotBefore, 0)) {
+		return fmt.Errorf("%w: token valid from %v", ErrTokenNotYetValid, time.Unix(c.NotBefore, 0))
+	}
+	if v.cfg.Issuer != "" && c.Issuer != v.cfg.Issuer {
+		return fmt.Errorf("%w: got %q, want %q", ErrInvalidIssuer, c.Issuer, v.cfg.Issuer)
+	}
+	if v.cfg.Audience != "" && !slices.Contains(aud, v.cfg.Audience) {
+		return fmt.Errorf("%w: %q not in %q", ErrInvalidAudience, v.cfg.Audience, aud)
+	}
+	return nil
+}
+
+// keysFor returns the cached keys usable for kid and alg, refetching the key
+// set when it is stale or when kid is unknown.
+func (v *Verifier) keysFor(ctx context.Context, kid, alg string) ([]jwk, error) {
+	keys, fetchedAt := v.cached()
+	if keys == nil || time.Since(fetchedAt) >= v.cfg.CacheTTL {
+		// A stale key set is still better than none if the endpoint is down.
+		if err := v.refresh(ctx); err != nil && keys == nil {
+			return nil, err
+		}
+		keys, fetchedAt = v.cached()
+	}
+	matched := matchKeys(keys, kid, alg)
+	if len(matched) == 0 && kid != "" && time.Since(fetchedAt) >= v.cfg.MinRefreshInterval {
+		if err := v.refresh(ctx); err != nil {
+			return nil, err
+		}
+		keys, _ = v.cached()
+		matched = matchKeys(keys, kid, alg)
+	}
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("%w: kid %q for %s", ErrUnknownSigningKey, kid, alg)
+	}
+	return matched, nil
+}
+
+func (v *Verifier) cached() ([]jwk, time.Time) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.keys, v.fetchedAt
+}
+
+// refresh fetches the key set without holding mu, so verifications with
+// cached keys are not held up by a slow endpoint. Concurrent callers share
+// one fetch, and within MinRefreshInterval of a failed attempt the caller
+// gets that attempt's error instead of a new fetch.
+func (v *Verifier) refresh(ctx context.Context) error {
+	v.mu.Lock()
+	if f := v.fetch; f != nil {
+		select {
+		case <-f.done:
+			if f.err != nil && time.Since(f.at) < v.cfg.MinRefreshInterval {
+				v.mu.Unlock()
+				return f.err
+			}
+		default:
+			v.mu.Unlock()
+			select {
+			case <-f.done:
+				return f.err
+			case <-ctx.Done():
+				return fmt.Errorf("%w: %v", ErrJWKSUnavailable, ctx.Err())
+			}
+		}
+	}
+	f := &jwksFetch{at: time.Now(), done: make(chan struct{})}
+	v.fetch = f
+	v.mu.Unlock()
+
+	keys, err := v.fetchKeys(ctx)
+
+	v.mu.Lock()
+	f.err = err
+	switch {
+	case err == nil:
+		v.keys, v.fetchedAt = keys, f.at
+	case ctx.Err() != nil:
+		// The caller gave up; that says nothing about the endpoint, so
+		// let the next caller try again straight away.
+		f.at = time.Time{}
+	}
+	v.mu.Unlock()
+	close(f.done)
+	return err
+}
+
+// fetchKeys downloads and parses the key set.
+func (v *Verifier) fetchKeys(ctx context.Context) ([]jwk, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.cfg.JWKSURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrJWKSUnavailable, err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", ClientUserAgent())
+	resp, err := v.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrJWKSUnavailable, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s returned %s", ErrJWKSUnavailable, v.cfg.JWKSURL, resp.Status)
+	}
+
+	var set struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&set); err != nil {
+		return nil, fmt.Errorf("%w: failed to decode key set: %v", ErrJWKSUnavailable, err)
+	}
+	keys := make([]jwk, 0, len(set.Keys))
+	for _, raw := range set.Keys {
+		k, err := parseJWK(raw)
+		if err != nil {
+			// Skip keys we cannot use rather than rejecting the whole set.
+			continue
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// jwk is a parsed signing key from a JWKS document.
+type jwk struct {
+	kid string
+	alg string // algorithm the key is usable with
+	key crypto.PublicKey
+}
+
+func parseJWK(raw json.RawMessage) (jwk, error) {
+	var k struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		Alg string `json:"alg"`
+		Crv string `json:"crv"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+	}
+	if err := json.Unmarshal(raw, &k); err != nil {
+		return jwk{}, err
+	}
+	if k.Use != "" && k.Use != "sig" {
+		return jwk{}, fmt.Errorf("key %q is not a signing key", k.Kid)
+	}
+
+	var out jwk
+	switch {
+	case k.Kty == "RSA":
+		n, err1 := decodeBigInt(k.N)
+		e, err2 := decodeBigInt(k.E)
+		if err := errors.Join(err1, err2); err != nil {
+			return jwk{}, err
+		}
+		if !e.IsInt64() {
+			return jwk{}, errors.New("RSA exponent too large")
+		}
+		out = jwk{alg: AlgRS256, key: &rsa.PublicKey{N: n, E: int(e.Int64())}}
+	case k.Kty == "EC" && k.Crv == "P-256":
+		x, err1 := decodeBigInt(k.X)
+		y, err2 := decodeBigInt(k.Y)
+		if err := errors.Join(err1, err2); err != nil {
+			return jwk{}, err
+		}
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		if !pub.Curve.IsOnCurve(x, y) {
+			return jwk{}, errors.New("EC point is not on P-256")
+		}
+		out = jwk{alg: AlgES256, key: pub}
+	case k.Kty == "OKP" && k.Crv == "Ed25519":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return jwk{}, errors.New("invalid Ed25519 key")
+		}
+		out = jwk{alg: AlgEdDSA, key: ed25519.PublicKey(x)}
+	default:
+		return jwk{}, fmt.Errorf("unsupported key type %s %s", k.Kty, k.Crv)
+	}
+	if k.Alg != "" && k.Alg != out.alg {
+		return jwk{}, fmt.Errorf("key %q declares alg %s for a %s key", k.Kid, k.Alg, out.alg)
+	}
+	out.kid = k.Kid
+	return out, nil
+}
+
+// matchKeys returns the keys usable with alg, restricted to kid when set.
+func matchKeys(keys []jwk, kid, alg string) []jwk {
+	var out []jwk
+	for _, k := range keys {
+		if k.alg == alg && (kid == "" || k.kid == kid) {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+func verifySignature(alg string, key crypto.PublicKey, signed, sig []byte) bool {
+	switch alg {
+	case AlgRS256:
+		digest := sha256.Sum256(signed)
+		return rsa.VerifyPKCS1v15(key.(*rsa.PublicKey), crypto.SHA256, digest[:], sig) == nil
+	case AlgES256:
+		if len(sig) != 64 {
+			return false
+		}
+		digest := sha256.Sum256(signed)
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		return ecdsa.Verify(key.(*ecdsa.PublicKey), digest[:], r, s)
+	case AlgEdDSA:
+		return ed25519.Verify(key.(ed25519.PublicKey), signed, sig)
+	default:
+		return false
+	}
+}
+
+// audienceClaim accepts aud as either a string or an array of strings.
+type audienceClaim []string
+
+func (a *audienceClaim) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audienceClaim{single}
+		return nil
+	}
+	var multi []string
+	if err := json.Unmarshal(data, &multi); err != nil {
+		return fmt.Errorf("aud must be a string or an array of strings")
+	}
+	*a = multi
+	return nil
+}
+
+func decodeSegment(seg string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(seg, "="))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid base64url integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,290 @@
+package client
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testJWKS serves a mutable key set and signs tokens with its private keys.
+type testJWKS struct {
+	t       *testing.T
+	mu      sync.Mutex
+	keys    []map[string]string
+	signers map[string]crypto.Signer
+	fetches int
+	down    bool
+	delay   time.Duration
+	srv     *httptest.Server
+}
+
+func newTestJWKS(t *testing.T) *testJWKS {
+	j := &testJWKS{t: t, signers: map[string]crypto.Signer{}}
+	j.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		j.fetches++
+		time.Sleep(j.delay)
+		if j.down {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": j.keys})
+	}))
+	t.Cleanup(j.srv.Close)
+	return j
+}
+
+func b64(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+// addKey generates a key for alg and publishes it under kid.
+func (j *testJWKS) addKey(kid, alg string) {
+	j.t.Helper()
+	var pub map[string]string
+	var signer crypto.Signer
+	switch alg {
+	case AlgRS256:
+		k, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			j.t.Fatal(err)
+		}
+		signer = k
+		pub = map[string]string{"kty": "RSA", "n": b64(k.N.Bytes()), "e": b64([]byte{1, 0, 1})}
+	case AlgES256:
+		k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			j.t.Fatal(err)
+		}
+		signer = k
+		pub = map[string]string{"kty": "EC", "crv": "P-256", "x": b64(k.X.FillBytes(make([]byte, 32))), "y": b64(k.Y.FillBytes(make([]byte, 32)))}
+	case AlgEdDSA:
+		p, k, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			j.t.Fatal(err)
+		}
+		signer = k
+		pub = map[string]string{"kty": "OKP", "crv": "Ed25519", "x": b64(p)}
+	}
+	pub["kid"] = kid
+	pub["use"] = "sig"
+	pub["alg"] = alg
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.keys = append(j.keys, pub)
+	j.signers[kid] = signer
+}
+
+func (j *testJWKS) sign(kid, alg string, claims map[string]any) string {
+	j.t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := b64(header) + "." + b64(payload)
+
+	j.mu.Lock()
+	signer := j.signers[kid]
+	j.mu.Unlock()
+
+	var sig []byte
+	var err error
+	switch alg {
+	case AlgRS256:
+		digest := sha256.Sum256([]byte(signed))
+		sig, err = rsa.SignPKCS1v15(rand.Reader, signer.(*rsa.PrivateKey), crypto.SHA256, digest[:])
+	case AlgES256:
+		digest := sha256.Sum256([]byte(signed))
+		r, s, serr := ecdsa.Sign(rand.Reader, signer.(*ecdsa.PrivateKey), digest[:])
+		err = serr
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case AlgEdDSA:
+		sig = ed25519.Sign(signer.(ed25519.PrivateKey), []byte(signed))
+	}
+	if err != nil {
+		j.t.Fatal(err)
+	}
+	return signed + "." + b64(sig)
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"iss":    "https://auth.admiral.io",
+		"aud":    []string{"admission", "other"},
+		"sub":    "user-1",
+		"exp":    time.Now().Add(time.Hour).Unix(),
+		"tenant": "t-1",
+	}
+}
+
+func newTestVerifier(t *testing.T, j *testJWKS) *Verifier {
+	t.Helper()
+	v, err := NewVerifier(VerifierConfig{
+		JWKSURL:            j.srv.URL,
+		Issuer:             "https://auth.admiral.io",
+		Audience:           "admission",
+		MinRefreshInterval: time.Nanosecond,
+	})
+	if err != nil {
+		t.Fatalf("NewVerifier() error = %v", err)
+	}
+	return v
+}
+
+func TestVerifier_Algorithms(t *testing.T) {
+	j := newTestJWKS(t)
+	for _, alg := range []string{AlgRS256, AlgES256, AlgEdDSA} {
+		j.addKey("key-"+alg, alg)
+	}
+	v := newTestVerifier(t, j)
+
+	for _, alg := range []string{AlgRS256, AlgES256, AlgEdDSA} {
+		t.Run(alg, func(t *testing.T) {
+			tok, err := v.Verify(context.Background(), j.sign("key-"+alg, alg, validClaims()))
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if tok.Subject != "user-1" || tok.Audience != "admission" || tok.Algorithm != alg {
+				t.Errorf("Verify() = %+v", tok)
+			}
+			var extra struct {
+				Tenant string `json:"tenant"`
+			}
+			if err := tok.UnmarshalClaims(&extra); err != nil || extra.Tenant != "t-1" {
+				t.Errorf("UnmarshalClaims() = %q, %v, want t-1", extra.Tenant, err)
+			}
+		})
+	}
+	if j.fetches != 1 {
+		t.Errorf("JWKS fetches = %d, want 1 (cached)", j.fetches)
+	}
+}
+
+func TestVerifier_Errors(t *testing.T) {
+	j := newTestJWKS(t)
+	j.addKey("k1", AlgES256)
+	v := newTestVerifier(t, j)
+
+	with := func(key string, value any) map[string]any {
+		c := validClaims()
+		c[key] = value
+		return c
+	}
+	tampered := j.sign("k1", AlgES256, validClaims())
+	tampered = tampered[:len(tampered)-4] + "AAAA"
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"bad signature", tampered, ErrInvalidSignature},
+		{"expired", j.sign("k1", AlgES256, with("exp", time.Now().Add(-time.Minute).Unix())), ErrTokenExpired},
+		{"not yet valid", j.sign("k1", AlgES256, with("nbf", time.Now().Add(time.Hour).Unix())), ErrTokenNotYetValid},
+		{"wrong issuer", j.sign("k1", AlgES256, with("iss", "https://evil.example")), ErrInvalidIssuer},
+		{"wrong audience", j.sign("k1", AlgES256, with("aud", "someone-else")), ErrInvalidAudience},
+		{"unknown kid", "eyJhbGciOiJFUzI1NiIsImtpZCI6Im5vcGUifQ.e30.AAAA", ErrUnknownSigningKey},
+		{"alg none", "eyJhbGciOiJub25lIn0.e30.", ErrUnsupportedAlgorithm},
+		{"malformed", "not-a-jwt", ErrInvalidTokenFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.Verify(context.Background(), tt.token)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifier_KeyRotation(t *testing.T) {
+	j := newTestJWKS(t)
+	j.addKey("old", AlgRS256)
+	v := newTestVerifier(t, j)
+
+	if _, err := v.Verify(context.Background(), j.sign("old", AlgRS256, validClaims())); err != nil {
+		t.Fatalf("Verify(old) error = %v", err)
+	}
+	j.addKey("new", AlgRS256)
+	if _, err := v.Verify(context.Background(), j.sign("new", AlgRS256, validClaims())); err != nil {
+		t.Fatalf("Verify(new) error = %v, want refetch on unknown kid", err)
+	}
+	if j.fetches != 2 {
+		t.Errorf("JWKS fetches = %d, want 2", j.fetches)
+	}
+}
+
+func TestVerifier_JWKSUnavailable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	v, err := NewVerifier(VerifierConfig{JWKSURL: srv.URL})
+	if err != nil {
+		t.Fatalf("NewVerifier() error = %v", err)
+	}
+	_, err = v.Verify(context.Background(), "eyJhbGciOiJSUzI1NiJ9.e30.AAAA")
+	if !errors.Is(err, ErrJWKSUnavailable) {
+		t.Errorf("Verify() error = %v, want ErrJWKSUnavailable", err)
+	}
+}
+
+func TestVerifier_StaleKeysDuringOutage(t *testing.T) {
+	j := newTestJWKS(t)
+	j.addKey("k", AlgES256)
+	v, err := NewVerifier(VerifierConfig{JWKSURL: j.srv.URL, CacheTTL: time.Nanosecond, MinRefreshInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("NewVerifier() error = %v", err)
+	}
+	token := j.sign("k", AlgES256, validClaims())
+	if _, err := v.Verify(context.Background(), token); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	j.mu.Lock()
+	j.down = true
+	j.mu.Unlock()
+	for range 5 {
+		if _, err := v.Verify(context.Background(), token); err != nil {
+			t.Fatalf("Verify() with stale keys error = %v", err)
+		}
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.fetches != 2 {
+		t.Errorf("JWKS fetches = %d, want 2 (failed refresh retried at most once per MinRefreshInterval)", j.fetches)
+	}
+}
+
+func TestVerifier_ConcurrentFetchesShared(t *testing.T) {
+	j := newTestJWKS(t)
+	j.addKey("k", AlgEdDSA)
+	j.delay = 50 * time.Millisecond
+	v := newTestVerifier(t, j)
+	token := j.sign("k", AlgEdDSA, validClaims())
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			if _, err := v.Verify(context.Background(), token); err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+		})
+	}
+	wg.Wait()
+	if j.fetches != 1 {
+		t.Errorf("JWKS fetches = %d, want 1", j.fetches)
+	}
+}
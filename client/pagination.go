@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"iter"
+
+	accesstokenv1 "go.admiral.io/sdk/proto/accesstoken/v1"
+	agentv1 "go.admiral.io/sdk/proto/agent/v1"
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+	runnerv1 "go.admiral.io/sdk/proto/runner/v1"
+	serviceaccountv1 "go.admiral.io/sdk/proto/serviceaccount/v1"
+	userv1 "go.admiral.io/sdk/proto/user/v1"
+)
+
+// ListOption configures a List iterator.
+type ListOption func(*listOptions)
+
+type listOptions struct {
+	pageSize int32
+	filter   string
+}
+
+// WithPageSize sets the number of items requested per page. The server
+// default is used when unset.
+func WithPageSize(n int32) ListOption {
+	return func(o *listOptions) { o.pageSize = n }
+}
+
+// WithFilter sets the filter expression sent with every page request.
+func WithFilter(filter string) ListOption {
+	return func(o *listOptions) { o.filter = filter }
+}
+
+// pageFunc fetches one page and returns its items and the next page token.
+type pageFunc[T any] func(ctx context.Context, pageSize int32, pageToken, filter string) ([]T, string, error)
+
+// paginate turns a pageFunc into an iterator that follows NextPageToken
+// until it is empty. On error, context cancellation or a server returning
+// a page token it returned before, which would loop forever, the iterator
+// yields a single zero item with the error and stops; items yielded before
+// that are unaffected.
+func paginate[T any](ctx context.Context, opts []ListOption, fetch pageFunc[T]) iter.Seq2[T, error] {
+	var o listOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return func(yield func(T, error) bool) {
+		var zero T
+		pageToken := ""
+		seen := map[string]struct{}{}
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+			items, next, err := fetch(ctx, o.pageSize, pageToken, o.filter)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if next == "" {
+				return
+			}
+			seen[pageToken] = struct{}{}
+			if _, ok := seen[next]; ok {
+				yield(zero, fmt.Errorf("server returned page token %q again", next))
+				return
+			}
+			pageToken = next
+		}
+	}
+}
+
+// CollectAll drains seq into a slice. If the iterator reports an error, the
+// items collected before the error are returned along with it.
+func CollectAll[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var items []T
+	for item, err := range seq {
+		if err != nil {
+			return items, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// ListClusters iterates over all clusters visible to the caller.
+//
+//	for cluster, err := range client.ListClusters(ctx, c.Cluster(), client.WithPageSize(100)) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    fmt.Println(cluster.GetDisplayName())
+//	}
+func ListClusters(ctx context.Context, c clusterv1.ClusterAPIClient, opts ...ListOption) iter.Seq2[*clusterv1.Cluster, error] {
+	return paginate(ctx, opts, func(ctx context.Context, pageSize int32, pageToken, filter string) ([]*clusterv1.Cluster, string, error) {
+		resp, err := c.ListClusters(ctx, &clusterv1.ListClustersRequest{PageSize: pageSize, PageToken: pageToken, Filter: filter})
+		return resp.GetClusters(), resp.GetNextPageToken(), err
+	})
+}
+
+// ListWorkloads iterates over all workloads reported for a cluster.
+func ListWorkloads(ctx context.Context, c clusterv1.ClusterAPIClient, clusterID string, opts ...ListOption) iter.Seq2[*clusterv1.Workload, error] {
+	return paginate(ctx, opts, func(ctx context.Context, pageSize int32, pageToken, filter string) ([]*clusterv1.Workload, string, error) {
+		resp, err := c.ListWorkloads(ctx, &clusterv1.ListWorkloadsRequest{ClusterId: clusterID, PageSize: pageSize, PageToken: pageToken, Filter: filter})
+		return resp.GetWorkloads(), resp.GetNextPageToken(), err
+	})
+}
+
+// ListClusterTokens iterates over all access tokens issued for a cluster.
+func ListClusterTokens(ctx context.Context, c clusterv1.ClusterAPIClient, clusterID string, opts ...ListOption) iter.Seq2[*accesstokenv1.AccessToken, error] {
+	return paginate(ctx, opts, func(ctx context.Context, pageSize int32, pageToken, filter string) ([]*accesstokenv1.AccessToken, string, error) {
+		resp, err := c.ListClusterTokens(ctx, &clusterv1.ListClusterTokensRequest{ClusterId: clusterID, PageSize: pageSize, PageToken: pageToken, Filter: filter})
+		return resp.GetAccessTokens(), resp.GetNextPageToken(), err
+	})
+}
+
+// ListRunners iterates over all runners visible to the caller.
+func ListRunners(ctx context.Context, c runnerv1.RunnerAPIClient, opts ...ListOption) iter.Seq2[*runnerv1.Runner, error] {
+	return paginate(ctx, opts, func(ctx context.Context, pageSize int32, pageToken, filter string) ([]*runnerv1.Runner, string, error) {
+		resp, err := c.ListRunners(ctx, &runnerv1.ListRunnersRequest{PageSize: pageSize, PageToken: pageToken, Filter: filter})
+		return resp.GetRunners(), resp.GetNextPageToken(), err
+	})
+}
+
+// ListRunnerTokens iterates over all access tokens issued for a runner.
+func ListRunnerTokens(ctx context.Context, c runnerv1.RunnerAPIClient, runnerID string, opts ...ListOption) iter.Seq2[*accesstokenv1.AccessToken, error] {
+	return paginate(ctx, opts, func(ctx context.Context, pageSize int32, pageToken, filter string) ([]*accesstokenv1.AccessToken, string, error) {
+		resp, err := c.ListRunnerTokens(ctx, &runnerv1.ListRunnerTokensRequest{RunnerId: runnerID, PageSize: pageSize, PageToken: pageToken, Filter: filter})
+		return resp.GetAccessTokens(), resp.GetNextPageToken(), err
+	})
+}
+
+// ListAgents iterates over all agents visible to the caller.
+func ListAgents(ctx context.Context, c agentv1.AgentAPIClient, opts ...ListOption) iter.Seq2[*agentv1.Agent, error] {
+	return paginate(ctx, opts, func(ctx context.Context, pageSize int32, pageToken, filter string) ([]*agentv1.Agent, string, error) {
+		resp, err := c.ListAgents(ctx, &agentv1.ListAgentsRequest{PageSize: pageSize, PageToken: pageToken, Filter: filter})
+		return resp.GetAgents(), resp.GetNextPageToken(), err
+	})
+}
+
+// ListServiceAccounts iterates over all service accounts visible to the caller.
+func ListServiceAccounts(ctx context.Context, c serviceaccountv1.ServiceAccountAPIClient, opts ...ListOption) iter.Seq2[*serviceaccountv1.ServiceAccount, error] {
+	return paginate(ctx, opts, func(ctx context.Context, pageSize int32, pageToken, filter string) ([]*serviceaccountv1.ServiceAccount, string, error) {
+		resp, err := c.ListServiceAccounts(ctx, &serviceaccountv1.ListServiceAccountsRequest{PageSize: pageSize, PageToken: pageToken, Filter: filter})
+		return resp.GetServiceAccounts(), resp.GetNextPageToken(), err
+	})
+}
+
+// ListServiceAccountTokens iterates over all access tokens issued for a
+// service account.
+func ListServiceAccountTokens(ctx context.Context, c serviceaccountv1.ServiceAccountAPIClient, serviceAccountID string, opts ...ListOption) iter.Seq2[*accesstokenv1.AccessToken, error] {
+	return paginate(ctx, opts, func(ctx context.Context, pageSize int32, pageToken, filter string) ([]*accesstokenv1.AccessToken, string, error) {
+		resp, err := c.ListServiceAccountTokens(ctx, &serviceaccountv1.ListServiceAccountTokensRequest{ServiceAccountId: serviceAccountID, PageSize: pageSize, PageToken: pageToken, Filter: filter})
+		return resp.GetAccessTokens(), resp.GetNextPageToken(), err
+	})
+}
+
+// ListPersonalAccessTokens iterates over the caller's personal access tokens.
+func ListPersonalAccessTokens(ctx context.Context, c userv1.UserAPIClient, opts ...ListOption) iter.Seq2[*accesstokenv1.AccessToken, error] {
+	return paginate(ctx, opts, func(ctx context.Context, pageSize int32, pageToken, filter string) ([]*accesstokenv1.AccessToken, string, error) {
+		resp, err := c.ListPersonalAccessTokens(ctx, &userv1.ListPersonalAccessTokensRequest{PageSize: pageSize, PageToken: pageToken, Filter: filter})
+		return resp.GetAccessTokens(), resp.GetNextPageToken(), err
+	})
+}
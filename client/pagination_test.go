@@ -0,0 +1,155 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	runnerv1 "go.admiral.io/sdk/proto/runner/v1"
+)
+
+// fakeRunnerPager serves runners in fixed pages and optionally fails on a
+// given page index.
+type fakeRunnerPager struct {
+	runnerv1.RunnerAPIClient
+	pages    [][]string
+	failPage int
+	requests []*runnerv1.ListRunnersRequest
+}
+
+func (f *fakeRunnerPager) ListRunners(_ context.Context, in *runnerv1.ListRunnersRequest, _ ...grpc.CallOption) (*runnerv1.ListRunnersResponse, error) {
+	f.requests = append(f.requests, in)
+	page := len(f.requests) - 1
+	if page == f.failPage {
+		return nil, errors.New("boom")
+	}
+	resp := &runnerv1.ListRunnersResponse{}
+	for _, id := range f.pages[page] {
+		resp.Runners = append(resp.Runners, &runnerv1.Runner{Id: id})
+	}
+	if page < len(f.pages)-1 {
+		resp.NextPageToken = "page-" + string(rune('1'+page))
+	}
+	return resp, nil
+}
+
+func runnerIDs(runners []*runnerv1.Runner) []string {
+	ids := make([]string, len(runners))
+	for i, r := range runners {
+		ids[i] = r.GetId()
+	}
+	return ids
+}
+
+func TestListRunners_FollowsPageTokens(t *testing.T) {
+	f := &fakeRunnerPager{pages: [][]string{{"a", "b"}, {"c"}, {"d"}}, failPage: -1}
+
+	runners, err := CollectAll(ListRunners(context.Background(), f, WithPageSize(2), WithFilter(`kind = "KUBERNETES"`)))
+	if err != nil {
+		t.Fatalf("CollectAll() error = %v", err)
+	}
+	if got := runnerIDs(runners); len(got) != 4 || got[0] != "a" || got[3] != "d" {
+		t.Errorf("runners = %v, want [a b c d]", got)
+	}
+	if len(f.requests) != 3 {
+		t.Fatalf("requests = %d, want 3", len(f.requests))
+	}
+	for i, req := range f.requests {
+		if req.GetPageSize() != 2 || req.GetFilter() != `kind = "KUBERNETES"` {
+			t.Errorf("request %d = %v, want page size and filter set", i, req)
+		}
+	}
+	if f.requests[0].GetPageToken() != "" || f.requests[1].GetPageToken() != "page-1" {
+		t.Errorf("page tokens = %q, %q", f.requests[0].GetPageToken(), f.requests[1].GetPageToken())
+	}
+}
+
+func TestCollectAll_KeepsItemsBeforeError(t *testing.T) {
+	f := &fakeRunnerPager{pages: [][]string{{"a", "b"}, {"c"}}, failPage: 1}
+
+	runners, err := CollectAll(ListRunners(context.Background(), f))
+	if err == nil || err.Error() != "boom" {
+		t.Fatalf("CollectAll() error = %v, want boom", err)
+	}
+	if got := runnerIDs(runners); len(got) != 2 {
+		t.Errorf("runners = %v, want [a b]", got)
+	}
+}
+
+// cyclingRunnerPager returns one runner per page and, for each page token,
+// the next token in next, so the tokens can loop.
+type cyclingRunnerPager struct {
+	runnerv1.RunnerAPIClient
+	next     map[string]string
+	requests int
+}
+
+func (f *cyclingRunnerPager) ListRunners(_ context.Context, req *runnerv1.ListRunnersRequest, _ ...grpc.CallOption) (*runnerv1.ListRunnersResponse, error) {
+	f.requests++
+	return &runnerv1.ListRunnersResponse{Runners: []*runnerv1.Runner{{Id: req.GetPageToken()}}, NextPageToken: f.next[req.GetPageToken()]}, nil
+}
+
+func TestListRunners_RepeatedPageToken(t *testing.T) {
+	tests := []struct {
+		name string
+		next map[string]string
+		want int
+	}{
+		{"same token", map[string]string{"": "a", "a": "a"}, 2},
+		{"two-page cycle", map[string]string{"": "a", "a": "b", "b": "a"}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &cyclingRunnerPager{next: tt.next}
+			runners, err := CollectAll(ListRunners(context.Background(), f))
+			if err == nil || !strings.Contains(err.Error(), "page token") {
+				t.Fatalf("CollectAll() error = %v, want repeated page token error", err)
+			}
+			if len(runners) != tt.want || f.requests != tt.want {
+				t.Errorf("runners = %d, requests = %d, want %d and %d", len(runners), f.requests, tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func TestListRunners_StopsOnBreak(t *testing.T) {
+	f := &fakeRunnerPager{pages: [][]string{{"a", "b"}, {"c"}}, failPage: -1}
+
+	for runner, err := range ListRunners(context.Background(), f) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if runner.GetId() == "a" {
+			break
+		}
+	}
+	if len(f.requests) != 1 {
+		t.Errorf("requests = %d, want 1", len(f.requests))
+	}
+}
+
+func TestListRunners_ContextCancelled(t *testing.T) {
+	f := &fakeRunnerPager{pages: [][]string{{"a"}, {"b"}}, failPage: -1}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var ids []string
+	var gotErr error
+	for runner, err := range ListRunners(ctx, f) {
+		if err != nil {
+			gotErr = err
+			break
+		}
+		ids = append(ids, runner.GetId())
+		cancel()
+	}
+	if !errors.Is(gotErr, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", gotErr)
+	}
+	if len(ids) != 1 || len(f.requests) != 1 {
+		t.Errorf("ids = %v, requests = %d; want one item from one page", ids, len(f.requests))
+	}
+}
@@ -0,0 +1,259 @@
+package client
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"sigs.k8s.io/yaml"
+)
+
+// Environment variables read by LoadConfig. They override the values in the
+// config file.
+const (
+	// EnvConfig is the path of the config file.
+	EnvConfig = "ADMIRAL_CONFIG"
+	// EnvProfile names the profile to use.
+	EnvProfile = "ADMIRAL_PROFILE"
+	// EnvHost overrides the profile's host and endpoints.
+	EnvHost = "ADMIRAL_HOST"
+	// EnvToken overrides the profile's token, token file and token command.
+	EnvToken = "ADMIRAL_TOKEN"
+)
+
+// DefaultProfile is the profile used when none is named.
+const DefaultProfile = "default"
+
+// ErrProfileNotFound is returned by LoadConfig when the requested profile is
+// not in the config file.
+var ErrProfileNotFound = errors.New("profile not found")
+
+// ConfigFile is the contents of an Admiral config file: a set of named
+// profiles, one of which is current. The file is YAML, JSON or TOML, with
+// the same keys in each:
+//
+//	current-profile: prod
+//	profiles:
+//	  prod:
+//	    host: api.admiral.io:443
+//	    token-command: [op, read, "op://ci/admiral/token"]
+//	  local:
+//	    host: localhost:8080
+//	    token: your-token
+//	    insecure: true
+type ConfigFile struct {
+	// CurrentProfile is used when no profile is named. Defaults to
+	// DefaultProfile.
+	CurrentProfile string             `json:"current-profile,omitempty"`
+	Profiles       map[string]Profile `json:"profiles,omitempty"`
+}
+
+// Profile holds the settings for one Admiral endpoint. At most one of
+// Token, TokenFile and TokenCommand may be set.
+type Profile struct {
+	// Host is the API endpoint as host:port, or unix:///path for a Unix
+	// socket.
+	Host string `json:"host,omitempty"`
+	// Endpoints lists several endpoints instead of Host, balanced by
+	// LoadBalancing. See Config.Endpoints.
+	Endpoints     []string      `json:"endpoints,omitempty"`
+	LoadBalancing LoadBalancing `json:"load-balancing,omitempty"`
+	// Token is a fixed API token.
+	Token string `json:"token,omitempty"`
+	// TokenFile is read on every RPC, following rotation. See
+	// FileTokenSource.
+	TokenFile string `json:"token-file,omitempty"`
+	// TokenCommand is run to obtain a token, as the program and its
+	// arguments. Its output is cached until a minute before it expires.
+	// See CommandTokenSource.
+	TokenCommand []string   `json:"token-command,omitempty"`
+	AuthScheme   AuthScheme `json:"auth-scheme,omitempty"`
+	Transport    Transport  `json:"transport,omitempty"`
+	// Insecure connects without TLS.
+	Insecure bool `json:"insecure,omitempty"`
+	// Proxy is an http://, https:// or socks5:// proxy URL. See
+	// ConnectionOptions.Proxy.
+	Proxy string `json:"proxy,omitempty"`
+	// CAFile is a PEM bundle of CAs trusted instead of the system roots.
+	CAFile string `json:"ca-file,omitempty"`
+	// CertFile and KeyFile are a PEM client certificate and key for mutual
+	// TLS, re-read when they change.
+	CertFile string `json:"cert-file,omitempty"`
+	KeyFile  string `json:"key-file,omitempty"`
+	// ServerName overrides the name the server certificate is verified
+	// against.
+	ServerName string `json:"server-name,omitempty"`
+	// PinnedSPKIHashes restricts the server to these public keys. See
+	// ConnectionOptions.PinnedSPKIHashes.
+	PinnedSPKIHashes []string `json:"pinned-spki-hashes,omitempty"`
+}
+
+// DefaultConfigPath returns the path LoadConfig reads when none is given:
+// $ADMIRAL_CONFIG if set, otherwise admiral/config under the user's config
+// directory (~/.config/admiral/config on Linux).
+func DefaultConfigPath() (string, error) {
+	if path := os.Getenv(EnvConfig); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "admiral", "config"), nil
+}
+
+// ReadConfigFile parses the config file at path: TOML for a .toml file,
+// YAML (or JSON) for .yaml, .yml and .json. Any other file is read as YAML,
+// or as TOML if it is not valid YAML.
+func ReadConfigFile(path string) (*ConfigFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	var f ConfigFile
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		err = unmarshalTOML(data, &f)
+	case ".yaml", ".yml", ".json":
+		err = yaml.UnmarshalStrict(data, &f)
+	default:
+		if err = yaml.UnmarshalStrict(data, &f); err != nil {
+			var tf ConfigFile
+			if unmarshalTOML(data, &tf) == nil {
+				f, err = tf, nil
+			}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return &f, nil
+}
+
+// unmarshalTOML decodes TOML into v by way of JSON, so the json tags and
+// strict field checking apply to every format.
+func unmarshalTOML(data []byte, v any) error {
+	var m map[string]any
+	if err := toml.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return yaml.UnmarshalStrict(b, v)
+}
+
+// LoadConfig builds a Config from a profile in the config file at path,
+// with the ADMIRAL_HOST and ADMIRAL_TOKEN environment variables layered on
+// top, so the CLI and scripts share credentials.
+//
+// An empty path means DefaultConfigPath. The profile is, in order, the
+// profile argument, $ADMIRAL_PROFILE, the file's current-profile, or
+// DefaultProfile. A missing config file is not an error unless path or the
+// profile was given explicitly; the Config then comes from the environment
+// alone.
+//
+// The returned Config can be adjusted before it is passed to New.
+func LoadConfig(path, profile string) (Config, error) {
+	explicit := path != "" || profile != "" || os.Getenv(EnvProfile) != ""
+	if path == "" {
+		var err error
+		if path, err = DefaultConfigPath(); err != nil && explicit {
+			return Config{}, fmt.Errorf("failed to locate config file: %w", err)
+		}
+	}
+	if profile == "" {
+		profile = os.Getenv(EnvProfile)
+	}
+
+	var p Profile
+	f, err := ReadConfigFile(path)
+	switch {
+	case err == nil:
+		if profile == "" {
+			profile = cmp.Or(f.CurrentProfile, DefaultProfile)
+		}
+		var ok bool
+		if p, ok = f.Profiles[profile]; !ok && (explicit || f.CurrentProfile != "") {
+			return Config{}, fmt.Errorf("%w: %q in %s (have %s)", ErrProfileNotFound, profile, path, profileNames(f))
+		}
+	case errors.Is(err, fs.ErrNotExist) && !explicit:
+	case errors.Is(err, fs.ErrNotExist) && profile != "":
+		return Config{}, fmt.Errorf("%w: %q (no config file at %s)", ErrProfileNotFound, profile, path)
+	default:
+		return Config{}, err
+	}
+
+	if host := os.Getenv(EnvHost); host != "" {
+		p.Host, p.Endpoints = host, nil
+	}
+	if token := os.Getenv(EnvToken); token != "" {
+		p.Token, p.TokenFile, p.TokenCommand = token, "", nil
+	}
+	cfg, err := p.Config()
+	if err != nil {
+		return Config{}, fmt.Errorf("profile %q: %w", profile, err)
+	}
+	return cfg, nil
+}
+
+// Config converts the profile to a client Config. Its TLS files are read and
+// checked by Config.CheckAndSetDefaults.
+func (p Profile) Config() (Config, error) {
+	cfg := Config{
+		HostPort:      p.Host,
+		Endpoints:     p.Endpoints,
+		LoadBalancing: p.LoadBalancing,
+		AuthToken:     p.Token,
+		AuthScheme:    p.AuthScheme,
+		Transport:     p.Transport,
+		ConnectionOptions: ConnectionOptions{
+			Insecure:         p.Insecure,
+			Proxy:            p.Proxy,
+			CAFile:           p.CAFile,
+			CertFile:         p.CertFile,
+			KeyFile:          p.KeyFile,
+			ServerName:       p.ServerName,
+			PinnedSPKIHashes: p.PinnedSPKIHashes,
+		},
+	}
+
+	sources := 0
+	for _, set := range []bool{p.Token != "", p.TokenFile != "", len(p.TokenCommand) > 0} {
+		if set {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return Config{}, errors.New("token, token-file and token-command are mutually exclusive")
+	}
+	switch {
+	case p.TokenFile != "":
+		cfg.TokenSource = FileTokenSource(p.TokenFile)
+	case len(p.TokenCommand) > 0:
+		cfg.TokenSource = CachingTokenSource(CommandTokenSource(p.TokenCommand[0], p.TokenCommand[1:]...), time.Minute)
+	}
+
+	return cfg, nil
+}
+
+func profileNames(f *ConfigFile) string {
+	if len(f.Profiles) == 0 {
+		return "none"
+	}
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return strings.Join(names, ", ")
+}
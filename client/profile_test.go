@@ -0,0 +1,179 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testConfigFile = `
+current-profile: prod
+profiles:
+  prod:
+    host: api.example.com:443
+    token: prod-token-value
+    auth-scheme: Token
+  local:
+    host: localhost:8080
+    token-command: [echo, local-token-value]
+    transport: connect
+    insecure: true
+`
+
+// writeConfig writes a config file and isolates the test from the
+// ADMIRAL_* variables of the environment running it.
+func writeConfig(t *testing.T, contents string) string {
+	t.Helper()
+	for _, name := range []string{EnvConfig, EnvProfile, EnvHost, EnvToken} {
+		t.Setenv(name, "")
+	}
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig_Profiles(t *testing.T) {
+	path := writeConfig(t, testConfigFile)
+
+	cfg, err := LoadConfig(path, "")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.HostPort != "api.example.com:443" || cfg.AuthToken != "prod-token-value" || cfg.AuthScheme != AuthSchemeToken {
+		t.Errorf("current profile = %+v", cfg)
+	}
+
+	t.Setenv(EnvProfile, "local")
+	cfg, err = LoadConfig(path, "")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.HostPort != "localhost:8080" || cfg.Transport != TransportConnect || !cfg.ConnectionOptions.Insecure {
+		t.Errorf("ADMIRAL_PROFILE=local = %+v", cfg)
+	}
+	tok, err := cfg.TokenSource.Token(context.Background())
+	if err != nil || tok.Value != "local-token-value" {
+		t.Errorf("token command Token() = %v, %v, want local-token-value", tok, err)
+	}
+
+	if cfg, _ := LoadConfig(path, "prod"); cfg.HostPort != "api.example.com:443" {
+		t.Errorf("profile argument did not override ADMIRAL_PROFILE: %+v", cfg)
+	}
+	if _, err := LoadConfig(path, "staging"); !errors.Is(err, ErrProfileNotFound) || !strings.Contains(err.Error(), "local, prod") {
+		t.Errorf("unknown profile error = %v, want ErrProfileNotFound listing profiles", err)
+	}
+}
+
+func TestReadConfigFile_TOML(t *testing.T) {
+	const contents = `
+current-profile = "prod"
+
+[profiles.prod]
+host = "api.example.com:443"
+token = "prod-token-value"
+auth-scheme = "Token"
+
+[profiles.local]
+host = "localhost:8080"
+token-command = ["echo", "local-token-value"]
+transport = "connect"
+insecure = true
+`
+	want, err := ReadConfigFile(writeConfig(t, testConfigFile))
+	if err != nil {
+		t.Fatalf("ReadConfigFile(YAML) error = %v", err)
+	}
+	sniffed := writeConfig(t, contents)
+	named := sniffed + ".toml"
+	if err := os.WriteFile(named, []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{named, sniffed} {
+		got, err := ReadConfigFile(path)
+		if err != nil {
+			t.Fatalf("ReadConfigFile(%s) error = %v", filepath.Base(path), err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ReadConfigFile(%s) = %+v, want %+v", filepath.Base(path), got, want)
+		}
+	}
+
+	bad := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(bad, []byte("[profiles.default]\nhostname = \"x:1\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadConfigFile(bad); err == nil || !strings.Contains(err.Error(), "unknown field") {
+		t.Errorf("ReadConfigFile() with an unknown TOML key error = %v, want unknown field", err)
+	}
+}
+
+func TestLoadConfig_EnvOverrides(t *testing.T) {
+	path := writeConfig(t, testConfigFile)
+	t.Setenv(EnvHost, "override.example.com:443")
+	t.Setenv(EnvToken, "env-token-value")
+
+	cfg, err := LoadConfig(path, "local")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.HostPort != "override.example.com:443" || cfg.AuthToken != "env-token-value" || cfg.TokenSource != nil {
+		t.Errorf("LoadConfig() = %+v, want ADMIRAL_HOST and ADMIRAL_TOKEN applied", cfg)
+	}
+	if err := cfg.CheckAndSetDefaults(); err != nil {
+		t.Errorf("CheckAndSetDefaults() error = %v", err)
+	}
+}
+
+func TestLoadConfig_MissingFile(t *testing.T) {
+	missing := filepath.Join(writeConfig(t, ""), "..", "missing")
+	t.Setenv(EnvConfig, missing)
+	t.Setenv(EnvToken, "env-token-value")
+
+	cfg, err := LoadConfig("", "")
+	if err != nil {
+		t.Fatalf("LoadConfig() without a config file error = %v", err)
+	}
+	if cfg.AuthToken != "env-token-value" {
+		t.Errorf("AuthToken = %q, want env-token-value", cfg.AuthToken)
+	}
+
+	if _, err := LoadConfig(missing, ""); err == nil {
+		t.Error("LoadConfig() with an explicit missing path error = nil")
+	}
+	if _, err := LoadConfig("", "prod"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("LoadConfig() with a named profile and no file error = %v, want ErrProfileNotFound", err)
+	}
+}
+
+func TestLoadConfig_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     string
+	}{
+		{"unknown field", "profiles:\n  default:\n    hostname: x:1\n", "unknown field"},
+		{"bad transport", "profiles:\n  default:\n    transport: http\n", "unknown transport"},
+		{"two token sources", "profiles:\n  default:\n    token: abcdefghijk\n    token-file: /tmp/t\n", "mutually exclusive"},
+		{"missing CA file", "profiles:\n  default:\n    ca-file: /nonexistent/ca.pem\n", "CA file"},
+		{"cert without key", "profiles:\n  default:\n    cert-file: /tmp/c.pem\n", "set together"},
+		{"TLS files with insecure", "profiles:\n  default:\n    ca-file: /tmp/ca.pem\n    insecure: true\n", "Insecure is true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, tt.contents)
+			cfg, err := LoadConfig(path, "")
+			if err == nil {
+				err = cfg.CheckAndSetDefaults()
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadConfig() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,445 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"go.opentelemetry.io/otel/propagation"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	grpcstatus "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	agentv1 "go.admiral.io/sdk/proto/agent/v1"
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+	healthcheckv1 "go.admiral.io/sdk/proto/healthcheck/v1"
+	runnerv1 "go.admiral.io/sdk/proto/runner/v1"
+	serviceaccountv1 "go.admiral.io/sdk/proto/serviceaccount/v1"
+	userv1 "go.admiral.io/sdk/proto/user/v1"
+)
+
+// Compile-time check that restConn can back the generated gRPC clients.
+var _ grpc.ClientConnInterface = (*restConn)(nil)
+
+// newRESTClient builds a Client whose service accessors call the
+// grpc-gateway HTTP/JSON routes. The accessors still return the gRPC client
+// interfaces, so callers are unaware of the transport in use.
+func newRESTClient(cfg Config) *Client {
+	httpClient := cfg.ConnectionOptions.HTTPClient
+	if httpClient == nil {
+		httpClient = newHTTP1Client(cfg)
+	}
+
+	rest := &restConn{
+		httpClient: httpClient,
+		baseURL:    baseURL(cfg) + strings.TrimSuffix(cfg.ConnectionOptions.RESTPathPrefix, "/"),
+		creds:      cfg.perRPCCredentials(),
+	}
+	var conn grpc.ClientConnInterface = rest
+	if r := cfg.retrier(); r != nil {
+		conn = &retryConn{ClientConnInterface: rest, retrier: r}
+	}
+	if t := cfg.telemetry(); t != nil {
+		rest.propagator = t.propagator
+		conn = &telemetryConn{ClientConnInterface: conn, telemetry: t}
+	}
+	if s := cfg.scopeChecker(); s != nil {
+		conn = &scopeConn{ClientConnInterface: conn, checker: s}
+	}
+	if cfg.ConnectionOptions.ValidateRequests {
+		conn = &validateConn{ClientConnInterface: conn}
+	}
+	conn = &errorConn{ClientConnInterface: conn}
+
+	cfg.Logger.Debugf("using REST transport for Admiral API at %s", rest.baseURL)
+
+	return &Client{
+		httpClient:     httpClient,
+		logger:         cfg.Logger,
+		tokens:         cfg.TokenSource,
+		agent:          agentv1.NewAgentAPIClient(conn),
+		cluster:        clusterv1.NewClusterAPIClient(conn),
+		healthcheck:    healthcheckv1.NewHealthcheckAPIClient(conn),
+		runner:         runnerv1.NewRunnerAPIClient(conn),
+		serviceAccount: serviceaccountv1.NewServiceAccountAPIClient(conn),
+		user:           userv1.NewUserAPIClient(conn),
+	}
+}
+
+// newHTTP1Client builds an HTTP client restricted to HTTP/1.1, for API
+// gateways that do not accept HTTP/2.
+func newHTTP1Client(cfg Config) *http.Client {
+	httpClient := newHTTPClient(cfg)
+	transport := httpClient.Transport.(*http.Transport)
+	transport.ForceAttemptHTTP2 = false
+	transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	return httpClient
+}
+
+// restConn implements grpc.ClientConnInterface on top of the grpc-gateway
+// HTTP/JSON mapping. Routes are derived from the google.api.http annotations
+// on each method descriptor.
+type restConn struct {
+	httpClient *http.Client
+	baseURL    string
+	creds      credentials.PerRPCCredentials
+	// propagator injects the trace context into request headers when
+	// telemetry is enabled.
+	propagator propagation.TextMapPropagator
+}
+
+// Invoke performs a unary RPC as an HTTP/JSON request.
+func (c *restConn) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	in, ok := args.(proto.Message)
+	if !ok {
+		return grpcstatus.Errorf(codes.Internal, "request %T is not a proto message", args)
+	}
+	out, ok := reply.(proto.Message)
+	if !ok {
+		return grpcstatus.Errorf(codes.Internal, "response %T is not a proto message", reply)
+	}
+
+	req, err := c.newRequest(ctx, method, in)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return grpcstatus.FromContextError(ctx.Err()).Err()
+		}
+		return grpcstatus.Error(codes.Unavailable, err.Error())
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return grpcstatus.Errorf(codes.Unavailable, "failed to read response body: %v", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return withRetryAfter(restErrorToStatus(resp.StatusCode, body), resp.Header.Get("Retry-After"))
+	}
+
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, out); err != nil {
+		return grpcstatus.Errorf(codes.Internal, "failed to decode response: %v", err)
+	}
+
+	for _, opt := range opts {
+		switch o := opt.(type) {
+		case grpc.HeaderCallOption:
+			*o.HeaderAddr = prefixedHeaderToMD(resp.Header, runtime.MetadataHeaderPrefix)
+		case grpc.TrailerCallOption:
+			*o.TrailerAddr = prefixedHeaderToMD(resp.Header, runtime.MetadataTrailerPrefix)
+		}
+	}
+	return nil
+}
+
+// NewStream is not supported; the Admiral API has no streaming RPCs on its
+// HTTP/JSON mapping.
+func (c *restConn) NewStream(context.Context, *grpc.StreamDesc, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, grpcstatus.Error(codes.Unimplemented, "streaming RPCs are not supported by the REST transport")
+}
+
+// newRequest builds the HTTP request for method from its HttpRule.
+func (c *restConn) newRequest(ctx context.Context, method string, in proto.Message) (*http.Request, error) {
+	rule, err := lookupHTTPRule(method)
+	if err != nil {
+		return nil, err
+	}
+	verb, template := httpRulePattern(rule)
+	if verb == "" {
+		return nil, grpcstatus.Errorf(codes.Unimplemented, "method %s has no HTTP mapping", method)
+	}
+
+	msg := in.ProtoReflect()
+	path, bound, err := expandPathTemplate(template, msg)
+	if err != nil {
+		return nil, grpcstatus.Errorf(codes.InvalidArgument, "%s: %v", method, err)
+	}
+
+	var body io.Reader
+	query := url.Values{}
+	switch rule.GetBody() {
+	case "":
+		addQueryParams(query, msg, "", bound)
+	case "*":
+		data, err := protojson.Marshal(in)
+		if err != nil {
+			return nil, grpcstatus.Errorf(codes.Internal, "failed to encode request: %v", err)
+		}
+		body = bytes.NewReader(data)
+	default:
+		fd := msg.Descriptor().Fields().ByName(protoreflect.Name(rule.GetBody()))
+		if fd == nil || fd.Message() == nil {
+			return nil, grpcstatus.Errorf(codes.Internal, "%s: invalid body field %q", method, rule.GetBody())
+		}
+		data, err := protojson.Marshal(msg.Get(fd).Message().Interface())
+		if err != nil {
+			return nil, grpcstatus.Errorf(codes.Internal, "failed to encode request: %v", err)
+		}
+		body = bytes.NewReader(data)
+		bound[string(fd.Name())] = true
+		addQueryParams(query, msg, "", bound)
+	}
+
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, verb, target, body)
+	if err != nil {
+		return nil, grpcstatus.Errorf(codes.Internal, "failed to build request: %v", err)
+	}
+
+	md, err := c.creds.GetRequestMetadata(ctx, method)
+	if err != nil {
+		return nil, grpcstatus.Error(codes.Unauthenticated, err.Error())
+	}
+	for k, v := range md {
+		req.Header.Set(k, v)
+	}
+	if outgoing, ok := metadata.FromOutgoingContext(ctx); ok {
+		for k, vs := range outgoing {
+			for _, v := range vs {
+				req.Header.Add(runtime.MetadataHeaderPrefix+k, v)
+			}
+		}
+	}
+	if c.propagator != nil {
+		c.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	}
+	req.Header.Set("User-Agent", ClientUserAgent())
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+// lookupHTTPRule returns the google.api.http rule for a gRPC method name of
+// the form "/package.Service/Method".
+func lookupHTTPRule(method string) (*annotations.HttpRule, error) {
+	name := protoreflect.FullName(strings.Replace(strings.TrimPrefix(method, "/"), "/", ".", 1))
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(name)
+	if err != nil {
+		return nil, grpcstatus.Errorf(codes.Unimplemented, "unknown method %s", method)
+	}
+	md, ok := desc.(protoreflect.MethodDescriptor)
+	if !ok {
+		return nil, grpcstatus.Errorf(codes.Unimplemented, "%s is not a method", method)
+	}
+	rule, _ := proto.GetExtension(md.Options(), annotations.E_Http).(*annotations.HttpRule)
+	if rule == nil {
+		return nil, grpcstatus.Errorf(codes.Unimplemented, "method %s has no HTTP mapping", method)
+	}
+	return rule, nil
+}
+
+// httpRulePattern returns the HTTP verb and path template of a rule.
+func httpRulePattern(rule *annotations.HttpRule) (string, string) {
+	switch p := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		return http.MethodGet, p.Get
+	case *annotations.HttpRule_Post:
+		return http.MethodPost, p.Post
+	case *annotations.HttpRule_Put:
+		return http.MethodPut, p.Put
+	case *annotations.HttpRule_Patch:
+		return http.MethodPatch, p.Patch
+	case *annotations.HttpRule_Delete:
+		return http.MethodDelete, p.Delete
+	case *annotations.HttpRule_Custom:
+		return p.Custom.GetKind(), p.Custom.GetPath()
+	default:
+		return "", ""
+	}
+}
+
+// expandPathTemplate substitutes {field.path} variables in template with
+// values from msg. It returns the expanded path and the set of top-level
+// field paths consumed by the path.
+func expandPathTemplate(template string, msg protoreflect.Message) (string, map[string]bool, error) {
+	bound := map[string]bool{}
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			b.WriteString(template)
+			break
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			return "", nil, fmt.Errorf("malformed path template %q", template)
+		}
+		end += start
+		b.WriteString(template[:start])
+
+		fieldPath, _, _ := strings.Cut(template[start+1:end], "=")
+		value, err := fieldPathValue(msg, fieldPath)
+		if err != nil {
+			return "", nil, err
+		}
+		if value == "" {
+			return "", nil, fmt.Errorf("missing required path field %q", fieldPath)
+		}
+		b.WriteString(url.PathEscape(value))
+		bound[fieldPath] = true
+		template = template[end+1:]
+	}
+	return b.String(), bound, nil
+}
+
+// fieldPathValue resolves a dotted field path against msg.
+func fieldPathValue(msg protoreflect.Message, fieldPath string) (string, error) {
+	names := strings.Split(fieldPath, ".")
+	for i, name := range names {
+		fd := msg.Descriptor().Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			return "", fmt.Errorf("unknown path field %q", fieldPath)
+		}
+		if i == len(names)-1 {
+			return scalarString(fd, msg.Get(fd)), nil
+		}
+		if fd.Message() == nil || !msg.Has(fd) {
+			return "", nil
+		}
+		msg = msg.Get(fd).Message()
+	}
+	return "", nil
+}
+
+// addQueryParams encodes the populated fields of msg that are not bound to
+// the path as query parameters, using dotted names for nested messages.
+func addQueryParams(query url.Values, msg protoreflect.Message, prefix string, bound map[string]bool) {
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		name := prefix + string(fd.Name())
+		if bound[name] {
+			return true
+		}
+		switch {
+		case fd.IsMap():
+			// Maps have no query parameter encoding in grpc-gateway.
+		case fd.IsList():
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				query.Add(name, scalarString(fd, list.Get(i)))
+			}
+		case fd.Message() != nil:
+			if s, ok := wellKnownString(v.Message()); ok {
+				query.Set(name, s)
+			} else {
+				addQueryParams(query, v.Message(), name+".", bound)
+			}
+		default:
+			query.Set(name, scalarString(fd, v))
+		}
+		return true
+	})
+}
+
+// wellKnownString renders well-known types with a string JSON form, such
+// as Timestamp, Duration and FieldMask.
+func wellKnownString(msg protoreflect.Message) (string, bool) {
+	if msg.Descriptor().ParentFile().Package() != "google.protobuf" {
+		return "", false
+	}
+	data, err := protojson.Marshal(msg.Interface())
+	if err != nil {
+		return "", false
+	}
+	s, err := strconv.Unquote(string(data))
+	if err != nil {
+		return "", false
+	}
+	return s, true
+}
+
+func scalarString(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return strconv.Itoa(int(v.Enum()))
+	case protoreflect.BytesKind:
+		return base64.URLEncoding.EncodeToString(v.Bytes())
+	default:
+		return v.String()
+	}
+}
+
+// restErrorToStatus decodes a grpc-gateway error body into a gRPC status
+// error, falling back to the HTTP status code when the body is not a
+// google.rpc.Status.
+func restErrorToStatus(httpStatus int, body []byte) error {
+	st := &status.Status{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, st); err == nil && st.GetCode() != 0 {
+		return grpcstatus.FromProto(st).Err()
+	}
+	msg := strings.TrimSpace(string(body))
+	if msg == "" {
+		msg = http.StatusText(httpStatus)
+	}
+	return grpcstatus.Error(codeFromHTTPStatus(httpStatus), msg)
+}
+
+// codeFromHTTPStatus is the inverse of runtime.HTTPStatusFromCode. A bare
+// 502 Bad Gateway comes from a proxy that could not reach the API, so it is
+// Unavailable, as on the Connect transport.
+func codeFromHTTPStatus(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	case 499:
+		return codes.Canceled
+	default:
+		return codes.Unknown
+	}
+}
+
+// prefixedHeaderToMD extracts grpc-gateway metadata headers carrying prefix.
+func prefixedHeaderToMD(h http.Header, prefix string) metadata.MD {
+	md := metadata.MD{}
+	for k, vs := range h {
+		if name, ok := strings.CutPrefix(k, prefix); ok {
+			md.Append(strings.ToLower(name), vs...)
+		}
+	}
+	return md
+}
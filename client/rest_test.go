@@ -0,0 +1,205 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+)
+
+type testClusterServer struct {
+	clusterv1.UnimplementedClusterAPIServer
+	lastList *clusterv1.ListClustersRequest
+	lastMD   metadata.MD
+}
+
+func (s *testClusterServer) GetCluster(ctx context.Context, req *clusterv1.GetClusterRequest) (*clusterv1.GetClusterResponse, error) {
+	s.lastMD, _ = metadata.FromIncomingContext(ctx)
+	if req.GetClusterId() == "missing" {
+		return nil, status.Error(codes.NotFound, "cluster not found")
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs("x-request-id", "req-123"))
+	return &clusterv1.GetClusterResponse{
+		Cluster: &clusterv1.Cluster{Id: req.GetClusterId(), DisplayName: "prod"},
+	}, nil
+}
+
+func (s *testClusterServer) ListClusters(_ context.Context, req *clusterv1.ListClustersRequest) (*clusterv1.ListClustersResponse, error) {
+	s.lastList = req
+	return &clusterv1.ListClustersResponse{
+		Clusters:      []*clusterv1.Cluster{{Id: "c1"}, {Id: "c2"}},
+		NextPageToken: "next",
+	}, nil
+}
+
+func (s *testClusterServer) UpdateCluster(_ context.Context, req *clusterv1.UpdateClusterRequest) (*clusterv1.UpdateClusterResponse, error) {
+	return &clusterv1.UpdateClusterResponse{Cluster: req.GetCluster()}, nil
+}
+
+func newTestRESTClient(t *testing.T, srv *testClusterServer, configure ...func(*Config)) *Client {
+	t.Helper()
+	gw := runtime.NewServeMux()
+	if err := clusterv1.RegisterClusterAPIHandlerServer(context.Background(), gw, srv); err != nil {
+		t.Fatalf("RegisterClusterAPIHandlerServer() error = %v", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/api/", http.StripPrefix("/api", gw))
+	httpSrv := httptest.NewServer(mux)
+	t.Cleanup(httpSrv.Close)
+
+	cfg := Config{
+		HostPort:  strings.TrimPrefix(httpSrv.URL, "http://"),
+		AuthToken: "this-is-a-valid-opaque-token-12345",
+		Transport: TransportREST,
+		ConnectionOptions: ConnectionOptions{
+			Insecure: true,
+		},
+	}
+	for _, f := range configure {
+		f(&cfg)
+	}
+	c, err := New(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func TestRESTTransport_GetWithPathParam(t *testing.T) {
+	srv := &testClusterServer{}
+	c := newTestRESTClient(t, srv)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-trace", "abc")
+	var header metadata.MD
+	resp, err := c.Cluster().GetCluster(ctx, &clusterv1.GetClusterRequest{ClusterId: "c1"}, grpc.Header(&header))
+	if err != nil {
+		t.Fatalf("GetCluster() error = %v", err)
+	}
+	if got := resp.GetCluster().GetId(); got != "c1" {
+		t.Errorf("cluster id = %q, want %q", got, "c1")
+	}
+	if got := srv.lastMD.Get("authorization"); len(got) != 1 || got[0] != "Bearer this-is-a-valid-opaque-token-12345" {
+		t.Errorf("authorization metadata = %v", got)
+	}
+	if got := srv.lastMD.Get("x-trace"); len(got) != 1 || got[0] != "abc" {
+		t.Errorf("x-trace metadata = %v, want [abc]", got)
+	}
+	if got := header.Get("x-request-id"); len(got) != 1 || got[0] != "req-123" {
+		t.Errorf("response header x-request-id = %v, want [req-123]", got)
+	}
+}
+
+func TestRESTTransport_ListWithQueryParams(t *testing.T) {
+	srv := &testClusterServer{}
+	c := newTestRESTClient(t, srv)
+
+	resp, err := c.Cluster().ListClusters(context.Background(), &clusterv1.ListClustersRequest{
+		PageSize:  25,
+		PageToken: "tok",
+		Filter:    `display_name = "ci-*"`,
+	})
+	if err != nil {
+		t.Fatalf("ListClusters() error = %v", err)
+	}
+	if len(resp.GetClusters()) != 2 || resp.GetNextPageToken() != "next" {
+		t.Errorf("unexpected response: %v", resp)
+	}
+	if srv.lastList.GetPageSize() != 25 || srv.lastList.GetPageToken() != "tok" || srv.lastList.GetFilter() != `display_name = "ci-*"` {
+		t.Errorf("server received %v", srv.lastList)
+	}
+}
+
+func TestRESTTransport_PatchWithBody(t *testing.T) {
+	c := newTestRESTClient(t, &testClusterServer{})
+
+	resp, err := c.Cluster().UpdateCluster(context.Background(), &clusterv1.UpdateClusterRequest{
+		Cluster:    &clusterv1.Cluster{Id: "c1", DisplayName: "renamed"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"display_name"}},
+	})
+	if err != nil {
+		t.Fatalf("UpdateCluster() error = %v", err)
+	}
+	if got := resp.GetCluster().GetDisplayName(); got != "renamed" {
+		t.Errorf("display name = %q, want %q", got, "renamed")
+	}
+}
+
+func TestRESTTransport_Errors(t *testing.T) {
+	c := newTestRESTClient(t, &testClusterServer{})
+
+	_, err := c.Cluster().GetCluster(context.Background(), &clusterv1.GetClusterRequest{ClusterId: "missing"})
+	if got := status.Code(err); got != codes.NotFound {
+		t.Fatalf("status.Code() = %v, want %v (err = %v)", got, codes.NotFound, err)
+	}
+	if got := status.Convert(err).Message(); got != "cluster not found" {
+		t.Errorf("message = %q, want %q", got, "cluster not found")
+	}
+
+	_, err = c.Cluster().GetCluster(context.Background(), &clusterv1.GetClusterRequest{})
+	if got := status.Code(err); got != codes.InvalidArgument {
+		t.Errorf("status.Code() for missing path field = %v, want %v", got, codes.InvalidArgument)
+	}
+}
+
+func TestRESTTransport_BareStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		want   codes.Code
+	}{
+		{http.StatusConflict, codes.AlreadyExists},
+		{http.StatusBadGateway, codes.Unavailable},
+		{http.StatusServiceUnavailable, codes.Unavailable},
+		{http.StatusGatewayTimeout, codes.DeadlineExceeded},
+		{http.StatusInternalServerError, codes.Unknown},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			c := newBareStatusClient(t, tt.status)
+			_, err := c.Cluster().GetCluster(context.Background(), &clusterv1.GetClusterRequest{ClusterId: "c1"})
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("status.Code() for a bare %d = %v, want %v", tt.status, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRESTTransport_BareConflict(t *testing.T) {
+	c := newBareStatusClient(t, http.StatusConflict)
+	_, err := c.Cluster().GetCluster(context.Background(), &clusterv1.GetClusterRequest{ClusterId: "c1"})
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("GetCluster() error = %v, want ErrConflict for a 409 without a status body", err)
+	}
+}
+
+// newBareStatusClient returns a REST client for a server that answers every
+// request with code and no body.
+func newBareStatusClient(t *testing.T, code int) *Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(code)
+	}))
+	t.Cleanup(srv.Close)
+	c, err := New(context.Background(), Config{
+		HostPort:          strings.TrimPrefix(srv.URL, "http://"),
+		AuthToken:         "this-is-a-valid-opaque-token-12345",
+		Transport:         TransportREST,
+		ConnectionOptions: ConnectionOptions{Insecure: true},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
@@ -0,0 +1,252 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// RetryPolicy configures automatic retries of failed unary RPCs.
+//
+// Only idempotent methods (those named Get* or List*) are retried unless the
+// call opts in with WithRetry. A server hint in a google.rpc.RetryInfo error
+// detail, or a Retry-After header on the HTTP transports, replaces the
+// computed backoff for that attempt. Retries stop early when the next delay
+// would exceed the context deadline.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Defaults to DefaultRetryMaxAttempts; 1 disables retries.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry. Defaults to
+	// DefaultRetryInitialBackoff.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the computed delay. Defaults to DefaultRetryMaxBackoff.
+	MaxBackoff time.Duration
+	// BackoffMultiplier grows the delay after each retry. Defaults to
+	// DefaultRetryBackoffMultiplier.
+	BackoffMultiplier float64
+	// Jitter randomizes each delay by up to this fraction in either
+	// direction, at most 1. Defaults to DefaultRetryJitter; a negative
+	// value disables jitter.
+	Jitter float64
+	// RetryableCodes are the status codes that trigger a retry. Defaults to
+	// Unavailable and ResourceExhausted.
+	RetryableCodes []codes.Code
+}
+
+// DefaultRetryPolicy returns a RetryPolicy with every field set to its
+// default.
+func DefaultRetryPolicy() *RetryPolicy {
+	p := &RetryPolicy{}
+	p.setDefaults()
+	return p
+}
+
+func (p *RetryPolicy) setDefaults() {
+	if p.MaxAttempts == 0 {
+		p.MaxAttempts = DefaultRetryMaxAttempts
+	}
+	if p.InitialBackoff == 0 {
+		p.InitialBackoff = DefaultRetryInitialBackoff
+	}
+	if p.MaxBackoff == 0 {
+		p.MaxBackoff = DefaultRetryMaxBackoff
+	}
+	if p.BackoffMultiplier == 0 {
+		p.BackoffMultiplier = DefaultRetryBackoffMultiplier
+	}
+	if p.Jitter == 0 {
+		p.Jitter = DefaultRetryJitter
+	}
+	if len(p.RetryableCodes) == 0 {
+		p.RetryableCodes = []codes.Code{codes.Unavailable, codes.ResourceExhausted}
+	}
+}
+
+func (p *RetryPolicy) validate() error {
+	switch {
+	case p.MaxAttempts < 1:
+		return fmt.Errorf("retry policy: MaxAttempts must be at least 1, got %d", p.MaxAttempts)
+	case p.InitialBackoff < 0 || p.MaxBackoff < 0:
+		return errors.New("retry policy: backoff must not be negative")
+	case p.BackoffMultiplier < 1:
+		return fmt.Errorf("retry policy: BackoffMultiplier must be at least 1, got %g", p.BackoffMultiplier)
+	case p.Jitter > 1:
+		return fmt.Errorf("retry policy: Jitter must be at most 1, got %g", p.Jitter)
+	}
+	return nil
+}
+
+// backoff returns the delay before retry number n (starting at 1).
+func (p *RetryPolicy) backoff(n int) time.Duration {
+	d := float64(p.InitialBackoff) * math.Pow(p.BackoffMultiplier, float64(n-1))
+	d = math.Min(d, float64(p.MaxBackoff))
+	if p.Jitter > 0 {
+		d *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(d)
+}
+
+// retryCallOption marks a call as safe to retry.
+type retryCallOption struct {
+	grpc.EmptyCallOption
+}
+
+// WithRetry opts a single call into the client's RetryPolicy. Use it for
+// mutating RPCs that are safe to repeat, such as an update that sets
+// absolute values. It has no effect when no RetryPolicy is configured.
+func WithRetry() grpc.CallOption {
+	return retryCallOption{}
+}
+
+func hasRetryOption(opts []grpc.CallOption) bool {
+	for _, opt := range opts {
+		if _, ok := opt.(retryCallOption); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isIdempotentMethod reports whether a full method name such as
+// "/admiral.api.cluster.v1.ClusterAPI/GetCluster" names a read-only RPC.
+func isIdempotentMethod(method string) bool {
+	name := method[strings.LastIndex(method, "/")+1:]
+	return strings.HasPrefix(name, "Get") || strings.HasPrefix(name, "List")
+}
+
+// retrier runs calls under a RetryPolicy.
+type retrier struct {
+	policy RetryPolicy
+	logger Logger
+}
+
+// do calls invoke until it succeeds, fails with a non-retryable error or the
+// attempts are exhausted. Errors must be gRPC status errors.
+func (r *retrier) do(ctx context.Context, method string, force bool, invoke func(context.Context) error) error {
+	if !force && !isIdempotentMethod(method) {
+		return invoke(ctx)
+	}
+	for attempt := 1; ; attempt++ {
+		err := invoke(ctx)
+		if err == nil || attempt >= r.policy.MaxAttempts {
+			return err
+		}
+		st := status.Convert(err)
+		if !slices.Contains(r.policy.RetryableCodes, st.Code()) {
+			return err
+		}
+
+		delay, ok := retryDelay(st)
+		if !ok {
+			delay = r.policy.backoff(attempt)
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+		r.logger.Debugf("retrying %s in %v after %s (attempt %d of %d)", method, delay, st.Code(), attempt+1, r.policy.MaxAttempts)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// unaryInterceptor applies the retrier to gRPC calls.
+func (r *retrier) unaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return r.do(ctx, method, hasRetryOption(opts), func(ctx context.Context) error {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		})
+	}
+}
+
+// retryConn applies the retrier to a grpc.ClientConnInterface.
+type retryConn struct {
+	grpc.ClientConnInterface
+	retrier *retrier
+}
+
+func (c *retryConn) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	return c.retrier.do(ctx, method, hasRetryOption(opts), func(ctx context.Context) error {
+		return c.ClientConnInterface.Invoke(ctx, method, args, reply, opts...)
+	})
+}
+
+type forceRetryKey struct{}
+
+// connectInterceptor applies the retrier to Connect calls. Per-call opt-in
+// reaches the interceptor through the context, set by connectUnary.
+func (r *retrier) connectInterceptor() connect.UnaryInterceptorFunc {
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			force, _ := ctx.Value(forceRetryKey{}).(bool)
+			var resp connect.AnyResponse
+			var connectErr error
+			err := r.do(ctx, req.Spec().Procedure, force, func(ctx context.Context) error {
+				resp, connectErr = next(ctx, req)
+				if connectErr != nil {
+					return connectErrorToStatus(connectErr)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, connectErr
+			}
+			return resp, nil
+		}
+	}
+}
+
+// retryDelay returns the server's RetryInfo hint, if any.
+func retryDelay(st *status.Status) (time.Duration, bool) {
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.RetryInfo); ok && info.GetRetryDelay() != nil {
+			return max(info.GetRetryDelay().AsDuration(), 0), true
+		}
+	}
+	return 0, false
+}
+
+// withRetryAfter attaches an HTTP Retry-After header value to a status error
+// as a RetryInfo detail, unless the status already carries one.
+func withRetryAfter(err error, retryAfter string) error {
+	if retryAfter == "" {
+		return err
+	}
+	st := status.Convert(err)
+	if _, ok := retryDelay(st); ok {
+		return err
+	}
+	var delay time.Duration
+	if secs, perr := strconv.Atoi(retryAfter); perr == nil {
+		delay = time.Duration(secs) * time.Second
+	} else if t, perr := http.ParseTime(retryAfter); perr == nil {
+		delay = time.Until(t)
+	} else {
+		return err
+	}
+	withInfo, derr := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(max(delay, 0))})
+	if derr != nil {
+		return err
+	}
+	return withInfo.Err()
+}
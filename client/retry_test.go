@@ -0,0 +1,233 @@
+package client
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+)
+
+func newTestRetrier() *retrier {
+	return &retrier{
+		policy: RetryPolicy{
+			MaxAttempts:       3,
+			InitialBackoff:    time.Millisecond,
+			MaxBackoff:        time.Millisecond,
+			BackoffMultiplier: 2,
+			RetryableCodes:    []codes.Code{codes.Unavailable},
+		},
+		logger: NewNoOpLogger(),
+	}
+}
+
+// failingCall fails with err for the first n calls and counts every call.
+func failingCall(n int, err error, calls *int) func(context.Context) error {
+	return func(context.Context) error {
+		*calls++
+		if *calls <= n {
+			return err
+		}
+		return nil
+	}
+}
+
+func TestRetrier_RetriesIdempotentMethods(t *testing.T) {
+	r := newTestRetrier()
+	calls := 0
+	err := r.do(context.Background(), "/admiral.api.cluster.v1.ClusterAPI/GetCluster", false,
+		failingCall(2, status.Error(codes.Unavailable, "down"), &calls))
+	if err != nil {
+		t.Fatalf("do() error = %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+}
+
+func TestRetrier_GivesUpAfterMaxAttempts(t *testing.T) {
+	r := newTestRetrier()
+	calls := 0
+	err := r.do(context.Background(), "/admiral.api.cluster.v1.ClusterAPI/ListClusters", false,
+		failingCall(5, status.Error(codes.Unavailable, "down"), &calls))
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("do() error = %v, want Unavailable", err)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+}
+
+func TestRetrier_MutatingMethodsNeedOptIn(t *testing.T) {
+	r := newTestRetrier()
+	method := "/admiral.api.cluster.v1.ClusterAPI/CreateCluster"
+
+	calls := 0
+	_ = r.do(context.Background(), method, false, failingCall(1, status.Error(codes.Unavailable, "down"), &calls))
+	if calls != 1 {
+		t.Errorf("without opt-in: calls = %d, want 1", calls)
+	}
+
+	calls = 0
+	if err := r.do(context.Background(), method, true, failingCall(1, status.Error(codes.Unavailable, "down"), &calls)); err != nil {
+		t.Fatalf("with opt-in: do() error = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("with opt-in: calls = %d, want 2", calls)
+	}
+}
+
+func TestRetrier_NonRetryableCode(t *testing.T) {
+	r := newTestRetrier()
+	calls := 0
+	err := r.do(context.Background(), "/admiral.api.cluster.v1.ClusterAPI/GetCluster", false,
+		failingCall(1, status.Error(codes.NotFound, "missing"), &calls))
+	if status.Code(err) != codes.NotFound || calls != 1 {
+		t.Errorf("do() = %v after %d calls, want NotFound after 1", err, calls)
+	}
+}
+
+func TestRetrier_HonorsRetryInfo(t *testing.T) {
+	r := newTestRetrier()
+	r.policy.InitialBackoff = time.Hour
+	r.policy.MaxBackoff = time.Hour
+
+	st, _ := status.New(codes.Unavailable, "busy").WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(time.Millisecond)})
+	calls := 0
+	start := time.Now()
+	if err := r.do(context.Background(), "/admiral.api.cluster.v1.ClusterAPI/GetCluster", false, failingCall(1, st.Err(), &calls)); err != nil {
+		t.Fatalf("do() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("elapsed = %v, want the server hint to replace the backoff", elapsed)
+	}
+}
+
+func TestRetrier_StopsBeforeDeadline(t *testing.T) {
+	r := newTestRetrier()
+	r.policy.InitialBackoff = time.Minute
+	r.policy.MaxBackoff = time.Minute
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	calls := 0
+	err := r.do(ctx, "/admiral.api.cluster.v1.ClusterAPI/GetCluster", false,
+		failingCall(5, status.Error(codes.Unavailable, "down"), &calls))
+	if status.Code(err) != codes.Unavailable || calls != 1 {
+		t.Errorf("do() = %v after %d calls, want Unavailable after 1", err, calls)
+	}
+}
+
+func TestWithRetryAfter(t *testing.T) {
+	err := withRetryAfter(status.Error(codes.Unavailable, "busy"), "7")
+	delay, ok := retryDelay(status.Convert(err))
+	if !ok || delay != 7*time.Second {
+		t.Errorf("retryDelay() = %v, %v, want 7s, true", delay, ok)
+	}
+
+	err = withRetryAfter(status.Error(codes.Unavailable, "busy"), "soon")
+	if _, ok := retryDelay(status.Convert(err)); ok {
+		t.Error("retryDelay() found a hint for an unparseable Retry-After")
+	}
+}
+
+func TestRetryPolicy_Validate(t *testing.T) {
+	err := (&Config{
+		AuthToken:         "this-is-a-valid-opaque-token-12345",
+		ConnectionOptions: ConnectionOptions{RetryPolicy: &RetryPolicy{Jitter: 2}},
+	}).CheckAndSetDefaults()
+	if err == nil {
+		t.Error("CheckAndSetDefaults() accepted Jitter > 1")
+	}
+}
+
+func TestRetryPolicy_NegativeJitterDisablesJitter(t *testing.T) {
+	p := &RetryPolicy{Jitter: -1, InitialBackoff: 100 * time.Millisecond}
+	p.setDefaults()
+	if err := p.validate(); err != nil {
+		t.Fatalf("validate() error = %v", err)
+	}
+	for range 20 {
+		if got := p.backoff(1); got != 100*time.Millisecond {
+			t.Fatalf("backoff(1) = %v, want exactly 100ms", got)
+		}
+	}
+}
+
+// flakyClusterServer fails the first failures calls of each method.
+type flakyClusterServer struct {
+	clusterv1.UnimplementedClusterAPIServer
+	failures int
+	calls    map[string]int
+}
+
+func (s *flakyClusterServer) fail(method string) error {
+	s.calls[method]++
+	if s.calls[method] <= s.failures {
+		return status.Error(codes.Unavailable, "control plane restarting")
+	}
+	return nil
+}
+
+func (s *flakyClusterServer) GetCluster(_ context.Context, req *clusterv1.GetClusterRequest) (*clusterv1.GetClusterResponse, error) {
+	if err := s.fail("GetCluster"); err != nil {
+		return nil, err
+	}
+	return &clusterv1.GetClusterResponse{Cluster: &clusterv1.Cluster{Id: req.GetClusterId()}}, nil
+}
+
+func (s *flakyClusterServer) DeleteCluster(context.Context, *clusterv1.DeleteClusterRequest) (*clusterv1.DeleteClusterResponse, error) {
+	if err := s.fail("DeleteCluster"); err != nil {
+		return nil, err
+	}
+	return &clusterv1.DeleteClusterResponse{}, nil
+}
+
+func TestGRPCTransport_Retry(t *testing.T) {
+	srv := &flakyClusterServer{failures: 2, calls: map[string]int{}}
+	lis := bufconn.Listen(1024 * 1024)
+	gs := grpc.NewServer()
+	clusterv1.RegisterClusterAPIServer(gs, srv)
+	go func() { _ = gs.Serve(lis) }()
+	t.Cleanup(gs.Stop)
+
+	c, err := New(context.Background(), Config{
+		HostPort:  "bufconn:0",
+		AuthToken: "this-is-a-valid-opaque-token-12345",
+		ConnectionOptions: ConnectionOptions{
+			Insecure: true,
+			DialOptions: []grpc.DialOption{grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			})},
+			RetryPolicy: &RetryPolicy{InitialBackoff: time.Millisecond},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	ctx := context.Background()
+
+	if _, err := c.Cluster().GetCluster(ctx, &clusterv1.GetClusterRequest{ClusterId: "c1"}); err != nil {
+		t.Fatalf("GetCluster() error = %v", err)
+	}
+	if srv.calls["GetCluster"] != 3 {
+		t.Errorf("GetCluster calls = %d, want 3", srv.calls["GetCluster"])
+	}
+
+	_, err = c.Cluster().DeleteCluster(ctx, &clusterv1.DeleteClusterRequest{ClusterId: "c1"})
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("DeleteCluster() error = %v, want Unavailable without opt-in", err)
+	}
+	if _, err := c.Cluster().DeleteCluster(ctx, &clusterv1.DeleteClusterRequest{ClusterId: "c1"}, WithRetry()); err != nil {
+		t.Fatalf("DeleteCluster(WithRetry) error = %v", err)
+	}
+}
@@ -0,0 +1,170 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"connectrpc.com/connect"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	authzv1 "go.admiral.io/sdk/proto/authz/v1"
+)
+
+// Token types named in AuthRule.AllowedTokenTypes.
+const (
+	TokenTypePersonalAccess = "pat"
+	TokenTypeServiceAccount = "sat"
+	TokenTypeAgent          = "agt"
+	TokenTypeSession        = "session"
+)
+
+var authRules sync.Map // method name -> *authzv1.AuthRule, nil if none
+
+// AuthRuleFor returns the authz rule annotated on a gRPC method name of the
+// form "/package.Service/Method". It reports false for unknown methods and
+// methods without a rule, which need no authentication.
+func AuthRuleFor(method string) (*authzv1.AuthRule, bool) {
+	if v, ok := authRules.Load(method); ok {
+		rule := v.(*authzv1.AuthRule)
+		return rule, rule != nil
+	}
+	var rule *authzv1.AuthRule
+	name := protoreflect.FullName(strings.Replace(strings.TrimPrefix(method, "/"), "/", ".", 1))
+	if desc, err := protoregistry.GlobalFiles.FindDescriptorByName(name); err == nil {
+		if md, ok := desc.(protoreflect.MethodDescriptor); ok && proto.HasExtension(md.Options(), authzv1.E_Rule) {
+			rule, _ = proto.GetExtension(md.Options(), authzv1.E_Rule).(*authzv1.AuthRule)
+		}
+	}
+	authRules.Store(method, rule)
+	return rule, rule != nil
+}
+
+// RequiredScope returns the scope needed to call method, such as
+// "clusters:write". It returns "" when any authenticated token will do or
+// the method is unknown.
+func RequiredScope(method string) string {
+	rule, _ := AuthRuleFor(method)
+	return rule.GetScope()
+}
+
+// tokenType returns the type named by a JWT's token_type claim, or "" if
+// the token does not carry one.
+func tokenType(token string) string {
+	claims, err := ParseJWTToken(strings.TrimPrefix(token, "Bearer "))
+	if err != nil {
+		return ""
+	}
+	return claims.TokenType
+}
+
+// tokenScopes returns the scopes granted to a token, and false when they
+// cannot be read from the token itself. Only JWTs with a scope claim carry
+// their scopes.
+func tokenScopes(token string) ([]string, bool) {
+	claims, err := ParseJWTToken(strings.TrimPrefix(token, "Bearer "))
+	if err != nil || claims.Scope == "" {
+		return nil, false
+	}
+	return strings.Fields(claims.Scope), true
+}
+
+// HasScope reports whether the granted scopes satisfy scope, honouring the
+// "*" and "resource:*" wildcards. Servers checking Admiral tokens should use
+// it so they grant exactly what the client expects.
+func HasScope(granted []string, scope string) bool {
+	resource, _, _ := strings.Cut(scope, ":")
+	return slices.Contains(granted, scope) || slices.Contains(granted, "*") || slices.Contains(granted, resource+":*")
+}
+
+// scopeChecker rejects calls the current token clearly cannot make, before
+// they are sent. Calls whose token type or scopes are unknown are let
+// through for the server to decide.
+type scopeChecker struct {
+	tokens TokenSource
+}
+
+func (s *scopeChecker) check(ctx context.Context, method string) error {
+	rule, ok := AuthRuleFor(method)
+	if !ok {
+		return nil
+	}
+	t, err := s.tokens.Token(ctx)
+	if err != nil {
+		// Leave token errors to the per-RPC credentials.
+		return nil
+	}
+
+	if allowed := rule.GetAllowedTokenTypes(); len(allowed) > 0 {
+		if typ := tokenType(t.Value); typ != "" && !slices.Contains(allowed, typ) {
+			return status.Errorf(codes.PermissionDenied, "%s tokens cannot call %s (allowed: %s)", typ, method, strings.Join(allowed, ", "))
+		}
+	}
+
+	scope := rule.GetScope()
+	if scope == "" {
+		return nil
+	}
+	granted, ok := tokenScopes(t.Value)
+	if !ok || HasScope(granted, scope) {
+		return nil
+	}
+	st := status.New(codes.PermissionDenied, fmt.Sprintf("token is missing scope %q required by %s", scope, method))
+	if withInfo, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   ErrorReasonInsufficientScope,
+		Metadata: map[string]string{"scope": scope},
+	}); err == nil {
+		st = withInfo
+	}
+	return st.Err()
+}
+
+func (s *scopeChecker) unaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if err := s.check(ctx, method); err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func (s *scopeChecker) streamInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if err := s.check(ctx, method); err != nil {
+			return nil, err
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+func (s *scopeChecker) connectInterceptor() connect.UnaryInterceptorFunc {
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			if err := s.check(ctx, req.Spec().Procedure); err != nil {
+				return nil, statusToConnectError(status.Convert(err))
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// scopeConn applies a scopeChecker to the REST transport.
+type scopeConn struct {
+	grpc.ClientConnInterface
+	checker *scopeChecker
+}
+
+func (c *scopeConn) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	if err := c.checker.check(ctx, method); err != nil {
+		return err
+	}
+	return c.ClientConnInterface.Invoke(ctx, method, args, reply, opts...)
+}
@@ -0,0 +1,142 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	agentv1 "go.admiral.io/sdk/proto/agent/v1"
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+)
+
+func TestRequiredScope(t *testing.T) {
+	tests := []struct {
+		method string
+		want   string
+	}{
+		{clusterv1.ClusterAPI_CreateCluster_FullMethodName, "clusters:write"},
+		{clusterv1.ClusterAPI_GetCluster_FullMethodName, "clusters:read"},
+		{agentv1.AgentAPI_Heartbeat_FullMethodName, "agents:heartbeat"},
+		{"/admiral.api.cluster.v1.ClusterAPI/NoSuchMethod", ""},
+		{"not a method", ""},
+	}
+	for _, tt := range tests {
+		if got := RequiredScope(tt.method); got != tt.want {
+			t.Errorf("RequiredScope(%q) = %q, want %q", tt.method, got, tt.want)
+		}
+	}
+
+	rule, ok := AuthRuleFor(clusterv1.ClusterAPI_ReportClusterStatus_FullMethodName)
+	if !ok || len(rule.GetAllowedTokenTypes()) != 1 || rule.GetAllowedTokenTypes()[0] != TokenTypeAgent {
+		t.Errorf("AuthRuleFor(ReportClusterStatus) = %v, %v, want agt only", rule, ok)
+	}
+}
+
+func TestHasScope(t *testing.T) {
+	tests := []struct {
+		granted []string
+		scope   string
+		want    bool
+	}{
+		{[]string{"clusters:read"}, "clusters:read", true},
+		{[]string{"clusters:*"}, "clusters:write", true},
+		{[]string{"*"}, "agents:heartbeat", true},
+		{[]string{"clusters:read"}, "clusters:write", false},
+		{[]string{"agents:*"}, "clusters:read", false},
+		{nil, "clusters:read", false},
+	}
+	for _, tt := range tests {
+		if got := HasScope(tt.granted, tt.scope); got != tt.want {
+			t.Errorf("HasScope(%v, %q) = %v, want %v", tt.granted, tt.scope, got, tt.want)
+		}
+	}
+}
+
+func TestTokenType(t *testing.T) {
+	tests := map[string]string{
+		createTestJWT(JWTClaims{Subject: "u", TokenType: TokenTypePersonalAccess}):             TokenTypePersonalAccess,
+		"Bearer " + createTestJWT(JWTClaims{Subject: "u", TokenType: TokenTypeServiceAccount}): TokenTypeServiceAccount,
+		createTestJWT(JWTClaims{Subject: "u"}):                                                 "",
+		"adm_agt_abcdefghijkl":                                                                 "",
+		"this-is-a-valid-opaque-token-12345":                                                   "",
+	}
+	for token, want := range tests {
+		if got := tokenType(token); got != want {
+			t.Errorf("tokenType(%q) = %q, want %q", token, got, want)
+		}
+	}
+}
+
+func TestScopeChecker(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Unix()
+	readOnly := createTestJWT(JWTClaims{Subject: "u", ExpirationTime: exp, Scope: "clusters:read agents:read"})
+	wildcard := createTestJWT(JWTClaims{Subject: "u", ExpirationTime: exp, Scope: "clusters:*"})
+	pat := createTestJWT(JWTClaims{Subject: "u", ExpirationTime: exp, TokenType: TokenTypePersonalAccess})
+	agent := createTestJWT(JWTClaims{Subject: "a", ExpirationTime: exp, TokenType: TokenTypeAgent})
+	untyped := createTestJWT(JWTClaims{Subject: "a", ExpirationTime: exp, Scope: "agents:*"})
+
+	tests := []struct {
+		name      string
+		token     string
+		method    string
+		wantScope string
+		wantErr   bool
+	}{
+		{"granted scope", readOnly, clusterv1.ClusterAPI_GetCluster_FullMethodName, "", false},
+		{"missing scope", readOnly, clusterv1.ClusterAPI_CreateCluster_FullMethodName, "clusters:write", true},
+		{"wildcard scope", wildcard, clusterv1.ClusterAPI_CreateCluster_FullMethodName, "", false},
+		{"unknown scopes", pat, clusterv1.ClusterAPI_CreateCluster_FullMethodName, "", false},
+		{"token type not allowed", pat, clusterv1.ClusterAPI_ReportClusterStatus_FullMethodName, "", true},
+		{"token type allowed", agent, clusterv1.ClusterAPI_ReportClusterStatus_FullMethodName, "", false},
+		{"JWT without token type", untyped, agentv1.AgentAPI_RegisterAgent_FullMethodName, "", false},
+		{"opaque token", "adm_pat_abcdefghijkl", clusterv1.ClusterAPI_ReportClusterStatus_FullMethodName, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &scopeChecker{tokens: StaticTokenSource(tt.token)}
+			err := wrapError(s.check(context.Background(), tt.method))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				return
+			}
+			var e *Error
+			if !errors.Is(err, ErrPermissionDenied) || !errors.As(err, &e) {
+				t.Fatalf("check() error = %v, want ErrPermissionDenied", err)
+			}
+			if e.MissingScope != tt.wantScope {
+				t.Errorf("MissingScope = %q, want %q", e.MissingScope, tt.wantScope)
+			}
+		})
+	}
+}
+
+func TestCheckScopes_Transports(t *testing.T) {
+	pat := func(cfg *Config) {
+		cfg.AuthToken = createTestJWT(JWTClaims{Subject: "u", ExpirationTime: time.Now().Add(time.Hour).Unix(), TokenType: TokenTypePersonalAccess})
+		cfg.ConnectionOptions.CheckScopes = true
+	}
+
+	t.Run("rest", func(t *testing.T) {
+		c := newTestRESTClient(t, &testClusterServer{}, pat)
+		_, err := c.Cluster().ReportClusterStatus(context.Background(), &clusterv1.ReportClusterStatusRequest{ClusterId: "c1"})
+		if !errors.Is(err, ErrPermissionDenied) {
+			t.Errorf("ReportClusterStatus() error = %v, want ErrPermissionDenied", err)
+		}
+		if _, err := c.Cluster().GetCluster(context.Background(), &clusterv1.GetClusterRequest{ClusterId: "c1"}); err != nil {
+			t.Errorf("GetCluster() error = %v", err)
+		}
+	})
+	t.Run("connect", func(t *testing.T) {
+		c := newTestConnectClient(t, &testAgentHandler{}, EncodingProto, pat)
+		_, err := c.Agent().Heartbeat(context.Background(), &agentv1.HeartbeatRequest{AgentId: "a1"})
+		if !errors.Is(err, ErrPermissionDenied) || status.Code(err) != codes.PermissionDenied {
+			t.Errorf("Heartbeat() error = %v, want ErrPermissionDenied without reaching the server", err)
+		}
+	})
+}
@@ -0,0 +1,237 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// instrumentationName identifies the SDK as the source of its spans and
+// metrics.
+const instrumentationName = "go.admiral.io/sdk/client"
+
+// TelemetryOptions configures OpenTelemetry instrumentation of API calls.
+// Nil providers fall back to the global ones registered with the otel
+// package.
+type TelemetryOptions struct {
+	// TracerProvider creates the client span for each RPC.
+	TracerProvider trace.TracerProvider
+	// MeterProvider records RPC latency and error counts.
+	MeterProvider metric.MeterProvider
+	// Propagator injects the trace context into outgoing requests so the
+	// server joins the caller's trace.
+	Propagator propagation.TextMapPropagator
+}
+
+// telemetry records a span and metrics for each RPC, for every transport.
+type telemetry struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+	attrs      []attribute.KeyValue
+	duration   metric.Float64Histogram
+	errors     metric.Int64Counter
+}
+
+func newTelemetry(opts TelemetryOptions, system, hostPort string) *telemetry {
+	tp := opts.TracerProvider
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	mp := opts.MeterProvider
+	if mp == nil {
+		mp = otel.GetMeterProvider()
+	}
+	prop := opts.Propagator
+	if prop == nil {
+		prop = otel.GetTextMapPropagator()
+	}
+
+	t := &telemetry{
+		tracer:     tp.Tracer(instrumentationName, trace.WithInstrumentationVersion(Version())),
+		propagator: prop,
+		attrs:      []attribute.KeyValue{attribute.String("rpc.system", system)},
+	}
+	if host, port, err := net.SplitHostPort(hostPort); err == nil {
+		t.attrs = append(t.attrs, attribute.String("server.address", host), attribute.String("server.port", port))
+	}
+
+	meter := mp.Meter(instrumentationName, metric.WithInstrumentationVersion(Version()))
+	var err error
+	t.duration, err = meter.Float64Histogram("rpc.client.duration",
+		metric.WithDescription("Duration of Admiral API calls."),
+		metric.WithUnit("ms"))
+	if err != nil {
+		otel.Handle(err)
+	}
+	t.errors, err = meter.Int64Counter("rpc.client.errors",
+		metric.WithDescription("Number of Admiral API calls that returned an error."),
+		metric.WithUnit("{call}"))
+	if err != nil {
+		otel.Handle(err)
+	}
+	return t
+}
+
+// start opens the client span for method, a full gRPC method name such as
+// "/admiral.api.cluster.v1.ClusterAPI/GetCluster". The returned function
+// ends the span and records metrics for the call's outcome.
+func (t *telemetry) start(ctx context.Context, method string) (context.Context, func(codes.Code, error)) {
+	service, name := splitMethod(method)
+	attrs := append([]attribute.KeyValue{
+		attribute.String("rpc.service", service),
+		attribute.String("rpc.method", name),
+	}, t.attrs...)
+
+	ctx, span := t.tracer.Start(ctx, strings.TrimPrefix(method, "/"),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+	begin := time.Now()
+
+	return ctx, func(code codes.Code, err error) {
+		elapsed := float64(time.Since(begin)) / float64(time.Millisecond)
+		attrs := append(attrs, attribute.Int("rpc.grpc.status_code", int(code)))
+		set := metric.WithAttributes(attrs...)
+
+		span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(code)))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(otelcodes.Error, status.Convert(err).Message())
+			if t.errors != nil {
+				t.errors.Add(ctx, 1, set)
+			}
+		}
+		if t.duration != nil {
+			t.duration.Record(ctx, elapsed, set)
+		}
+		span.End()
+	}
+}
+
+// unaryInterceptor instruments gRPC unary calls.
+func (t *telemetry) unaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, end := t.start(ctx, method)
+		err := invoker(t.injectMetadata(ctx), method, req, reply, cc, opts...)
+		end(status.Code(err), err)
+		return err
+	}
+}
+
+// streamInterceptor instruments gRPC streams. The span ends when the stream
+// fails to open or the first receive returns an error, including io.EOF.
+func (t *telemetry) streamInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, end := t.start(ctx, method)
+		stream, err := streamer(t.injectMetadata(ctx), desc, cc, method, opts...)
+		if err != nil {
+			end(status.Code(err), err)
+			return nil, err
+		}
+		return &telemetryStream{ClientStream: stream, end: end}, nil
+	}
+}
+
+// injectMetadata adds the trace context in ctx to the outgoing metadata.
+func (t *telemetry) injectMetadata(ctx context.Context) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	t.propagator.Inject(ctx, metadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// connectInterceptor instruments Connect calls and propagates the trace
+// context in request headers.
+func (t *telemetry) connectInterceptor() connect.UnaryInterceptorFunc {
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			ctx, end := t.start(ctx, req.Spec().Procedure)
+			t.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header()))
+			resp, err := next(ctx, req)
+			code := codes.OK
+			if err != nil {
+				code = codes.Code(connect.CodeOf(err))
+			}
+			end(code, err)
+			return resp, err
+		}
+	}
+}
+
+// telemetryConn instruments REST calls. restConn injects the trace context
+// into the HTTP request headers.
+type telemetryConn struct {
+	grpc.ClientConnInterface
+	telemetry *telemetry
+}
+
+func (c *telemetryConn) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	ctx, end := c.telemetry.start(ctx, method)
+	err := c.ClientConnInterface.Invoke(ctx, method, args, reply, opts...)
+	end(status.Code(err), err)
+	return err
+}
+
+type telemetryStream struct {
+	grpc.ClientStream
+	end  func(codes.Code, error)
+	done bool
+}
+
+func (s *telemetryStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil && !s.done {
+		s.done = true
+		if errors.Is(err, io.EOF) {
+			s.end(codes.OK, nil)
+		} else {
+			s.end(status.Code(err), err)
+		}
+	}
+	return err
+}
+
+// metadataCarrier adapts gRPC metadata to a propagation.TextMapCarrier.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// splitMethod splits "/pkg.Service/Method" into its service and method.
+func splitMethod(method string) (service, name string) {
+	method = strings.TrimPrefix(method, "/")
+	if i := strings.LastIndex(method, "/"); i >= 0 {
+		return method[:i], method[i+1:]
+	}
+	return "unknown", method
+}
@@ -0,0 +1,190 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	agentv1 "go.admiral.io/sdk/proto/agent/v1"
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+)
+
+// testTelemetry collects spans and metrics in memory.
+type testTelemetry struct {
+	spans  *tracetest.SpanRecorder
+	reader *sdkmetric.ManualReader
+	opts   *TelemetryOptions
+}
+
+func newTestTelemetry() *testTelemetry {
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	return &testTelemetry{
+		spans:  spans,
+		reader: reader,
+		opts: &TelemetryOptions{
+			TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)),
+			MeterProvider:  sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+			Propagator:     propagation.TraceContext{},
+		},
+	}
+}
+
+func (tt *testTelemetry) configure(cfg *Config) {
+	cfg.ConnectionOptions.Telemetry = tt.opts
+}
+
+// metric returns the aggregation recorded for the named metric.
+func (tt *testTelemetry) metric(t *testing.T, name string) metricdata.Aggregation {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := tt.reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				return m.Data
+			}
+		}
+	}
+	t.Fatalf("metric %s not recorded", name)
+	return nil
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func spanAttr(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTelemetry_GRPC(t *testing.T) {
+	tel := newTestTelemetry()
+	srv := &testClusterServer{}
+	lis := bufconn.Listen(1024 * 1024)
+	gs := grpc.NewServer()
+	clusterv1.RegisterClusterAPIServer(gs, srv)
+	go func() { _ = gs.Serve(lis) }()
+	t.Cleanup(gs.Stop)
+
+	cfg := Config{
+		HostPort:  "bufconn:0",
+		AuthToken: "this-is-a-valid-opaque-token-12345",
+		ConnectionOptions: ConnectionOptions{
+			Insecure: true,
+			DialOptions: []grpc.DialOption{grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			})},
+		},
+	}
+	tel.configure(&cfg)
+	c, err := New(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	ctx := context.Background()
+
+	if _, err := c.Cluster().GetCluster(ctx, &clusterv1.GetClusterRequest{ClusterId: "c1"}); err != nil {
+		t.Fatalf("GetCluster() error = %v", err)
+	}
+	if len(srv.lastMD.Get("traceparent")) != 1 {
+		t.Errorf("server metadata = %v, want traceparent", srv.lastMD)
+	}
+	if _, err := c.Cluster().GetCluster(ctx, &clusterv1.GetClusterRequest{ClusterId: "missing"}); err == nil {
+		t.Fatal("GetCluster(missing) succeeded")
+	}
+
+	spans := tel.spans.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("spans = %d, want 2", len(spans))
+	}
+	ok, failed := spans[0], spans[1]
+	if ok.Name() != "admiral.api.cluster.v1.ClusterAPI/GetCluster" {
+		t.Errorf("span name = %q", ok.Name())
+	}
+	if got := spanAttr(ok, "rpc.service").AsString(); got != "admiral.api.cluster.v1.ClusterAPI" {
+		t.Errorf("rpc.service = %q", got)
+	}
+	if got := spanAttr(ok, "rpc.system").AsString(); got != "grpc" {
+		t.Errorf("rpc.system = %q, want grpc", got)
+	}
+	if failed.Status().Code != otelcodes.Error || spanAttr(failed, "rpc.grpc.status_code").AsInt64() != 5 {
+		t.Errorf("failed span status = %v, code = %v, want Error and NotFound",
+			failed.Status(), spanAttr(failed, "rpc.grpc.status_code"))
+	}
+
+	hist := tel.metric(t, "rpc.client.duration").(metricdata.Histogram[float64])
+	var calls uint64
+	for _, dp := range hist.DataPoints {
+		calls += dp.Count
+	}
+	if calls != 2 || len(hist.DataPoints) != 2 {
+		t.Errorf("duration: %d calls in %d series, want 2 in 2 (one per status code)", calls, len(hist.DataPoints))
+	}
+	errs := tel.metric(t, "rpc.client.errors").(metricdata.Sum[int64])
+	if len(errs.DataPoints) != 1 || errs.DataPoints[0].Value != 1 {
+		t.Errorf("errors = %+v, want a single NotFound", errs.DataPoints)
+	}
+}
+
+func TestTelemetry_Connect(t *testing.T) {
+	tel := newTestTelemetry()
+	handler := &testAgentHandler{}
+	c := newTestConnectClient(t, handler, EncodingProto, tel.configure)
+
+	if _, err := c.Agent().GetAgent(context.Background(), &agentv1.GetAgentRequest{AgentId: "missing"}); err == nil {
+		t.Fatal("GetAgent(missing) succeeded")
+	}
+	if handler.lastHeader.Get("Traceparent") == "" {
+		t.Errorf("request headers = %v, want traceparent", handler.lastHeader)
+	}
+	spans := tel.spans.Ended()
+	if len(spans) != 1 || spans[0].Name() != "admiral.api.agent.v1.AgentAPI/GetAgent" {
+		t.Fatalf("spans = %v, want admiral.api.agent.v1.AgentAPI/GetAgent", spans)
+	}
+	if got := spanAttr(spans[0], "rpc.grpc.status_code").AsInt64(); got != 5 {
+		t.Errorf("rpc.grpc.status_code = %d, want 5 (NotFound)", got)
+	}
+}
+
+func TestTelemetry_REST(t *testing.T) {
+	tel := newTestTelemetry()
+	srv := &testClusterServer{}
+	var sent http.Header
+	c := newTestRESTClient(t, srv, tel.configure, func(cfg *Config) {
+		cfg.ConnectionOptions.HTTPClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			sent = req.Header.Clone()
+			return http.DefaultTransport.RoundTrip(req)
+		})}
+	})
+
+	if _, err := c.Cluster().GetCluster(context.Background(), &clusterv1.GetClusterRequest{ClusterId: "c1"}); err != nil {
+		t.Fatalf("GetCluster() error = %v", err)
+	}
+	if sent.Get("Traceparent") == "" {
+		t.Errorf("request headers = %v, want traceparent", sent)
+	}
+	spans := tel.spans.Ended()
+	if len(spans) != 1 || spanAttr(spans[0], "rpc.system").AsString() != "http" {
+		t.Fatalf("spans = %v, want one http span", spans)
+	}
+}
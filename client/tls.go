@@ -0,0 +1,176 @@
+package client
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// ErrSPKIPinMismatch is returned from the TLS handshake when
+// ConnectionOptions.PinnedSPKIHashes is set and no certificate in the
+// server's verified chain matches a pin.
+var ErrSPKIPinMismatch = errors.New("server certificate does not match any pinned public key")
+
+// SPKIHash returns the base64-encoded SHA-256 hash of the certificate's
+// SubjectPublicKeyInfo, the form used by ConnectionOptions.PinnedSPKIHashes.
+// The same value is printed by:
+//
+//	openssl x509 -in cert.pem -pubkey -noout |
+//	  openssl pkey -pubin -outform der |
+//	  openssl dgst -sha256 -binary | base64
+func SPKIHash(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// hasTLSFiles reports whether any of the file-based TLS options are set.
+func (o *ConnectionOptions) hasTLSFiles() bool {
+	return o.CAFile != "" || o.CertFile != "" || o.KeyFile != "" || o.ServerName != "" || len(o.PinnedSPKIHashes) > 0
+}
+
+// applyTLSFiles validates the file-based TLS options and applies them to a
+// copy of TLSConfig.
+func (o *ConnectionOptions) applyTLSFiles(logger Logger) error {
+	if o.Insecure {
+		return errors.New("CAFile, CertFile, KeyFile, ServerName and PinnedSPKIHashes require TLS but Insecure is true")
+	}
+	tlsConfig := o.TLSConfig.Clone()
+
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("CA file %s contains no PEM certificates", o.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	switch {
+	case o.CertFile == "" && o.KeyFile == "":
+	case o.CertFile == "" || o.KeyFile == "":
+		return errors.New("CertFile and KeyFile must be set together")
+	default:
+		r := &certReloader{certFile: o.CertFile, keyFile: o.KeyFile, logger: logger}
+		if err := r.reload(); err != nil {
+			return err
+		}
+		tlsConfig.Certificates = nil
+		tlsConfig.GetClientCertificate = r.clientCertificate
+	}
+
+	if o.ServerName != "" {
+		tlsConfig.ServerName = o.ServerName
+	}
+
+	if len(o.PinnedSPKIHashes) > 0 {
+		pins := make(map[string]bool, len(o.PinnedSPKIHashes))
+		for _, pin := range o.PinnedSPKIHashes {
+			if sum, err := base64.StdEncoding.DecodeString(pin); err != nil || len(sum) != sha256.Size {
+				return fmt.Errorf("invalid SPKI pin %q: want a base64-encoded SHA-256 hash", pin)
+			}
+			pins[pin] = true
+		}
+		next, skipVerify := tlsConfig.VerifyConnection, tlsConfig.InsecureSkipVerify
+		tlsConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+			if next != nil {
+				if err := next(cs); err != nil {
+					return err
+				}
+			}
+			return verifyPins(cs, pins, skipVerify)
+		}
+	}
+
+	o.TLSConfig = tlsConfig
+	return nil
+}
+
+// verifyPins checks that a certificate in a verified chain to the server
+// matches a pin. Other certificates the server sent are ignored, since
+// anyone can append a pinned certificate to their own chain. With
+// skipVerify there are no verified chains, and only the leaf is checked.
+func verifyPins(cs tls.ConnectionState, pins map[string]bool, skipVerify bool) error {
+	chains := cs.VerifiedChains
+	if skipVerify && len(cs.PeerCertificates) > 0 {
+		chains = [][]*x509.Certificate{cs.PeerCertificates[:1]}
+	}
+	for _, chain := range chains {
+		for _, cert := range chain {
+			if pins[SPKIHash(cert)] {
+				return nil
+			}
+		}
+	}
+	return ErrSPKIPinMismatch
+}
+
+// certReloader serves a client certificate from a certificate and key file,
+// re-reading them whenever either file's size or modification time changes.
+// This follows daily rotation of mounted certificates without a restart.
+type certReloader struct {
+	certFile, keyFile string
+	logger            Logger
+
+	mu    sync.Mutex
+	stamp []byte
+	cert  *tls.Certificate
+}
+
+func (r *certReloader) clientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stamp, err := r.fileStamp()
+	if err == nil && !bytes.Equal(stamp, r.stamp) {
+		err = r.load(stamp)
+	}
+	if err != nil {
+		// A rotation may be half written; keep the previous certificate
+		// until both files are readable again.
+		r.logger.Warnf("keeping previous client certificate: %v", err)
+	}
+	return r.cert, nil
+}
+
+// reload loads the files unconditionally.
+func (r *certReloader) reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stamp, err := r.fileStamp()
+	if err != nil {
+		return err
+	}
+	return r.load(stamp)
+}
+
+func (r *certReloader) load(stamp []byte) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load client certificate: %w", err)
+	}
+	r.cert = &cert
+	r.stamp = stamp
+	return nil
+}
+
+// fileStamp identifies the current version of both files by size and
+// modification time.
+func (r *certReloader) fileStamp() ([]byte, error) {
+	var stamp []byte
+	for _, path := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat client certificate: %w", err)
+		}
+		stamp = fmt.Appendf(stamp, "%d:%d;", info.Size(), info.ModTime().UnixNano())
+	}
+	return stamp, nil
+}
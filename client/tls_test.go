@@ -0,0 +1,259 @@
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCA issues certificates for TLS tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	ca := &testCA{}
+	ca.cert, ca.key, ca.pem = ca.issue(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "test CA"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	})
+	return ca
+}
+
+// issue signs template with the CA, or self-signs it when the CA has no
+// certificate yet, and returns the certificate, its key and its PEM.
+func (ca *testCA) issue(t *testing.T, template *x509.Certificate) (*x509.Certificate, *ecdsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	parent, signer := template, key
+	if ca.cert != nil {
+		parent, signer = ca.cert, ca.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+// writeKeyPair issues a certificate for name and writes it and its key to
+// certFile and keyFile.
+func (ca *testCA) writeKeyPair(t *testing.T, name string, usage x509.ExtKeyUsage, certFile, keyFile string) tls.Certificate {
+	t.Helper()
+	_, key, certPEM := ca.issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: name},
+		DNSNames:    []string{name},
+		ExtKeyUsage: []x509.ExtKeyUsage{usage},
+	})
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(certFile, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pair
+}
+
+// handshake runs a TLS handshake between client and a server requiring a
+// client certificate signed by ca, and returns the client certificate's
+// common name as seen by the server.
+func handshake(t *testing.T, client *tls.Config, server tls.Certificate, ca *testCA) (string, error) {
+	t.Helper()
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	serverConf := &tls.Config{
+		Certificates: []tls.Certificate{server},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+
+	c, s := net.Pipe()
+	defer c.Close()
+	defer s.Close()
+	peer := make(chan string, 1)
+	go func() {
+		conn := tls.Server(s, serverConf)
+		if err := conn.Handshake(); err != nil {
+			peer <- ""
+			return
+		}
+		peer <- conn.ConnectionState().PeerCertificates[0].Subject.CommonName
+	}()
+
+	if err := tls.Client(c, client).Handshake(); err != nil {
+		c.Close()
+		<-peer
+		return "", err
+	}
+	return <-peer, nil
+}
+
+func TestConnectionOptions_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	caFile := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(caFile, ca.pem, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	server := ca.writeKeyPair(t, "admiral.internal", x509.ExtKeyUsageServerAuth, filepath.Join(dir, "server.pem"), filepath.Join(dir, "server-key.pem"))
+	certFile, keyFile := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem")
+	ca.writeKeyPair(t, "client-1", x509.ExtKeyUsageClientAuth, certFile, keyFile)
+
+	cfg := Config{
+		HostPort:  "10.0.0.1:443",
+		AuthToken: "test-token-value",
+		ConnectionOptions: ConnectionOptions{
+			CAFile:     caFile,
+			CertFile:   certFile,
+			KeyFile:    keyFile,
+			ServerName: "admiral.internal",
+		},
+	}
+	if err := cfg.CheckAndSetDefaults(); err != nil {
+		t.Fatalf("CheckAndSetDefaults() error = %v", err)
+	}
+	tlsConfig := cfg.ConnectionOptions.TLSConfig
+
+	if name, err := handshake(t, tlsConfig, server, ca); err != nil || name != "client-1" {
+		t.Fatalf("handshake() = %q, %v, want client-1", name, err)
+	}
+
+	// Rotate the client certificate in place.
+	ca.writeKeyPair(t, "client-2", x509.ExtKeyUsageClientAuth, certFile, keyFile)
+	future := time.Now().Add(time.Minute)
+	for _, f := range []string{certFile, keyFile} {
+		if err := os.Chtimes(f, future, future); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if name, err := handshake(t, tlsConfig, server, ca); err != nil || name != "client-2" {
+		t.Errorf("handshake() after rotation = %q, %v, want client-2", name, err)
+	}
+
+	// A half-written rotation keeps the previous certificate.
+	if err := os.WriteFile(keyFile, []byte("partial"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if name, err := handshake(t, tlsConfig, server, ca); err != nil || name != "client-2" {
+		t.Errorf("handshake() with a broken key file = %q, %v, want client-2", name, err)
+	}
+}
+
+func TestConnectionOptions_SPKIPinning(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	caFile := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(caFile, ca.pem, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	server := ca.writeKeyPair(t, "admiral.internal", x509.ExtKeyUsageServerAuth, filepath.Join(dir, "server.pem"), filepath.Join(dir, "server-key.pem"))
+	certFile, keyFile := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem")
+	ca.writeKeyPair(t, "client", x509.ExtKeyUsageClientAuth, certFile, keyFile)
+	other := newTestCA(t)
+
+	// The server appends the pinned but unrelated certificate to its chain.
+	padded := server
+	padded.Certificate = append(slices.Clone(server.Certificate), other.cert.Raw)
+
+	for _, tt := range []struct {
+		name       string
+		pins       []string
+		server     tls.Certificate
+		skipVerify bool
+		wantErr    error
+	}{
+		{"leaf pinned", []string{SPKIHash(server.Leaf)}, server, false, nil},
+		{"CA pinned", []string{SPKIHash(other.cert), SPKIHash(ca.cert)}, server, false, nil},
+		{"no match", []string{SPKIHash(other.cert)}, server, false, ErrSPKIPinMismatch},
+		{"pinned cert appended to chain", []string{SPKIHash(other.cert)}, padded, false, ErrSPKIPinMismatch},
+		{"skip verify leaf pinned", []string{SPKIHash(server.Leaf)}, padded, true, nil},
+		{"skip verify CA pinned", []string{SPKIHash(ca.cert)}, server, true, ErrSPKIPinMismatch},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				AuthToken: "test-token-value",
+				ConnectionOptions: ConnectionOptions{
+					TLSConfig:        &tls.Config{InsecureSkipVerify: tt.skipVerify},
+					CAFile:           caFile,
+					CertFile:         certFile,
+					KeyFile:          keyFile,
+					ServerName:       "admiral.internal",
+					PinnedSPKIHashes: tt.pins,
+				},
+			}
+			if err := cfg.CheckAndSetDefaults(); err != nil {
+				t.Fatalf("CheckAndSetDefaults() error = %v", err)
+			}
+			_, err := handshake(t, cfg.ConnectionOptions.TLSConfig, tt.server, ca)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("handshake() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConnectionOptions_TLSValidation(t *testing.T) {
+	dir := t.TempDir()
+	notPEM := filepath.Join(dir, "not.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opts ConnectionOptions
+		want string
+	}{
+		{"missing CA file", ConnectionOptions{CAFile: filepath.Join(dir, "missing.pem")}, "failed to read CA file"},
+		{"CA file without certificates", ConnectionOptions{CAFile: notPEM}, "contains no PEM certificates"},
+		{"cert without key", ConnectionOptions{CertFile: notPEM}, "must be set together"},
+		{"unparsable key pair", ConnectionOptions{CertFile: notPEM, KeyFile: notPEM}, "failed to load client certificate"},
+		{"bad pin", ConnectionOptions{PinnedSPKIHashes: []string{"abc"}}, "invalid SPKI pin"},
+		{"insecure", ConnectionOptions{Insecure: true, ServerName: "x"}, "Insecure is true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{AuthToken: "test-token-value", ConnectionOptions: tt.opts}
+			if err := cfg.CheckAndSetDefaults(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("CheckAndSetDefaults() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
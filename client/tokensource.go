@@ -0,0 +1,189 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoToken is returned by a TokenSource that has no token to offer, such
+// as an unset environment variable or an empty token file.
+var ErrNoToken = errors.New("no auth token available")
+
+// Token is an auth token and the time it stops being valid.
+type Token struct {
+	// Value is the raw token sent in the Authorization header.
+	Value string
+	// Expiry is when the token expires. The zero value means it does not
+	// expire.
+	Expiry time.Time
+}
+
+// Valid reports whether the token is non-empty and not expired.
+func (t *Token) Valid() bool {
+	return t != nil && t.Value != "" && (t.Expiry.IsZero() || time.Now().Before(t.Expiry))
+}
+
+// TokenSource supplies the auth token for each RPC. Implementations must be
+// safe for concurrent use.
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// TokenSourceFunc adapts a function to a TokenSource.
+type TokenSourceFunc func(ctx context.Context) (*Token, error)
+
+// Token calls f(ctx).
+func (f TokenSourceFunc) Token(ctx context.Context) (*Token, error) {
+	return f(ctx)
+}
+
+// StaticTokenSource returns a TokenSource that always returns token. Its
+// expiry is taken from the exp claim when token is a JWT.
+func StaticTokenSource(token string) TokenSource {
+	t := &Token{Value: token, Expiry: jwtExpiry(token)}
+	return TokenSourceFunc(func(context.Context) (*Token, error) {
+		return t, nil
+	})
+}
+
+// EnvTokenSource returns a TokenSource that reads the named environment
+// variable on every call, so a process that updates its own environment
+// picks up the new value immediately.
+func EnvTokenSource(name string) TokenSource {
+	return TokenSourceFunc(func(context.Context) (*Token, error) {
+		value := strings.TrimSpace(os.Getenv(name))
+		if value == "" {
+			return nil, fmt.Errorf("%w: environment variable %s is not set", ErrNoToken, name)
+		}
+		return &Token{Value: value, Expiry: jwtExpiry(value)}, nil
+	})
+}
+
+// FileTokenSource returns a TokenSource that reads the token from a file and
+// re-reads it whenever the file's size or modification time changes. This
+// follows rotation of mounted secrets and projected service account tokens,
+// which replace the file in place or swap a symlink.
+func FileTokenSource(path string) TokenSource {
+	return &fileTokenSource{path: path}
+}
+
+type fileTokenSource struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	token   *Token
+}
+
+func (s *fileTokenSource) Token(context.Context) (*Token, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat token file: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != nil && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return s.token, nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+	value := string(bytes.TrimSpace(data))
+	if value == "" {
+		return nil, fmt.Errorf("%w: token file %s is empty", ErrNoToken, s.path)
+	}
+	s.token = &Token{Value: value, Expiry: jwtExpiry(value)}
+	s.modTime = info.ModTime()
+	s.size = info.Size()
+	return s.token, nil
+}
+
+// CommandTokenSource returns a TokenSource that runs the named command on
+// every call and uses its trimmed standard output as the token, like the
+// credential helpers of cloud CLIs. Wrap it in CachingTokenSource so the
+// command only runs when its token nears expiry.
+func CommandTokenSource(name string, args ...string) TokenSource {
+	return TokenSourceFunc(func(ctx context.Context) (*Token, error) {
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, name, args...)
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return nil, fmt.Errorf("token command %s failed: %w: %s", name, err, msg)
+			}
+			return nil, fmt.Errorf("token command %s failed: %w", name, err)
+		}
+		value := string(bytes.TrimSpace(out))
+		if value == "" {
+			return nil, fmt.Errorf("%w: token command %s printed nothing", ErrNoToken, name)
+		}
+		return &Token{Value: value, Expiry: jwtExpiry(value)}, nil
+	})
+}
+
+// CachingTokenSource wraps src and reuses its token until it is within
+// earlyExpiry of expiring, then fetches a new one. Concurrent callers share
+// a single refresh. Tokens without an expiry are cached indefinitely.
+//
+// Use it in front of sources that are expensive to call, such as a token
+// exchange against an identity provider.
+func CachingTokenSource(src TokenSource, earlyExpiry time.Duration) TokenSource {
+	return &cachingTokenSource{src: src, earlyExpiry: earlyExpiry}
+}
+
+type cachingTokenSource struct {
+	src         TokenSource
+	earlyExpiry time.Duration
+
+	mu    sync.Mutex
+	token *Token
+}
+
+func (s *cachingTokenSource) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fresh(s.token) {
+		return s.token, nil
+	}
+	t, err := s.src.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if t.Expiry.IsZero() {
+		t = &Token{Value: t.Value, Expiry: jwtExpiry(t.Value)}
+	}
+	s.token = t
+	return t, nil
+}
+
+func (s *cachingTokenSource) fresh(t *Token) bool {
+	if t == nil || t.Value == "" {
+		return false
+	}
+	return t.Expiry.IsZero() || time.Until(t.Expiry) > s.earlyExpiry
+}
+
+// jwtExpiry returns the exp claim of a JWT, or the zero time for opaque
+// tokens and JWTs without one.
+func jwtExpiry(token string) time.Time {
+	if strings.Count(token, ".") != 2 {
+		return time.Time{}
+	}
+	claims, err := ParseJWTToken(token)
+	if err != nil || claims.ExpirationTime == 0 {
+		return time.Time{}
+	}
+	return time.Unix(claims.ExpirationTime, 0)
+}
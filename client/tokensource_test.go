@@ -0,0 +1,157 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEnvTokenSource(t *testing.T) {
+	t.Setenv("ADMIRAL_TEST_TOKEN", "first-token-value")
+	src := EnvTokenSource("ADMIRAL_TEST_TOKEN")
+
+	tok, err := src.Token(context.Background())
+	if err != nil || tok.Value != "first-token-value" {
+		t.Fatalf("Token() = %v, %v, want first-token-value", tok, err)
+	}
+
+	t.Setenv("ADMIRAL_TEST_TOKEN", "second-token-value")
+	if tok, _ := src.Token(context.Background()); tok.Value != "second-token-value" {
+		t.Errorf("Token() = %q after update, want second-token-value", tok.Value)
+	}
+
+	t.Setenv("ADMIRAL_TEST_TOKEN", "")
+	if _, err := src.Token(context.Background()); !errors.Is(err, ErrNoToken) {
+		t.Errorf("Token() error = %v, want ErrNoToken", err)
+	}
+}
+
+func TestFileTokenSource_FollowsRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	write := func(value string, mtime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(value+"\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Now()
+	write("token-one", now.Add(-time.Minute))
+	src := FileTokenSource(path)
+
+	tok, err := src.Token(context.Background())
+	if err != nil || tok.Value != "token-one" {
+		t.Fatalf("Token() = %v, %v, want token-one", tok, err)
+	}
+
+	write("token-two", now)
+	if tok, _ := src.Token(context.Background()); tok.Value != "token-two" {
+		t.Errorf("Token() = %q after rotation, want token-two", tok.Value)
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := src.Token(context.Background()); err == nil {
+		t.Error("Token() succeeded after the file was removed")
+	}
+}
+
+func TestCachingTokenSource_RefreshesBeforeExpiry(t *testing.T) {
+	var calls atomic.Int32
+	expiry := time.Now().Add(time.Hour)
+	src := CachingTokenSource(TokenSourceFunc(func(context.Context) (*Token, error) {
+		calls.Add(1)
+		return &Token{Value: "refreshed-token", Expiry: expiry}, nil
+	}), time.Minute)
+
+	for range 3 {
+		if _, err := src.Token(context.Background()); err != nil {
+			t.Fatalf("Token() error = %v", err)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("calls = %d, want 1 while the token is fresh", calls.Load())
+	}
+
+	expiry = time.Now().Add(30 * time.Second)
+	src.(*cachingTokenSource).token.Expiry = expiry
+	if _, err := src.Token(context.Background()); err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("calls = %d, want 2 once within the early expiry window", calls.Load())
+	}
+}
+
+func TestCachingTokenSource_UsesJWTExpiry(t *testing.T) {
+	jwt := createTestJWT(JWTClaims{ExpirationTime: time.Now().Add(-time.Minute).Unix()})
+	src := CachingTokenSource(StaticTokenSource(jwt), 0)
+
+	tok, err := src.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if tok.Valid() {
+		t.Error("Valid() = true for a JWT whose exp has passed")
+	}
+}
+
+func TestTokenAuth_FetchesPerRPC(t *testing.T) {
+	var n atomic.Int32
+	ta := tokenAuth{source: TokenSourceFunc(func(context.Context) (*Token, error) {
+		if n.Add(1) == 1 {
+			return &Token{Value: "old"}, nil
+		}
+		return &Token{Value: "new"}, nil
+	})}
+
+	first, _ := ta.GetRequestMetadata(context.Background())
+	second, _ := ta.GetRequestMetadata(context.Background())
+	if first["Authorization"] != "Bearer old" || second["Authorization"] != "Bearer new" {
+		t.Errorf("Authorization = %q then %q, want Bearer old then Bearer new", first["Authorization"], second["Authorization"])
+	}
+}
+
+func TestTokenAuth_RejectsExpiredToken(t *testing.T) {
+	ta := tokenAuth{source: StaticTokenSource(createTestJWT(JWTClaims{ExpirationTime: time.Now().Add(-time.Minute).Unix()}))}
+	if _, err := ta.GetRequestMetadata(context.Background()); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("GetRequestMetadata() error = %v, want ErrTokenExpired", err)
+	}
+}
+
+func TestConfig_TokenSourceExclusive(t *testing.T) {
+	cfg := Config{
+		AuthToken:   "this-is-a-valid-opaque-token-12345",
+		TokenSource: StaticTokenSource("another-token-value"),
+	}
+	if err := cfg.CheckAndSetDefaults(); err == nil {
+		t.Error("CheckAndSetDefaults() accepted both AuthToken and TokenSource")
+	}
+
+	cfg = Config{TokenSource: EnvTokenSource("ADMIRAL_UNSET_TOKEN")}
+	if err := cfg.CheckAndSetDefaults(); err != nil {
+		t.Errorf("CheckAndSetDefaults() error = %v, want lazy token fetch", err)
+	}
+}
+
+func TestCommandTokenSource(t *testing.T) {
+	tok, err := CommandTokenSource("echo", "command-token-value").Token(context.Background())
+	if err != nil || tok.Value != "command-token-value" {
+		t.Fatalf("Token() = %v, %v, want command-token-value", tok, err)
+	}
+
+	if _, err := CommandTokenSource("true").Token(context.Background()); !errors.Is(err, ErrNoToken) {
+		t.Errorf("Token() with no output error = %v, want ErrNoToken", err)
+	}
+	if _, err := CommandTokenSource("sh", "-c", "echo denied >&2; exit 1").Token(context.Background()); err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("Token() of failing command error = %v, want its stderr", err)
+	}
+}
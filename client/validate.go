@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"connectrpc.com/connect"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"go.admiral.io/sdk/filter"
+)
+
+// validationMessage runs the generated ValidateAll method of req, if it has
+// one, and checks the Filter of List requests with filter.ValidateRequest.
+// It returns the error message and field violations, or an empty message
+// when req is valid.
+func validationMessage(req any) (string, *errdetails.BadRequest) {
+	var violations []*errdetails.BadRequest_FieldViolation
+	if v, ok := req.(interface{ ValidateAll() error }); ok {
+		if err := v.ValidateAll(); err != nil {
+			violations = fieldViolations("", err)
+		}
+	}
+	if m, ok := req.(proto.Message); ok {
+		if err := filter.ValidateRequest(m); err != nil {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: "Filter", Description: err.Error()})
+		}
+	}
+	if len(violations) == 0 {
+		return "", nil
+	}
+	msgs := make([]string, len(violations))
+	for i, fv := range violations {
+		msgs[i] = fv.GetField() + ": " + fv.GetDescription()
+	}
+	return "invalid request: " + strings.Join(msgs, "; "), &errdetails.BadRequest{FieldViolations: violations}
+}
+
+// fieldViolations flattens the errors returned by a generated ValidateAll
+// method. Violations in embedded messages are reported against the full
+// field path, such as "Cluster.DisplayName".
+func fieldViolations(prefix string, err error) []*errdetails.BadRequest_FieldViolation {
+	var multi interface{ AllErrors() []error }
+	if errors.As(err, &multi) {
+		var out []*errdetails.BadRequest_FieldViolation
+		for _, e := range multi.AllErrors() {
+			out = append(out, fieldViolations(prefix, e)...)
+		}
+		return out
+	}
+
+	var fe interface {
+		Field() string
+		Reason() string
+		Cause() error
+	}
+	if !errors.As(err, &fe) {
+		return []*errdetails.BadRequest_FieldViolation{{Field: prefix, Description: err.Error()}}
+	}
+	field := fe.Field()
+	if prefix != "" {
+		field = prefix + "." + field
+	}
+	if cause := fe.Cause(); cause != nil {
+		if nested := fieldViolations(field, cause); len(nested) > 0 {
+			return nested
+		}
+	}
+	return []*errdetails.BadRequest_FieldViolation{{Field: field, Description: fe.Reason()}}
+}
+
+// validationError returns an InvalidArgument status error for an invalid
+// request, or nil.
+func validationError(req any) error {
+	msg, details := validationMessage(req)
+	if msg == "" {
+		return nil
+	}
+	st := status.New(codes.InvalidArgument, msg)
+	if withDetails, err := st.WithDetails(details); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// validateUnaryInterceptor rejects invalid requests before they are sent.
+func validateUnaryInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if err := validationError(req); err != nil {
+		return err
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// validateConnectInterceptor rejects invalid requests before they are sent.
+func validateConnectInterceptor() connect.UnaryInterceptorFunc {
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			if err := validationError(req.Any()); err != nil {
+				return nil, statusToConnectError(status.Convert(err))
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// validateConn rejects invalid requests before they are sent, for the REST
+// transport.
+type validateConn struct {
+	grpc.ClientConnInterface
+}
+
+func (c *validateConn) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	if err := validationError(args); err != nil {
+		return err
+	}
+	return c.ClientConnInterface.Invoke(ctx, method, args, reply, opts...)
+}
@@ -0,0 +1,126 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"connectrpc.com/connect"
+	"google.golang.org/grpc"
+
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+)
+
+// testViolation and testMultiError mimic the errors returned by the
+// generated ValidateAll methods.
+type testViolation struct {
+	field, reason string
+	cause         error
+}
+
+func (v testViolation) Field() string  { return v.field }
+func (v testViolation) Reason() string { return v.reason }
+func (v testViolation) Cause() error   { return v.cause }
+func (v testViolation) Error() string  { return "invalid " + v.field + ": " + v.reason }
+
+type testMultiError []error
+
+func (m testMultiError) Error() string      { return "multiple violations" }
+func (m testMultiError) AllErrors() []error { return m }
+
+type invalidRequest struct{}
+
+func (invalidRequest) ValidateAll() error {
+	return testMultiError{
+		testViolation{field: "DisplayName", reason: "value length must be at least 1 runes"},
+		testViolation{field: "Cluster", reason: "embedded message failed validation", cause: testMultiError{
+			testViolation{field: "Labels[env]", reason: "value must not be empty"},
+			testViolation{field: "Id", reason: "value must be a valid UUID"},
+		}},
+	}
+}
+
+func wantViolations(t *testing.T, err error) {
+	t.Helper()
+	var e *Error
+	if !errors.Is(err, ErrInvalidArgument) || !errors.As(err, &e) {
+		t.Fatalf("error = %v, want ErrInvalidArgument", err)
+	}
+	var fields []string
+	for _, fv := range e.FieldViolations {
+		fields = append(fields, fv.GetField())
+	}
+	if got := strings.Join(fields, ","); got != "DisplayName,Cluster.Labels[env],Cluster.Id" {
+		t.Errorf("violated fields = %s", got)
+	}
+	if !strings.Contains(e.Message, "Cluster.Id: value must be a valid UUID") {
+		t.Errorf("Message = %q, want every violation listed", e.Message)
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	wantViolations(t, wrapError(validationError(invalidRequest{})))
+	if err := validationError(&clusterv1.GetClusterRequest{}); err != nil {
+		t.Errorf("validationError(valid) = %v", err)
+	}
+	if err := validationError("not a message"); err != nil {
+		t.Errorf("validationError(non-message) = %v", err)
+	}
+}
+
+func TestValidateInterceptors(t *testing.T) {
+	sent := false
+	t.Run("grpc", func(t *testing.T) {
+		invoker := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+			sent = true
+			return nil
+		}
+		err := validateUnaryInterceptor(context.Background(), "/m", invalidRequest{}, nil, nil, invoker)
+		wantViolations(t, wrapError(err))
+	})
+	t.Run("connect", func(t *testing.T) {
+		next := func(context.Context, connect.AnyRequest) (connect.AnyResponse, error) {
+			sent = true
+			return nil, nil
+		}
+		_, err := validateConnectInterceptor()(next)(context.Background(), connect.NewRequest(&invalidRequest{}))
+		wantViolations(t, wrapError(connectErrorToStatus(err)))
+	})
+	t.Run("rest", func(t *testing.T) {
+		conn := &validateConn{ClientConnInterface: &restConn{}}
+		wantViolations(t, wrapError(conn.Invoke(context.Background(), "/m", invalidRequest{}, nil)))
+	})
+	if sent {
+		t.Error("invalid request was sent")
+	}
+}
+
+func TestValidateRequests_ValidPassesThrough(t *testing.T) {
+	c := newTestRESTClient(t, &testClusterServer{}, func(cfg *Config) {
+		cfg.ConnectionOptions.ValidateRequests = true
+	})
+	if _, err := c.Cluster().GetCluster(context.Background(), &clusterv1.GetClusterRequest{ClusterId: "c1"}); err != nil {
+		t.Errorf("GetCluster() error = %v", err)
+	}
+}
+
+func TestValidateRequests_Filter(t *testing.T) {
+	srv := &testClusterServer{}
+	c := newTestRESTClient(t, srv, func(cfg *Config) {
+		cfg.ConnectionOptions.ValidateRequests = true
+	})
+
+	_, err := c.Cluster().ListClusters(context.Background(), &clusterv1.ListClustersRequest{Filter: `colour = "red"`})
+	var e *Error
+	if !errors.Is(err, ErrInvalidArgument) || !errors.As(err, &e) || len(e.FieldViolations) != 1 || e.FieldViolations[0].GetField() != "Filter" {
+		t.Fatalf("ListClusters() error = %v, want ErrInvalidArgument for Filter", err)
+	}
+	if srv.lastList != nil {
+		t.Error("request with an invalid filter was sent")
+	}
+
+	if _, err := c.Cluster().ListClusters(context.Background(), &clusterv1.ListClustersRequest{Filter: `display_name = "prod"`}); err != nil {
+		t.Errorf("ListClusters() with a valid filter error = %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/proto"
+
+	"go.admiral.io/sdk/client"
+	agentv1 "go.admiral.io/sdk/proto/agent/v1"
+)
+
+func newAgentCommand(o *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "agent",
+		Aliases: []string{"agents"},
+		Short:   "Inspect agents",
+	}
+
+	var lo listOptions
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List agents",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return o.run(cmd, func(ctx context.Context, c *client.Client) error {
+				return printList(cmd, o, client.ListAgents(ctx, c.Agent(), lo.options()...))
+			})
+		},
+	}
+	lo.addFlags(list)
+
+	cmd.AddCommand(
+		idCommand(o, "get ID", "Show an agent", func(ctx context.Context, c *client.Client, id string) (proto.Message, error) {
+			return c.Agent().GetAgent(ctx, &agentv1.GetAgentRequest{AgentId: id})
+		}),
+		list,
+	)
+	return cmd
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"iter"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.admiral.io/sdk/client"
+	accesstokenv1 "go.admiral.io/sdk/proto/accesstoken/v1"
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+)
+
+func newClusterCommand(o *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "cluster",
+		Aliases: []string{"clusters"},
+		Short:   "Manage clusters",
+	}
+
+	var labels map[string]string
+	create := &cobra.Command{
+		Use:   "create NAME",
+		Short: "Create a cluster and print its agent token",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(cmd, func(ctx context.Context, c *client.Client) error {
+				resp, err := c.Cluster().CreateCluster(ctx, &clusterv1.CreateClusterRequest{DisplayName: args[0], Labels: labels})
+				if err != nil {
+					return err
+				}
+				return o.print(cmd, resp)
+			})
+		},
+	}
+	create.Flags().StringToStringVar(&labels, "label", nil, "label as key=value (repeatable)")
+
+	var lo listOptions
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List clusters",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return o.run(cmd, func(ctx context.Context, c *client.Client) error {
+				return printList(cmd, o, client.ListClusters(ctx, c.Cluster(), lo.options()...))
+			})
+		},
+	}
+	lo.addFlags(list)
+
+	var (
+		name        string
+		newLabels   map[string]string
+		clearLabels bool
+	)
+	update := &cobra.Command{
+		Use:   "update ID",
+		Short: "Rename a cluster or replace its labels",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cluster := &clusterv1.Cluster{Id: args[0]}
+			mask := &fieldmaskpb.FieldMask{}
+			if cmd.Flags().Changed("name") {
+				cluster.DisplayName = name
+				mask.Paths = append(mask.Paths, "display_name")
+			}
+			if cmd.Flags().Changed("label") || clearLabels {
+				cluster.Labels = newLabels
+				mask.Paths = append(mask.Paths, "labels")
+			}
+			if len(mask.Paths) == 0 {
+				return errNothingToUpdate
+			}
+			return o.run(cmd, func(ctx context.Context, c *client.Client) error {
+				resp, err := c.Cluster().UpdateCluster(ctx, &clusterv1.UpdateClusterRequest{Cluster: cluster, UpdateMask: mask})
+				if err != nil {
+					return err
+				}
+				return o.print(cmd, resp)
+			})
+		},
+	}
+	update.Flags().StringVar(&name, "name", "", "new display name")
+	update.Flags().StringToStringVar(&newLabels, "label", nil, "replacement label as key=value (repeatable)")
+	update.Flags().BoolVar(&clearLabels, "clear-labels", false, "remove all labels")
+
+	var wo listOptions
+	workloads := &cobra.Command{
+		Use:   "workloads ID",
+		Short: "List the workloads reported for a cluster",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(cmd, func(ctx context.Context, c *client.Client) error {
+				return printList(cmd, o, client.ListWorkloads(ctx, c.Cluster(), args[0], wo.options()...))
+			})
+		},
+	}
+	wo.addFlags(workloads)
+
+	tokens := &tokenCommands{
+		owner: "CLUSTER_ID",
+		create: func(ctx context.Context, c *client.Client, clusterID, name string, _ []string, expiresAt *timestamppb.Timestamp) (proto.Message, error) {
+			return c.Cluster().CreateClusterToken(ctx, &clusterv1.CreateClusterTokenRequest{ClusterId: clusterID, DisplayName: name, ExpiresAt: expiresAt})
+		},
+		list: func(ctx context.Context, c *client.Client, clusterID string, opts []client.ListOption) iter.Seq2[*accesstokenv1.AccessToken, error] {
+			return client.ListClusterTokens(ctx, c.Cluster(), clusterID, opts...)
+		},
+		get: func(ctx context.Context, c *client.Client, clusterID, tokenID string) (proto.Message, error) {
+			return c.Cluster().GetClusterToken(ctx, &clusterv1.GetClusterTokenRequest{ClusterId: clusterID, TokenId: tokenID})
+		},
+		revoke: func(ctx context.Context, c *client.Client, clusterID, tokenID string) (proto.Message, error) {
+			return c.Cluster().RevokeClusterToken(ctx, &clusterv1.RevokeClusterTokenRequest{ClusterId: clusterID, TokenId: tokenID})
+		},
+	}
+
+	cmd.AddCommand(
+		create,
+		idCommand(o, "get ID", "Show a cluster", func(ctx context.Context, c *client.Client, id string) (proto.Message, error) {
+			return c.Cluster().GetCluster(ctx, &clusterv1.GetClusterRequest{ClusterId: id})
+		}),
+		list,
+		update,
+		idCommand(o, "delete ID", "Delete a cluster and revoke its agent tokens", func(ctx context.Context, c *client.Client, id string) (proto.Message, error) {
+			return c.Cluster().DeleteCluster(ctx, &clusterv1.DeleteClusterRequest{ClusterId: id})
+		}),
+		idCommand(o, "status ID", "Show the latest status reported for a cluster", func(ctx context.Context, c *client.Client, id string) (proto.Message, error) {
+			return c.Cluster().GetClusterStatus(ctx, &clusterv1.GetClusterStatusRequest{ClusterId: id})
+		}),
+		workloads,
+		tokens.command(o, "token", "Manage cluster agent tokens"),
+	)
+	return cmd
+}
@@ -0,0 +1,44 @@
+// Command admiral manages Admiral resources from the command line.
+//
+// Usage:
+//
+//	admiral [--profile name] [--host host:port] [--token token] [--insecure] <command>
+//
+// Connection settings come from a profile in ~/.config/admiral/config (see
+// client.LoadConfig), then the ADMIRAL_HOST and ADMIRAL_TOKEN environment
+// variables, then the flags.
+//
+// Commands:
+//
+//	cluster          create, get, list, update and delete clusters, show
+//	                 their status and workloads, and manage agent tokens
+//	runner           create, get, list, update and delete runners, and
+//	                 manage agent tokens
+//	agent            get and list agents
+//	service-account  create, get, list, update and delete service accounts,
+//	                 and manage their tokens
+//	token            create, get, list and revoke personal access tokens
+//
+// List commands walk every page; --page-size sets how many items are
+// fetched per request and --filter takes a filter expression such as
+// 'health_status = "healthy"'. Results are printed as a table; -o selects
+// json, yaml, csv, template=<tmpl> or jsonpath=<expr> instead.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := newRootCommand().ExecuteContext(ctx)
+	stop()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "admiral:", err)
+		os.Exit(1)
+	}
+}
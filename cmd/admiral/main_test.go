@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.admiral.io/sdk/admiraltest"
+	"go.admiral.io/sdk/client"
+)
+
+// admiral runs the CLI against srv and returns its output.
+func admiral(t *testing.T, srv *admiraltest.Server, args ...string) (string, error) {
+	t.Helper()
+	isolateConfig(t)
+	cmd := newRootCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(append([]string{"--host", srv.Addr(), "--insecure", "--token", admiraltest.DefaultAuthToken, "-o", "json"}, args...))
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+// isolateConfig keeps the CLI from reading the user's config file and
+// ADMIRAL_* variables.
+func isolateConfig(t *testing.T) {
+	t.Helper()
+	t.Setenv("ADMIRAL_CONFIG", filepath.Join(t.TempDir(), "config"))
+	for _, name := range []string{"ADMIRAL_PROFILE", "ADMIRAL_HOST", "ADMIRAL_TOKEN"} {
+		t.Setenv(name, "")
+	}
+}
+
+func mustAdmiral(t *testing.T, srv *admiraltest.Server, args ...string) map[string]any {
+	t.Helper()
+	out, err := admiral(t, srv, args...)
+	if err != nil {
+		t.Fatalf("admiral %s: %v", strings.Join(args, " "), err)
+	}
+	var v map[string]any
+	if err := json.Unmarshal([]byte(out), &v); err != nil {
+		t.Fatalf("admiral %s: output is not a JSON object: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return v
+}
+
+func TestClusterCommands(t *testing.T) {
+	srv, _ := admiraltest.Start(t, admiraltest.WithLoopback())
+
+	created := mustAdmiral(t, srv, "cluster", "create", "prod", "--label", "env=prod")
+	if created["plainTextToken"] == "" {
+		t.Errorf("create printed no agent token: %v", created)
+	}
+	id := created["cluster"].(map[string]any)["id"].(string)
+	mustAdmiral(t, srv, "cluster", "create", "staging", "--label", "env=staging")
+
+	out, err := admiral(t, srv, "cluster", "list", "--filter", `labels.env = "prod"`, "--page-size", "1")
+	if err != nil {
+		t.Fatalf("cluster list: %v", err)
+	}
+	var clusters []map[string]any
+	if err := json.Unmarshal([]byte(out), &clusters); err != nil {
+		t.Fatalf("cluster list output is not a JSON array: %v\n%s", err, out)
+	}
+	if len(clusters) != 1 || clusters[0]["id"] != id {
+		t.Errorf("cluster list = %v, want only %s", clusters, id)
+	}
+
+	updated := mustAdmiral(t, srv, "cluster", "update", id, "--name", "production")
+	if got := updated["cluster"].(map[string]any)["displayName"]; got != "production" {
+		t.Errorf("displayName = %v, want production", got)
+	}
+	if _, err := admiral(t, srv, "cluster", "update", id); err != errNothingToUpdate {
+		t.Errorf("update without flags error = %v, want errNothingToUpdate", err)
+	}
+
+	token := mustAdmiral(t, srv, "cluster", "token", "create", id, "ci", "--expires-in", "24h")
+	tokenID := token["accessToken"].(map[string]any)["id"].(string)
+	mustAdmiral(t, srv, "cluster", "token", "revoke", id, tokenID)
+
+	mustAdmiral(t, srv, "cluster", "delete", id)
+	if _, err := admiral(t, srv, "cluster", "get", id); err == nil {
+		t.Error("cluster get after delete succeeded")
+	}
+}
+
+func TestRunnerAndTokenCommands(t *testing.T) {
+	srv, _ := admiraltest.Start(t, admiraltest.WithLoopback())
+
+	runner := mustAdmiral(t, srv, "runner", "create", "tf", "--kind", "terraform")
+	if got := runner["runner"].(map[string]any)["kind"]; got != "RUNNER_KIND_TERRAFORM" {
+		t.Errorf("kind = %v, want RUNNER_KIND_TERRAFORM", got)
+	}
+	if _, err := admiral(t, srv, "runner", "create", "x", "--kind", "ansible"); err == nil || !strings.Contains(err.Error(), "terraform, workflow") {
+		t.Errorf("bad --kind error = %v, want the valid kinds listed", err)
+	}
+
+	pat := mustAdmiral(t, srv, "token", "create", "laptop", "--scope", "clusters:read")
+	tokenID := pat["accessToken"].(map[string]any)["id"].(string)
+	got := mustAdmiral(t, srv, "token", "get", tokenID)
+	if got["accessToken"].(map[string]any)["displayName"] != "laptop" {
+		t.Errorf("token get = %v", got)
+	}
+}
+
+func TestOutputFormats(t *testing.T) {
+	srv, _ := admiraltest.Start(t, admiraltest.WithLoopback())
+	mustAdmiral(t, srv, "cluster", "create", "prod")
+
+	out, err := admiral(t, srv, "cluster", "list", "-o", "table")
+	if err != nil {
+		t.Fatalf("cluster list: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "ID ") || !strings.Contains(lines[1], "prod") {
+		t.Errorf("table output =\n%s", out)
+	}
+
+	out, err = admiral(t, srv, "cluster", "list", "-o", "jsonpath={[*].displayName}")
+	if err != nil || out != "prod" {
+		t.Errorf("jsonpath output = %q, %v, want prod", out, err)
+	}
+
+	if _, err := admiral(t, srv, "cluster", "list", "-o", "xml"); err == nil {
+		t.Error("-o xml error = nil")
+	}
+}
+
+func TestProfileConfig(t *testing.T) {
+	srv, _ := admiraltest.Start(t, admiraltest.WithLoopback())
+	isolateConfig(t)
+	config := filepath.Join(t.TempDir(), "config")
+	contents := fmt.Sprintf("current-profile: test\nprofiles:\n  test:\n    host: %s\n    token: %s\n    insecure: true\n",
+		srv.Addr(), admiraltest.DefaultAuthToken)
+	if err := os.WriteFile(config, []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) error {
+		cmd := newRootCommand()
+		cmd.SetOut(io.Discard)
+		cmd.SetArgs(args)
+		return cmd.Execute()
+	}
+	if err := run("--config", config, "cluster", "list"); err != nil {
+		t.Errorf("cluster list with profile: %v", err)
+	}
+	if err := run("--config", config, "--profile", "prod", "cluster", "list"); !errors.Is(err, client.ErrProfileNotFound) {
+		t.Errorf("unknown --profile error = %v, want ErrProfileNotFound", err)
+	}
+
+	// Flags override the profile.
+	t.Setenv("ADMIRAL_CONFIG", config)
+	if err := run("--token", "short", "cluster", "list"); err == nil || !strings.Contains(err.Error(), "too short") {
+		t.Errorf("--token override error = %v, want the flag's token rejected", err)
+	}
+}
+
+func TestTransportFlag(t *testing.T) {
+	srv, _ := admiraltest.Start(t, admiraltest.WithLoopback())
+	// The test server speaks gRPC only, so the other transports may fail to
+	// connect, but must not be rejected.
+	for _, s := range []string{"grpc", "Connect", "REST"} {
+		if _, err := admiral(t, srv, "--transport", s, "cluster", "list"); err != nil && strings.Contains(err.Error(), "unknown transport") {
+			t.Errorf("--transport %s: %v", s, err)
+		}
+	}
+	if _, err := admiral(t, srv, "--transport", "http", "cluster", "list"); err == nil || !strings.Contains(err.Error(), "unknown transport") {
+		t.Errorf("--transport http error = %v, want unknown transport", err)
+	}
+}
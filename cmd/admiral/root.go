@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.admiral.io/sdk/client"
+	"go.admiral.io/sdk/printer"
+)
+
+// errNothingToUpdate is returned by update commands given no field flags.
+var errNothingToUpdate = errors.New("nothing to update: set at least one field flag")
+
+// globalOptions are the flags shared by every command.
+type globalOptions struct {
+	config    string
+	profile   string
+	host      string
+	token     string
+	insecure  bool
+	transport string
+	output    string
+
+	printer *printer.Printer
+}
+
+func newRootCommand() *cobra.Command {
+	o := &globalOptions{}
+	cmd := &cobra.Command{
+		Use:           "admiral",
+		Short:         "Manage Admiral clusters, runners and tokens",
+		Version:       client.Version(),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(*cobra.Command, []string) error {
+			p, err := printer.New(o.output)
+			o.printer = p
+			return err
+		},
+	}
+	flags := cmd.PersistentFlags()
+	flags.StringVar(&o.config, "config", "", "config file (default $ADMIRAL_CONFIG or ~/.config/admiral/config)")
+	flags.StringVar(&o.profile, "profile", "", "config file profile (default $ADMIRAL_PROFILE or the file's current-profile)")
+	flags.StringVar(&o.host, "host", "", "API endpoint as host:port or unix:///path, overriding the profile and $ADMIRAL_HOST")
+	flags.StringVar(&o.token, "token", "", "API token, overriding the profile and $ADMIRAL_TOKEN")
+	flags.BoolVar(&o.insecure, "insecure", false, "connect without TLS")
+	flags.StringVar(&o.transport, "transport", client.TransportGRPC.String(), "wire protocol: grpc, connect or rest")
+	flags.StringVarP(&o.output, "output", "o", printer.FormatTable, "output format: table, json, yaml, csv, template=<tmpl> or jsonpath=<expr>")
+
+	cmd.AddCommand(
+		newClusterCommand(o),
+		newRunnerCommand(o),
+		newAgentCommand(o),
+		newServiceAccountCommand(o),
+		newTokenCommand(o),
+	)
+	return cmd
+}
+
+// run calls fn with a client built from the config file profile and the
+// environment, overridden by any global flags given, and closes it
+// afterwards.
+func (o *globalOptions) run(cmd *cobra.Command, fn func(ctx context.Context, c *client.Client) error) error {
+	cfg, err := client.LoadConfig(o.config, o.profile)
+	if err != nil {
+		return err
+	}
+	flags := cmd.Flags()
+	if flags.Changed("host") {
+		cfg.HostPort, cfg.Endpoints = o.host, nil
+	}
+	if flags.Changed("token") {
+		cfg.AuthToken, cfg.TokenSource = o.token, nil
+	}
+	if flags.Changed("insecure") {
+		cfg.ConnectionOptions.Insecure = o.insecure
+	}
+	if flags.Changed("transport") {
+		var t client.Transport
+		if err := t.UnmarshalText([]byte(o.transport)); err != nil {
+			return err
+		}
+		cfg.Transport = t
+	}
+	ctx := cmd.Context()
+	c, err := client.New(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = c.Close() }()
+	return fn(ctx, c)
+}
+
+// parseEnum maps a short enum name such as "terraform" to its value, given
+// the generated name-to-value map and the value prefix, e.g. "RUNNER_KIND_".
+func parseEnum(s, prefix string, values map[string]int32) (int32, error) {
+	if v, ok := values[prefix+strings.ToUpper(s)]; ok && v != 0 {
+		return v, nil
+	}
+	var names []string
+	for name, v := range values {
+		if v != 0 {
+			names = append(names, strings.ToLower(strings.TrimPrefix(name, prefix)))
+		}
+	}
+	slices.Sort(names)
+	return 0, fmt.Errorf("unknown value %q (want %s)", s, strings.Join(names, ", "))
+}
+
+// listOptions are the flags shared by list commands.
+type listOptions struct {
+	filter   string
+	pageSize int32
+}
+
+func (l *listOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&l.filter, "filter", "", "filter expression, e.g. 'display_name = \"prod\"'")
+	cmd.Flags().Int32Var(&l.pageSize, "page-size", 0, "items fetched per request (server default if 0)")
+}
+
+func (l *listOptions) options() []client.ListOption {
+	var opts []client.ListOption
+	if l.filter != "" {
+		opts = append(opts, client.WithFilter(l.filter))
+	}
+	if l.pageSize > 0 {
+		opts = append(opts, client.WithPageSize(l.pageSize))
+	}
+	return opts
+}
+
+// expiresAt converts an --expires-in duration to a timestamp, or nil for a
+// token that does not expire.
+func expiresAt(d time.Duration) *timestamppb.Timestamp {
+	if d <= 0 {
+		return nil
+	}
+	return timestamppb.New(time.Now().Add(d))
+}
+
+// print writes m in the --output format.
+func (o *globalOptions) print(cmd *cobra.Command, m proto.Message) error {
+	return o.printer.Print(cmd.OutOrStdout(), m)
+}
+
+// printList drains seq and writes the items in the --output format.
+func printList[T proto.Message](cmd *cobra.Command, o *globalOptions, seq iter.Seq2[T, error]) error {
+	items, err := client.CollectAll(seq)
+	if err != nil {
+		return err
+	}
+	return o.printer.PrintList(cmd.OutOrStdout(), printer.Messages(items))
+}
+
+// idCommand returns a command that calls fn with its ID argument and prints
+// the response.
+func idCommand(o *globalOptions, use, short string, fn func(ctx context.Context, c *client.Client, id string) (proto.Message, error)) *cobra.Command {
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(cmd, func(ctx context.Context, c *client.Client) error {
+				resp, err := fn(ctx, c, args[0])
+				if err != nil {
+					return err
+				}
+				return o.print(cmd, resp)
+			})
+		},
+	}
+}
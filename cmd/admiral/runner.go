@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"iter"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.admiral.io/sdk/client"
+	accesstokenv1 "go.admiral.io/sdk/proto/accesstoken/v1"
+	runnerv1 "go.admiral.io/sdk/proto/runner/v1"
+)
+
+func newRunnerCommand(o *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "runner",
+		Aliases: []string{"runners"},
+		Short:   "Manage runners",
+	}
+
+	var (
+		kind   string
+		labels map[string]string
+	)
+	create := &cobra.Command{
+		Use:   "create NAME",
+		Short: "Create a runner and print its agent token",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			k, err := parseEnum(kind, "RUNNER_KIND_", runnerv1.RunnerKind_value)
+			if err != nil {
+				return err
+			}
+			return o.run(cmd, func(ctx context.Context, c *client.Client) error {
+				resp, err := c.Runner().CreateRunner(ctx, &runnerv1.CreateRunnerRequest{
+					DisplayName: args[0],
+					Kind:        runnerv1.RunnerKind(k),
+					Labels:      labels,
+				})
+				if err != nil {
+					return err
+				}
+				return o.print(cmd, resp)
+			})
+		},
+	}
+	create.Flags().StringVar(&kind, "kind", "", "runner kind: terraform or workflow")
+	create.Flags().StringToStringVar(&labels, "label", nil, "label as key=value (repeatable)")
+	_ = create.MarkFlagRequired("kind")
+
+	var lo listOptions
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List runners",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return o.run(cmd, func(ctx context.Context, c *client.Client) error {
+				return printList(cmd, o, client.ListRunners(ctx, c.Runner(), lo.options()...))
+			})
+		},
+	}
+	lo.addFlags(list)
+
+	var (
+		name        string
+		newLabels   map[string]string
+		clearLabels bool
+	)
+	update := &cobra.Command{
+		Use:   "update ID",
+		Short: "Rename a runner or replace its labels",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			runner := &runnerv1.Runner{Id: args[0]}
+			mask := &fieldmaskpb.FieldMask{}
+			if cmd.Flags().Changed("name") {
+				runner.DisplayName = name
+				mask.Paths = append(mask.Paths, "display_name")
+			}
+			if cmd.Flags().Changed("label") || clearLabels {
+				runner.Labels = newLabels
+				mask.Paths = append(mask.Paths, "labels")
+			}
+			if len(mask.Paths) == 0 {
+				return errNothingToUpdate
+			}
+			return o.run(cmd, func(ctx context.Context, c *client.Client) error {
+				resp, err := c.Runner().UpdateRunner(ctx, &runnerv1.UpdateRunnerRequest{Runner: runner, UpdateMask: mask})
+				if err != nil {
+					return err
+				}
+				return o.print(cmd, resp)
+			})
+		},
+	}
+	update.Flags().StringVar(&name, "name", "", "new display name")
+	update.Flags().StringToStringVar(&newLabels, "label", nil, "replacement label as key=value (repeatable)")
+	update.Flags().BoolVar(&clearLabels, "clear-labels", false, "remove all labels")
+
+	tokens := &tokenCommands{
+		owner: "RUNNER_ID",
+		create: func(ctx context.Context, c *client.Client, runnerID, name string, _ []string, expiresAt *timestamppb.Timestamp) (proto.Message, error) {
+			return c.Runner().CreateRunnerToken(ctx, &runnerv1.CreateRunnerTokenRequest{RunnerId: runnerID, DisplayName: name, ExpiresAt: expiresAt})
+		},
+		list: func(ctx context.Context, c *client.Client, runnerID string, opts []client.ListOption) iter.Seq2[*accesstokenv1.AccessToken, error] {
+			return client.ListRunnerTokens(ctx, c.Runner(), runnerID, opts...)
+		},
+		get: func(ctx context.Context, c *client.Client, runnerID, tokenID string) (proto.Message, error) {
+			return c.Runner().GetRunnerToken(ctx, &runnerv1.GetRunnerTokenRequest{RunnerId: runnerID, TokenId: tokenID})
+		},
+		revoke: func(ctx context.Context, c *client.Client, runnerID, tokenID string) (proto.Message, error) {
+			return c.Runner().RevokeRunnerToken(ctx, &runnerv1.RevokeRunnerTokenRequest{RunnerId: runnerID, TokenId: tokenID})
+		},
+	}
+
+	cmd.AddCommand(
+		create,
+		idCommand(o, "get ID", "Show a runner", func(ctx context.Context, c *client.Client, id string) (proto.Message, error) {
+			return c.Runner().GetRunner(ctx, &runnerv1.GetRunnerRequest{RunnerId: id})
+		}),
+		list,
+		update,
+		idCommand(o, "delete ID", "Delete a runner and revoke its agent tokens", func(ctx context.Context, c *client.Client, id string) (proto.Message, error) {
+			return c.Runner().DeleteRunner(ctx, &runnerv1.DeleteRunnerRequest{RunnerId: id})
+		}),
+		tokens.command(o, "token", "Manage runner agent tokens"),
+	)
+	return cmd
+}
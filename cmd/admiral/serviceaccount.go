@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"iter"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.admiral.io/sdk/client"
+	accesstokenv1 "go.admiral.io/sdk/proto/accesstoken/v1"
+	serviceaccountv1 "go.admiral.io/sdk/proto/serviceaccount/v1"
+)
+
+func newServiceAccountCommand(o *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "service-account",
+		Aliases: []string{"service-accounts", "sa"},
+		Short:   "Manage service accounts",
+	}
+
+	var (
+		description string
+		scopes      []string
+	)
+	create := &cobra.Command{
+		Use:   "create NAME",
+		Short: "Create a service account",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(cmd, func(ctx context.Context, c *client.Client) error {
+				resp, err := c.ServiceAccount().CreateServiceAccount(ctx, &serviceaccountv1.CreateServiceAccountRequest{
+					DisplayName: args[0],
+					Description: description,
+					Scopes:      scopes,
+				})
+				if err != nil {
+					return err
+				}
+				return o.print(cmd, resp)
+			})
+		},
+	}
+	create.Flags().StringVar(&description, "description", "", "what the account is for")
+	create.Flags().StringSliceVar(&scopes, "scope", nil, "scope the account may grant its tokens (repeatable)")
+
+	var lo listOptions
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List service accounts",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return o.run(cmd, func(ctx context.Context, c *client.Client) error {
+				return printList(cmd, o, client.ListServiceAccounts(ctx, c.ServiceAccount(), lo.options()...))
+			})
+		},
+	}
+	lo.addFlags(list)
+
+	var (
+		name, newDescription, status string
+		newScopes                    []string
+	)
+	update := &cobra.Command{
+		Use:   "update ID",
+		Short: "Change a service account's name, description, scopes or status",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sa := &serviceaccountv1.ServiceAccount{Id: args[0]}
+			mask := &fieldmaskpb.FieldMask{}
+			if cmd.Flags().Changed("name") {
+				sa.DisplayName = name
+				mask.Paths = append(mask.Paths, "display_name")
+			}
+			if cmd.Flags().Changed("description") {
+				sa.Description = newDescription
+				mask.Paths = append(mask.Paths, "description")
+			}
+			if cmd.Flags().Changed("scope") {
+				sa.Scopes = newScopes
+				mask.Paths = append(mask.Paths, "scopes")
+			}
+			if cmd.Flags().Changed("status") {
+				s, err := parseEnum(status, "SERVICE_ACCOUNT_STATUS_", serviceaccountv1.ServiceAccountStatus_value)
+				if err != nil {
+					return err
+				}
+				sa.Status = serviceaccountv1.ServiceAccountStatus(s)
+				mask.Paths = append(mask.Paths, "status")
+			}
+			if len(mask.Paths) == 0 {
+				return errNothingToUpdate
+			}
+			return o.run(cmd, func(ctx context.Context, c *client.Client) error {
+				resp, err := c.ServiceAccount().UpdateServiceAccount(ctx, &serviceaccountv1.UpdateServiceAccountRequest{ServiceAccount: sa, UpdateMask: mask})
+				if err != nil {
+					return err
+				}
+				return o.print(cmd, resp)
+			})
+		},
+	}
+	update.Flags().StringVar(&name, "name", "", "new display name")
+	update.Flags().StringVar(&newDescription, "description", "", "new description")
+	update.Flags().StringSliceVar(&newScopes, "scope", nil, "replacement scope (repeatable)")
+	update.Flags().StringVar(&status, "status", "", "active or disabled")
+
+	tokens := &tokenCommands{
+		owner:  "SERVICE_ACCOUNT_ID",
+		scoped: true,
+		create: func(ctx context.Context, c *client.Client, saID, name string, scopes []string, expiresAt *timestamppb.Timestamp) (proto.Message, error) {
+			return c.ServiceAccount().CreateServiceAccountToken(ctx, &serviceaccountv1.CreateServiceAccountTokenRequest{
+				ServiceAccountId: saID,
+				DisplayName:      name,
+				Scopes:           scopes,
+				ExpiresAt:        expiresAt,
+			})
+		},
+		list: func(ctx context.Context, c *client.Client, saID string, opts []client.ListOption) iter.Seq2[*accesstokenv1.AccessToken, error] {
+			return client.ListServiceAccountTokens(ctx, c.ServiceAccount(), saID, opts...)
+		},
+		get: func(ctx context.Context, c *client.Client, saID, tokenID string) (proto.Message, error) {
+			return c.ServiceAccount().GetServiceAccountToken(ctx, &serviceaccountv1.GetServiceAccountTokenRequest{ServiceAccountId: saID, TokenId: tokenID})
+		},
+		revoke: func(ctx context.Context, c *client.Client, saID, tokenID string) (proto.Message, error) {
+			return c.ServiceAccount().RevokeServiceAccountToken(ctx, &serviceaccountv1.RevokeServiceAccountTokenRequest{ServiceAccountId: saID, TokenId: tokenID})
+		},
+	}
+
+	cmd.AddCommand(
+		create,
+		idCommand(o, "get ID", "Show a service account", func(ctx context.Context, c *client.Client, id string) (proto.Message, error) {
+			return c.ServiceAccount().GetServiceAccount(ctx, &serviceaccountv1.GetServiceAccountRequest{ServiceAccountId: id})
+		}),
+		list,
+		update,
+		idCommand(o, "delete ID", "Delete a service account and revoke its tokens", func(ctx context.Context, c *client.Client, id string) (proto.Message, error) {
+			return c.ServiceAccount().DeleteServiceAccount(ctx, &serviceaccountv1.DeleteServiceAccountRequest{ServiceAccountId: id})
+		}),
+		tokens.command(o, "token", "Manage service account tokens"),
+	)
+	return cmd
+}
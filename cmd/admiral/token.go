@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"iter"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.admiral.io/sdk/client"
+	accesstokenv1 "go.admiral.io/sdk/proto/accesstoken/v1"
+	userv1 "go.admiral.io/sdk/proto/user/v1"
+)
+
+func newTokenCommand(o *globalOptions) *cobra.Command {
+	tokens := &tokenCommands{
+		scoped: true,
+		create: func(ctx context.Context, c *client.Client, _, name string, scopes []string, expiresAt *timestamppb.Timestamp) (proto.Message, error) {
+			return c.User().CreatePersonalAccessToken(ctx, &userv1.CreatePersonalAccessTokenRequest{DisplayName: name, Scopes: scopes, ExpiresAt: expiresAt})
+		},
+		list: func(ctx context.Context, c *client.Client, _ string, opts []client.ListOption) iter.Seq2[*accesstokenv1.AccessToken, error] {
+			return client.ListPersonalAccessTokens(ctx, c.User(), opts...)
+		},
+		get: func(ctx context.Context, c *client.Client, _, tokenID string) (proto.Message, error) {
+			return c.User().GetPersonalAccessToken(ctx, &userv1.GetPersonalAccessTokenRequest{TokenId: tokenID})
+		},
+		revoke: func(ctx context.Context, c *client.Client, _, tokenID string) (proto.Message, error) {
+			return c.User().RevokePersonalAccessToken(ctx, &userv1.RevokePersonalAccessTokenRequest{TokenId: tokenID})
+		},
+	}
+	cmd := tokens.command(o, "token", "Manage your personal access tokens")
+	cmd.Aliases = []string{"tokens", "pat"}
+	return cmd
+}
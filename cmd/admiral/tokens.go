@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"iter"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.admiral.io/sdk/client"
+	accesstokenv1 "go.admiral.io/sdk/proto/accesstoken/v1"
+)
+
+// tokenCommands describes the access token RPCs of one kind of owner, such
+// as a cluster. Personal access tokens have no owner argument.
+type tokenCommands struct {
+	// owner names the owner argument, e.g. "CLUSTER_ID"; empty for none.
+	owner string
+	// scoped reports whether tokens are created with --scope.
+	scoped bool
+
+	create func(ctx context.Context, c *client.Client, ownerID, name string, scopes []string, expiresAt *timestamppb.Timestamp) (proto.Message, error)
+	list   func(ctx context.Context, c *client.Client, ownerID string, opts []client.ListOption) iter.Seq2[*accesstokenv1.AccessToken, error]
+	get    func(ctx context.Context, c *client.Client, ownerID, tokenID string) (proto.Message, error)
+	revoke func(ctx context.Context, c *client.Client, ownerID, tokenID string) (proto.Message, error)
+}
+
+// use returns a Use string with the owner argument prepended to args.
+func (t *tokenCommands) use(name string, args ...string) string {
+	if t.owner != "" {
+		args = append([]string{t.owner}, args...)
+	}
+	return strings.Join(append([]string{name}, args...), " ")
+}
+
+// split separates the owner ID from the remaining arguments.
+func (t *tokenCommands) split(args []string) (string, []string) {
+	if t.owner == "" {
+		return "", args
+	}
+	return args[0], args[1:]
+}
+
+func (t *tokenCommands) nargs(n int) cobra.PositionalArgs {
+	if t.owner != "" {
+		n++
+	}
+	return cobra.ExactArgs(n)
+}
+
+func (t *tokenCommands) command(o *globalOptions, use, short string) *cobra.Command {
+	cmd := &cobra.Command{Use: use, Short: short}
+
+	var (
+		scopes    []string
+		expiresIn time.Duration
+	)
+	create := &cobra.Command{
+		Use:   t.use("create", "NAME"),
+		Short: "Create a token and print its plain-text value",
+		Args:  t.nargs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ownerID, rest := t.split(args)
+			return o.run(cmd, func(ctx context.Context, c *client.Client) error {
+				resp, err := t.create(ctx, c, ownerID, rest[0], scopes, expiresAt(expiresIn))
+				if err != nil {
+					return err
+				}
+				return o.print(cmd, resp)
+			})
+		},
+	}
+	if t.scoped {
+		create.Flags().StringSliceVar(&scopes, "scope", nil, "scope granted to the token, e.g. clusters:read (repeatable)")
+	}
+	create.Flags().DurationVar(&expiresIn, "expires-in", 0, "token lifetime, e.g. 720h (never expires if 0)")
+
+	var lo listOptions
+	list := &cobra.Command{
+		Use:   t.use("list"),
+		Short: "List tokens",
+		Args:  t.nargs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ownerID, _ := t.split(args)
+			return o.run(cmd, func(ctx context.Context, c *client.Client) error {
+				return printList(cmd, o, t.list(ctx, c, ownerID, lo.options()))
+			})
+		},
+	}
+	lo.addFlags(list)
+
+	byID := func(name, short string, call func(ctx context.Context, c *client.Client, ownerID, tokenID string) (proto.Message, error)) *cobra.Command {
+		return &cobra.Command{
+			Use:   t.use(name, "TOKEN_ID"),
+			Short: short,
+			Args:  t.nargs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				ownerID, rest := t.split(args)
+				return o.run(cmd, func(ctx context.Context, c *client.Client) error {
+					resp, err := call(ctx, c, ownerID, rest[0])
+					if err != nil {
+						return err
+					}
+					return o.print(cmd, resp)
+				})
+			},
+		}
+	}
+
+	cmd.AddCommand(create, list, byID("get", "Show a token", t.get), byID("revoke", "Revoke a token", t.revoke))
+	return cmd
+}
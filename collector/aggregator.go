@@ -0,0 +1,297 @@
+package collector
+
+import (
+	"container/list"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	corev1 "k8s.io/api/core/v1"
+
+	"go.admiral.io/sdk/client"
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+)
+
+const (
+	// DefaultMaxTrackedEvents caps the number of distinct events an
+	// Aggregator remembers.
+	DefaultMaxTrackedEvents = 10000
+
+	// DefaultReasonLimit is the number of distinct events per reason an
+	// Aggregator accepts in each ReasonInterval.
+	DefaultReasonLimit = 100
+
+	// DefaultReasonInterval is the window over which ReasonLimit applies.
+	DefaultReasonInterval = time.Minute
+)
+
+// AggregatorConfig configures an Aggregator.
+type AggregatorConfig struct {
+	// MaxEvents caps the number of events returned by each Drain; the most
+	// recent are kept. Defaults to DefaultMaxEvents.
+	MaxEvents int
+	// MaxTracked caps the number of distinct events remembered for merging.
+	// When it is exceeded, the event updated least recently is forgotten,
+	// preferring events already drained. Undrained events that are dropped
+	// are counted and logged by the next Drain. Defaults to
+	// DefaultMaxTrackedEvents.
+	MaxTracked int
+	// ReasonLimit is the number of new distinct events accepted per reason
+	// in each ReasonInterval. Occurrences of events already tracked are
+	// always merged. Defaults to DefaultReasonLimit; negative means no
+	// limit.
+	ReasonLimit int
+	// ReasonInterval is the window for ReasonLimit. Defaults to
+	// DefaultReasonInterval.
+	ReasonInterval time.Duration
+	// Logger for the aggregator. Silent by default.
+	Logger client.Logger
+}
+
+// Aggregator merges repeated Kubernetes events into WorkloadEvents ready to
+// be sent in ReportClusterStatusRequest.Events.
+//
+// Events with the same type and reason about the same object are merged
+// into one WorkloadEvent, which keeps the Uid of the first: Count is the
+// total across the merged events, FirstSeen and LastSeen span all of them,
+// and Message is the most recent. An event seen again with the same Uid
+// and Count is a duplicate and is ignored. Reasons that produce more new
+// events than ReasonLimit in a ReasonInterval are dropped until the next
+// interval.
+type Aggregator struct {
+	cfg AggregatorConfig
+	now func() time.Time
+
+	mu      sync.Mutex
+	groups  map[string]*eventGroup
+	byUID   map[string]string
+	reasons map[string]*reasonWindow
+	// clean and dirty hold the groups that have and have not been drained,
+	// least recently updated first.
+	clean   *list.List
+	dirty   *list.List
+	dropped int
+}
+
+// eventGroup is the aggregate of the events sharing one key.
+type eventGroup struct {
+	key    string
+	event  *clusterv1.WorkloadEvent
+	counts map[string]int32
+	dirty  bool
+	elem   *list.Element // in Aggregator.clean or Aggregator.dirty
+}
+
+// reasonWindow counts new events for one reason in the current interval.
+type reasonWindow struct {
+	start      time.Time
+	accepted   int
+	suppressed int
+}
+
+// NewAggregator returns an empty Aggregator.
+func NewAggregator(cfg AggregatorConfig) *Aggregator {
+	if cfg.MaxEvents <= 0 {
+		cfg.MaxEvents = DefaultMaxEvents
+	}
+	if cfg.MaxTracked <= 0 {
+		cfg.MaxTracked = DefaultMaxTrackedEvents
+	}
+	if cfg.ReasonLimit == 0 {
+		cfg.ReasonLimit = DefaultReasonLimit
+	}
+	if cfg.ReasonInterval <= 0 {
+		cfg.ReasonInterval = DefaultReasonInterval
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = client.NewNoOpLogger()
+	}
+	return &Aggregator{
+		cfg:     cfg,
+		now:     time.Now,
+		groups:  map[string]*eventGroup{},
+		byUID:   map[string]string{},
+		reasons: map[string]*reasonWindow{},
+		clean:   list.New(),
+		dirty:   list.New(),
+	}
+}
+
+// AddEvent converts a core/v1 Event and adds it. Events about objects
+// other than pods and workload controllers are ignored.
+func (a *Aggregator) AddEvent(ev *corev1.Event) bool {
+	if !workloadEventKinds[ev.InvolvedObject.Kind] {
+		return false
+	}
+	return a.Add(workloadEvent(ev))
+}
+
+// Add merges ev into the aggregate. It reports whether ev was new
+// information; duplicates and rate-limited events return false.
+func (a *Aggregator) Add(ev *clusterv1.WorkloadEvent) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key, ok := a.byUID[ev.GetUid()]
+	if !ok {
+		key = eventKey(ev)
+	}
+	if g, ok := a.groups[key]; ok {
+		return a.merge(g, key, ev)
+	}
+	if !a.allow(ev.GetReason()) {
+		return false
+	}
+
+	g := &eventGroup{
+		key:    key,
+		event:  proto.Clone(ev).(*clusterv1.WorkloadEvent),
+		counts: map[string]int32{ev.GetUid(): max(ev.GetCount(), 1)},
+	}
+	g.event.Count = max(ev.GetCount(), 1)
+	a.markLocked(g, true)
+	a.groups[key] = g
+	a.byUID[ev.GetUid()] = key
+	if len(a.groups) > a.cfg.MaxTracked {
+		a.evictLocked()
+	}
+	return true
+}
+
+// Drain returns the most recent MaxEvents events that changed since the
+// last Drain, oldest first, as Collector keeps the most recent events of a
+// report. The rest are returned by later calls. If the batch cannot be
+// sent, pass it to Requeue.
+func (a *Aggregator) Drain() []*clusterv1.WorkloadEvent {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.dropped > 0 {
+		a.cfg.Logger.Warnf("dropped %d undrained events over MaxTracked (%d)", a.dropped, a.cfg.MaxTracked)
+		a.dropped = 0
+	}
+	dirty := make([]*eventGroup, 0, a.dirty.Len())
+	for e := a.dirty.Front(); e != nil; e = e.Next() {
+		dirty = append(dirty, e.Value.(*eventGroup))
+	}
+	slices.SortFunc(dirty, func(x, y *eventGroup) int {
+		return x.event.GetLastSeen().AsTime().Compare(y.event.GetLastSeen().AsTime())
+	})
+	if len(dirty) > a.cfg.MaxEvents {
+		dirty = dirty[len(dirty)-a.cfg.MaxEvents:]
+	}
+	out := make([]*clusterv1.WorkloadEvent, len(dirty))
+	for i, g := range dirty {
+		a.markLocked(g, false)
+		out[i] = proto.Clone(g.event).(*clusterv1.WorkloadEvent)
+	}
+	return out
+}
+
+// Requeue marks events returned by Drain as unsent, so the next Drain
+// returns them again. Events forgotten in the meantime are skipped.
+func (a *Aggregator) Requeue(events []*clusterv1.WorkloadEvent) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, ev := range events {
+		if g, ok := a.groups[a.byUID[ev.GetUid()]]; ok && !g.dirty {
+			a.markLocked(g, true)
+		}
+	}
+}
+
+// Pending returns the number of events waiting to be drained.
+func (a *Aggregator) Pending() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.dirty.Len()
+}
+
+// merge folds ev into g. The caller must hold mu.
+func (a *Aggregator) merge(g *eventGroup, key string, ev *clusterv1.WorkloadEvent) bool {
+	count := max(ev.GetCount(), 1)
+	prev, seen := g.counts[ev.GetUid()]
+	if seen && count <= prev {
+		return false
+	}
+	g.counts[ev.GetUid()] = count
+	a.byUID[ev.GetUid()] = key
+
+	agg := g.event
+	agg.Count = 0
+	for c := range maps.Values(g.counts) {
+		agg.Count += c
+	}
+	if first := ev.GetFirstSeen(); first != nil && (agg.GetFirstSeen() == nil || first.AsTime().Before(agg.GetFirstSeen().AsTime())) {
+		agg.FirstSeen = first
+	}
+	if last := ev.GetLastSeen(); last != nil && !last.AsTime().Before(agg.GetLastSeen().AsTime()) {
+		agg.LastSeen = last
+		agg.Message = ev.GetMessage()
+	}
+	a.markLocked(g, true)
+	return true
+}
+
+// allow applies ReasonLimit to a new event. The caller must hold mu.
+func (a *Aggregator) allow(reason string) bool {
+	if a.cfg.ReasonLimit < 0 {
+		return true
+	}
+	now := a.now()
+	w, ok := a.reasons[reason]
+	if !ok || now.Sub(w.start) >= a.cfg.ReasonInterval {
+		if ok && w.suppressed > 0 {
+			a.cfg.Logger.Warnf("dropped %d %s events in the last %s", w.suppressed, reason, a.cfg.ReasonInterval)
+		}
+		w = &reasonWindow{start: now}
+		a.reasons[reason] = w
+	}
+	if w.accepted >= a.cfg.ReasonLimit {
+		w.suppressed++
+		return false
+	}
+	w.accepted++
+	return true
+}
+
+// markLocked moves g to the back of the dirty or clean list. The caller
+// must hold mu.
+func (a *Aggregator) markLocked(g *eventGroup, dirty bool) {
+	if g.elem != nil {
+		a.queue(g.dirty).Remove(g.elem)
+	}
+	g.dirty = dirty
+	g.elem = a.queue(dirty).PushBack(g)
+}
+
+func (a *Aggregator) queue(dirty bool) *list.List {
+	if dirty {
+		return a.dirty
+	}
+	return a.clean
+}
+
+// evictLocked forgets the drained event updated least recently or, if
+// every event is undrained, the undrained one. The caller must hold mu.
+func (a *Aggregator) evictLocked() {
+	e := a.clean.Front()
+	if e == nil {
+		e = a.dirty.Front()
+		a.dropped++
+	}
+	g := e.Value.(*eventGroup)
+	a.queue(g.dirty).Remove(e)
+	for uid := range g.counts {
+		delete(a.byUID, uid)
+	}
+	delete(a.groups, g.key)
+}
+
+// eventKey groups events of the same type and reason about one object.
+func eventKey(ev *clusterv1.WorkloadEvent) string {
+	r := ev.GetRegarding()
+	return ev.GetType() + "/" + ev.GetReason() + "/" + r.GetKind() + "/" + r.GetNamespace() + "/" + r.GetName()
+}
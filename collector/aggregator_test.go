@@ -0,0 +1,190 @@
+package collector
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"go.admiral.io/sdk/client"
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+)
+
+// event returns a Warning event about pod name, first seen at testNow and
+// last seen at testNow+last.
+func event(uid, reason, name string, count int32, last time.Duration) *clusterv1.WorkloadEvent {
+	return &clusterv1.WorkloadEvent{
+		Uid:       uid,
+		Type:      "Warning",
+		Reason:    reason,
+		Regarding: &clusterv1.ObjectReference{Kind: "Pod", Namespace: "prod", Name: name},
+		Message:   reason + " at " + last.String(),
+		FirstSeen: timestamppb.New(testNow),
+		LastSeen:  timestamppb.New(testNow.Add(last)),
+		Count:     count,
+	}
+}
+
+// recordingLogger records warnings and discards everything else.
+type recordingLogger struct {
+	client.Logger
+	warnings []string
+}
+
+func (l *recordingLogger) Warnf(format string, args ...any) {
+	l.warnings = append(l.warnings, fmt.Sprintf(format, args...))
+}
+
+func newTestAggregator(cfg AggregatorConfig, now *time.Time) *Aggregator {
+	a := NewAggregator(cfg)
+	a.now = func() time.Time { return *now }
+	return a
+}
+
+func TestAggregator_Merges(t *testing.T) {
+	now := testNow
+	a := newTestAggregator(AggregatorConfig{}, &now)
+
+	if !a.Add(event("ev-1", "BackOff", "db-0", 3, time.Minute)) {
+		t.Fatal("Add() = false for a new event")
+	}
+	if a.Add(event("ev-1", "BackOff", "db-0", 3, time.Minute)) {
+		t.Error("Add() = true for a duplicate")
+	}
+	a.Add(event("ev-1", "BackOff", "db-0", 5, 2*time.Minute))
+	a.Add(event("ev-2", "BackOff", "db-0", 2, 3*time.Minute))
+	a.Add(event("ev-3", "Unhealthy", "db-0", 1, 30*time.Second))
+
+	got := a.Drain()
+	if len(got) != 2 {
+		t.Fatalf("Drain() = %v, want 2 events", got)
+	}
+	if got[0].GetReason() != "Unhealthy" {
+		t.Errorf("Drain()[0] = %v, want the oldest event first", got[0])
+	}
+	backoff := got[1]
+	if backoff.GetUid() != "ev-1" || backoff.GetCount() != 7 {
+		t.Errorf("merged event uid, count = %q, %d, want ev-1, 7", backoff.GetUid(), backoff.GetCount())
+	}
+	if !backoff.GetFirstSeen().AsTime().Equal(testNow) || !backoff.GetLastSeen().AsTime().Equal(testNow.Add(3*time.Minute)) {
+		t.Errorf("merged window = %v - %v", backoff.GetFirstSeen().AsTime(), backoff.GetLastSeen().AsTime())
+	}
+	if backoff.GetMessage() != "BackOff at 3m0s" {
+		t.Errorf("merged message = %q, want the latest", backoff.GetMessage())
+	}
+
+	if got := a.Drain(); len(got) != 0 {
+		t.Errorf("second Drain() = %v, want none", got)
+	}
+	a.Add(event("ev-2", "BackOff", "db-0", 4, 4*time.Minute))
+	if got := a.Drain(); len(got) != 1 || got[0].GetCount() != 9 {
+		t.Errorf("Drain() = %v, want ev-1 with count 9", got)
+	}
+}
+
+func TestAggregator_BoundedDrain(t *testing.T) {
+	now := testNow
+	a := newTestAggregator(AggregatorConfig{MaxEvents: 2}, &now)
+	for i, name := range []string{"a", "b", "c"} {
+		a.Add(event("ev-"+name, "BackOff", name, 1, time.Duration(i)*time.Minute))
+	}
+
+	first := a.Drain()
+	if len(first) != 2 || first[0].GetUid() != "ev-b" || first[1].GetUid() != "ev-c" || a.Pending() != 1 {
+		t.Fatalf("Drain() = %v with %d pending, want ev-b, ev-c and 1 pending", first, a.Pending())
+	}
+	a.Requeue(first)
+	if a.Pending() != 3 {
+		t.Errorf("Pending() = %d after Requeue, want 3", a.Pending())
+	}
+}
+
+func TestAggregator_ReasonLimit(t *testing.T) {
+	now := testNow
+	a := newTestAggregator(AggregatorConfig{ReasonLimit: 2}, &now)
+
+	a.Add(event("ev-a", "FailedScheduling", "a", 1, 0))
+	a.Add(event("ev-b", "FailedScheduling", "b", 1, 0))
+	if a.Add(event("ev-c", "FailedScheduling", "c", 1, 0)) {
+		t.Error("Add() = true over the reason limit")
+	}
+	if !a.Add(event("ev-a", "FailedScheduling", "a", 2, time.Second)) {
+		t.Error("Add() = false for a tracked event over the reason limit")
+	}
+	if !a.Add(event("ev-d", "BackOff", "d", 1, 0)) {
+		t.Error("Add() = false for another reason")
+	}
+
+	now = now.Add(DefaultReasonInterval)
+	if !a.Add(event("ev-c", "FailedScheduling", "c", 1, time.Minute)) {
+		t.Error("Add() = false in the next interval")
+	}
+}
+
+func TestAggregator_MaxTracked(t *testing.T) {
+	now := testNow
+	a := newTestAggregator(AggregatorConfig{MaxTracked: 2}, &now)
+	a.Add(event("ev-a", "BackOff", "a", 1, 0))
+	a.Add(event("ev-b", "BackOff", "b", 1, time.Minute))
+	a.Add(event("ev-c", "BackOff", "c", 1, 2*time.Minute))
+
+	got := a.Drain()
+	if len(got) != 2 || got[0].GetUid() != "ev-b" {
+		t.Errorf("Drain() = %v, want ev-b and ev-c after evicting the oldest", got)
+	}
+}
+
+func TestAggregator_EvictsDrainedFirst(t *testing.T) {
+	now := testNow
+	log := &recordingLogger{Logger: client.NewNoOpLogger()}
+	a := newTestAggregator(AggregatorConfig{MaxTracked: 2, Logger: log}, &now)
+	a.Add(event("ev-a", "BackOff", "a", 1, 0))
+	a.Drain()
+	a.Add(event("ev-b", "BackOff", "b", 1, time.Minute))
+	a.Add(event("ev-c", "BackOff", "c", 1, 2*time.Minute))
+
+	// ev-a was drained, so it goes before the older undrained ev-b.
+	if got := a.Drain(); len(got) != 2 || got[0].GetUid() != "ev-b" || got[1].GetUid() != "ev-c" {
+		t.Fatalf("Drain() = %v, want ev-b and ev-c", got)
+	}
+	if len(log.warnings) != 0 {
+		t.Errorf("warnings = %q, want none when only drained events are evicted", log.warnings)
+	}
+
+	a.Requeue([]*clusterv1.WorkloadEvent{event("ev-b", "", "", 0, 0), event("ev-c", "", "", 0, 0)})
+	a.Add(event("ev-d", "BackOff", "d", 1, 3*time.Minute))
+	if got := a.Drain(); len(got) != 2 || got[0].GetUid() != "ev-c" {
+		t.Errorf("Drain() = %v, want ev-c and ev-d", got)
+	}
+	if len(log.warnings) != 1 || !strings.Contains(log.warnings[0], "dropped 1 undrained") {
+		t.Errorf("warnings = %q, want one about the dropped event", log.warnings)
+	}
+}
+
+func TestAggregator_AddEvent(t *testing.T) {
+	now := testNow
+	a := newTestAggregator(AggregatorConfig{}, &now)
+	ev := &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{UID: "ev-1", Namespace: "prod"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "prod", Name: "db-0"},
+		Type:           corev1.EventTypeWarning,
+		Reason:         "BackOff",
+		Count:          2,
+		LastTimestamp:  metav1.NewTime(testNow),
+	}
+	if !a.AddEvent(ev) {
+		t.Error("AddEvent() = false for a Pod event")
+	}
+	ev.InvolvedObject.Kind = "Node"
+	ev.UID = "ev-2"
+	if a.AddEvent(ev) {
+		t.Error("AddEvent() = true for a Node event")
+	}
+	if got := a.Drain(); len(got) != 1 || got[0].GetCount() != 2 {
+		t.Errorf("Drain() = %v, want the Pod event", got)
+	}
+}
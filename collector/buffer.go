@@ -0,0 +1,209 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.admiral.io/sdk/agent"
+	"go.admiral.io/sdk/client"
+	agentv1 "go.admiral.io/sdk/proto/agent/v1"
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+)
+
+const (
+	// DefaultBufferSize is the number of snapshots a Buffer holds before it
+	// coalesces the oldest.
+	DefaultBufferSize = 60
+
+	// DefaultBufferMaxAge is how long a Buffer keeps a snapshot before
+	// dropping it.
+	DefaultBufferMaxAge = time.Hour
+)
+
+// ErrBuffered is returned by Buffer.Push when the report could not be sent
+// yet and is queued for replay.
+var ErrBuffered = errors.New("collector: status report buffered")
+
+// BufferConfig configures a Buffer.
+type BufferConfig struct {
+	// MaxSnapshots caps the number of queued reports. When it is exceeded,
+	// the oldest report is coalesced into the next one. Defaults to
+	// DefaultBufferSize.
+	MaxSnapshots int
+	// MaxAge is how long a report stays queued, measured from its
+	// ReportedAt. Older reports are dropped. Defaults to DefaultBufferMaxAge.
+	MaxAge time.Duration
+	// MaxEvents caps the number of events in a coalesced report; the most
+	// recent are kept. Defaults to DefaultMaxEvents.
+	MaxEvents int
+	// Logger for the buffer. Silent by default.
+	Logger client.Logger
+}
+
+// Buffer queues ReportClusterStatus requests while the control plane is
+// unreachable and replays them, oldest first and with their original
+// ReportedAt, once it is back.
+//
+// The queue is bounded by MaxSnapshots and MaxAge. Cluster and workload
+// status are full snapshots, so when the queue is full the oldest report
+// is coalesced into the next: its status is superseded and only its events
+// are carried forward. Reports are held in memory and do not survive a
+// restart.
+type Buffer struct {
+	api clusterv1.ClusterAPIClient
+	cfg BufferConfig
+	now func() time.Time
+
+	mu    sync.Mutex
+	queue []*clusterv1.ReportClusterStatusRequest
+}
+
+// NewBuffer returns a Buffer that sends through api.
+func NewBuffer(api clusterv1.ClusterAPIClient, cfg BufferConfig) *Buffer {
+	if cfg.MaxSnapshots <= 0 {
+		cfg.MaxSnapshots = DefaultBufferSize
+	}
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = DefaultBufferMaxAge
+	}
+	if cfg.MaxEvents <= 0 {
+		cfg.MaxEvents = DefaultMaxEvents
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = client.NewNoOpLogger()
+	}
+	return &Buffer{api: api, cfg: cfg, now: time.Now}
+}
+
+// Push queues req and replays the queue. It returns nil once every queued
+// report has been sent. If the control plane is unreachable it returns an
+// error wrapping both ErrBuffered and the cause, and the reports stay
+// queued for the next Push or Flush.
+func (b *Buffer) Push(ctx context.Context, req *clusterv1.ReportClusterStatusRequest) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.queue = append(b.queue, req)
+	return b.flushLocked(ctx)
+}
+
+// Flush replays queued reports without adding a new one, for example as
+// soon as the agent reconnects.
+func (b *Buffer) Flush(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.flushLocked(ctx)
+}
+
+// Len returns the number of queued reports.
+func (b *Buffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.queue)
+}
+
+// flushLocked trims the queue and sends it oldest first, stopping at the
+// first error. The caller must hold mu.
+func (b *Buffer) flushLocked(ctx context.Context) error {
+	b.dropExpiredLocked()
+	for len(b.queue) > b.cfg.MaxSnapshots {
+		b.queue[1] = coalesce(b.queue[0], b.queue[1], b.cfg.MaxEvents)
+		b.queue = b.queue[1:]
+	}
+
+	var rejected error
+	for len(b.queue) > 0 {
+		req := b.queue[0]
+		_, err := b.api.ReportClusterStatus(ctx, req)
+		switch status.Code(err) {
+		case codes.OK:
+		case codes.InvalidArgument, codes.FailedPrecondition:
+			// Replaying a report the server rejected cannot succeed, and
+			// would hold back every report after it.
+			b.cfg.Logger.Warnf("dropping status report from %s: %v", req.GetReportedAt().AsTime().Format(time.RFC3339), err)
+			rejected = errors.Join(rejected, err)
+		default:
+			return fmt.Errorf("%w (%d queued): %w", ErrBuffered, len(b.queue), err)
+		}
+		b.queue[0] = nil
+		b.queue = b.queue[1:]
+	}
+	return rejected
+}
+
+// dropExpiredLocked removes reports older than MaxAge. The caller must
+// hold mu.
+func (b *Buffer) dropExpiredLocked() {
+	cutoff := b.now().Add(-b.cfg.MaxAge)
+	n := 0
+	for _, req := range b.queue {
+		if req.GetReportedAt().AsTime().Before(cutoff) {
+			continue
+		}
+		b.queue[n] = req
+		n++
+	}
+	if dropped := len(b.queue) - n; dropped > 0 {
+		b.cfg.Logger.Warnf("dropped %d status reports older than %s", dropped, b.cfg.MaxAge)
+	}
+	clear(b.queue[n:])
+	b.queue = b.queue[:n]
+}
+
+// coalesce folds the events of older into newer, whose status supersedes
+// it. An event in both keeps the newer copy.
+func coalesce(older, newer *clusterv1.ReportClusterStatusRequest, maxEvents int) *clusterv1.ReportClusterStatusRequest {
+	if len(older.GetEvents()) == 0 {
+		return newer
+	}
+	seen := make(map[string]bool, len(newer.GetEvents()))
+	for _, ev := range newer.GetEvents() {
+		seen[ev.GetUid()] = true
+	}
+	events := slices.Clone(newer.GetEvents())
+	for _, ev := range older.GetEvents() {
+		if !seen[ev.GetUid()] {
+			events = append(events, ev)
+		}
+	}
+	slices.SortStableFunc(events, func(a, b *clusterv1.WorkloadEvent) int {
+		return a.GetLastSeen().AsTime().Compare(b.GetLastSeen().AsTime())
+	})
+	if len(events) > maxEvents {
+		events = events[len(events)-maxEvents:]
+	}
+
+	return &clusterv1.ReportClusterStatusRequest{
+		ClusterId:  newer.GetClusterId(),
+		Status:     newer.GetStatus(),
+		Workloads:  newer.GetWorkloads(),
+		Events:     events,
+		ReportedAt: newer.GetReportedAt(),
+	}
+}
+
+// BufferedStatusPusher returns an agent.StatusPusher like StatusPusher
+// that queues reports in buf while the control plane is unreachable.
+// Events in a queued report are not collected again.
+func (c *Collector) BufferedStatusPusher(buf *Buffer) agent.StatusPusher {
+	return agent.StatusPusherFunc(func(ctx context.Context, a *agentv1.Agent) error {
+		if a.GetClusterId() == "" {
+			return errors.New("collector: agent is not bound to a cluster")
+		}
+		req, err := c.Collect(ctx, a.GetClusterId())
+		if err != nil {
+			return err
+		}
+		err = buf.Push(ctx, req)
+		if err == nil || errors.Is(err, ErrBuffered) {
+			c.markSent(req.GetEvents())
+		}
+		return err
+	})
+}
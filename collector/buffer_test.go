@@ -0,0 +1,169 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	agentv1 "go.admiral.io/sdk/proto/agent/v1"
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+)
+
+// snapshot returns a report taken at testNow+at with the given node count
+// and event UIDs.
+func snapshot(at time.Duration, nodes int32, events ...string) *clusterv1.ReportClusterStatusRequest {
+	req := &clusterv1.ReportClusterStatusRequest{
+		ClusterId:  "cluster-1",
+		Status:     &clusterv1.ClusterStatus{NodeCount: nodes},
+		ReportedAt: timestamppb.New(testNow.Add(at)),
+	}
+	for _, uid := range events {
+		req.Events = append(req.Events, &clusterv1.WorkloadEvent{Uid: uid, LastSeen: req.GetReportedAt()})
+	}
+	return req
+}
+
+func newTestBuffer(api clusterv1.ClusterAPIClient, cfg BufferConfig, now *time.Time) *Buffer {
+	b := NewBuffer(api, cfg)
+	b.now = func() time.Time { return *now }
+	return b
+}
+
+func eventUIDs(req *clusterv1.ReportClusterStatusRequest) []string {
+	var uids []string
+	for _, ev := range req.GetEvents() {
+		uids = append(uids, ev.GetUid())
+	}
+	return uids
+}
+
+func TestBuffer_ReplaysInOrder(t *testing.T) {
+	api := &recordingClusterAPI{err: status.Error(codes.Unavailable, "control plane down")}
+	now := testNow
+	b := newTestBuffer(api, BufferConfig{}, &now)
+	ctx := context.Background()
+
+	for i := range 3 {
+		err := b.Push(ctx, snapshot(time.Duration(i)*time.Minute, int32(i+1)))
+		if !errors.Is(err, ErrBuffered) || status.Code(err) != codes.Unavailable {
+			t.Fatalf("Push() error = %v, want ErrBuffered wrapping Unavailable", err)
+		}
+	}
+	if b.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3", b.Len())
+	}
+
+	api.err = nil
+	if err := b.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if len(api.reports) != 3 || b.Len() != 0 {
+		t.Fatalf("sent %d reports, %d still queued, want 3 and 0", len(api.reports), b.Len())
+	}
+	for i, req := range api.reports {
+		want := testNow.Add(time.Duration(i) * time.Minute)
+		if req.GetStatus().GetNodeCount() != int32(i+1) || !req.GetReportedAt().AsTime().Equal(want) {
+			t.Errorf("report %d = %v, want node count %d reported at %v", i, req, i+1, want)
+		}
+	}
+}
+
+func TestBuffer_CoalescesOldest(t *testing.T) {
+	api := &recordingClusterAPI{err: status.Error(codes.Unavailable, "control plane down")}
+	now := testNow.Add(3 * time.Minute)
+	b := newTestBuffer(api, BufferConfig{MaxSnapshots: 2}, &now)
+	ctx := context.Background()
+
+	_ = b.Push(ctx, snapshot(0, 1, "ev-1", "ev-2"))
+	_ = b.Push(ctx, snapshot(time.Minute, 2, "ev-2", "ev-3"))
+	_ = b.Push(ctx, snapshot(2*time.Minute, 3))
+	if b.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", b.Len())
+	}
+
+	api.err = nil
+	if err := b.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	first := api.reports[0]
+	if first.GetStatus().GetNodeCount() != 2 || !first.GetReportedAt().AsTime().Equal(testNow.Add(time.Minute)) {
+		t.Errorf("coalesced report = %v, want the second snapshot's status", first)
+	}
+	uids := eventUIDs(first)
+	if len(uids) != 3 || uids[0] != "ev-1" {
+		t.Errorf("coalesced events = %v, want ev-1, ev-2, ev-3 oldest first", uids)
+	}
+	if n := api.reports[1].GetStatus().GetNodeCount(); n != 3 {
+		t.Errorf("second report node count = %d, want 3", n)
+	}
+}
+
+func TestBuffer_DropsExpired(t *testing.T) {
+	api := &recordingClusterAPI{err: status.Error(codes.Unavailable, "control plane down")}
+	now := testNow
+	b := newTestBuffer(api, BufferConfig{MaxAge: 10 * time.Minute}, &now)
+	ctx := context.Background()
+
+	_ = b.Push(ctx, snapshot(0, 1))
+	now = now.Add(5 * time.Minute)
+	_ = b.Push(ctx, snapshot(5*time.Minute, 2))
+
+	now = now.Add(6 * time.Minute)
+	api.err = nil
+	if err := b.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if len(api.reports) != 1 || api.reports[0].GetStatus().GetNodeCount() != 2 {
+		t.Errorf("reports = %v, want only the unexpired snapshot", api.reports)
+	}
+}
+
+func TestBuffer_DropsRejected(t *testing.T) {
+	api := &recordingClusterAPI{err: status.Error(codes.Unavailable, "control plane down")}
+	now := testNow
+	b := newTestBuffer(api, BufferConfig{}, &now)
+	ctx := context.Background()
+
+	_ = b.Push(ctx, snapshot(0, 1))
+	api.err = status.Error(codes.InvalidArgument, "bad report")
+	err := b.Flush(ctx)
+	if status.Code(err) != codes.InvalidArgument || errors.Is(err, ErrBuffered) {
+		t.Errorf("Flush() error = %v, want InvalidArgument", err)
+	}
+	if b.Len() != 0 {
+		t.Errorf("Len() = %d, want rejected report dropped", b.Len())
+	}
+}
+
+func TestBufferedStatusPusher(t *testing.T) {
+	kube, _ := newTestCluster(t)
+	c := newTestCollector(t, Config{Kubernetes: kube})
+	api := &recordingClusterAPI{err: status.Error(codes.Unavailable, "control plane down")}
+	now := testNow
+	buf := newTestBuffer(api, BufferConfig{}, &now)
+	pusher := c.BufferedStatusPusher(buf)
+	ctx := context.Background()
+	a := &agentv1.Agent{ClusterId: "cluster-1"}
+
+	for range 2 {
+		if err := pusher.PushStatus(ctx, a); !errors.Is(err, ErrBuffered) {
+			t.Fatalf("PushStatus() error = %v, want ErrBuffered", err)
+		}
+	}
+
+	api.err = nil
+	if err := pusher.PushStatus(ctx, a); err != nil {
+		t.Fatalf("PushStatus() error = %v", err)
+	}
+	if len(api.reports) != 3 {
+		t.Fatalf("sent %d reports, want 3", len(api.reports))
+	}
+	if got := [3]int{len(api.reports[0].GetEvents()), len(api.reports[1].GetEvents()), len(api.reports[2].GetEvents())}; got != [3]int{1, 0, 0} {
+		t.Errorf("events per report = %v, want the event only in the first", got)
+	}
+}
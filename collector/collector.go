@@ -0,0 +1,410 @@
+// Package collector builds Admiral cluster status reports from the
+// Kubernetes API and pushes them with ClusterAPI.ReportClusterStatus.
+//
+// # Collecting Status
+//
+// A Collector reads nodes, pods, Deployments, StatefulSets, DaemonSets and
+// events through client-go and fills in ClusterStatus, WorkloadStatus,
+// ContainerStatus and WorkloadEvent messages. CPU and memory usage come
+// from metrics-server when a metrics client is configured:
+//
+//	col, err := collector.New(collector.Config{
+//	    Kubernetes: kubernetes.NewForConfigOrDie(restConfig),
+//	    Metrics:    metricsclient.NewForConfigOrDie(restConfig),
+//	})
+//	resp, err := col.Push(ctx, c.Cluster(), clusterID)
+//
+// # Running in an Agent
+//
+// StatusPusher adapts a Collector to the agent runtime, so every status
+// push interval reports the cluster the agent is bound to:
+//
+//	uid, err := collector.ClusterUID(ctx, kube)
+//	rt, err := agent.New(c.Agent(), agent.Config{
+//	    DisplayName: "prod-us-east-1-agent",
+//	    Kubernetes:  &agentv1.KubernetesAgentMetadata{ClusterUid: uid},
+//	    Status:      col.StatusPusher(c.Cluster()),
+//	})
+//
+// # Buffering While Offline
+//
+// BufferedStatusPusher queues reports in a Buffer while the control plane
+// is unreachable and replays them in order, with their original
+// ReportedAt, on the next successful push. The queue is capped by count and
+// age; once full, the oldest report is coalesced into the next so that
+// only its events are kept:
+//
+//	buf := collector.NewBuffer(c.Cluster(), collector.BufferConfig{MaxAge: 30 * time.Minute})
+//	rt, err := agent.New(c.Agent(), agent.Config{
+//	    // ...
+//	    Status: col.BufferedStatusPusher(buf),
+//	})
+//
+// # Aggregating Events
+//
+// Agents that watch events rather than listing them can feed them to an
+// Aggregator, which merges repeats of the same reason about the same
+// object, rate-limits noisy reasons and drains a bounded batch per push:
+//
+//	agg := collector.NewAggregator(collector.AggregatorConfig{})
+//	agg.AddEvent(ev) // from an informer
+//	req.Events = agg.Drain()
+//	if _, err := c.Cluster().ReportClusterStatus(ctx, req); err != nil {
+//	    agg.Requeue(req.Events)
+//	}
+//
+// # Reporting Workload Changes
+//
+// A WorkloadReporter sends ReportWorkloadStatus pushes that carry only the
+// workloads that changed since the server last acknowledged them, with a
+// full push every ResyncInterval and whenever the server answers Ack false:
+//
+//	rep := collector.NewWorkloadReporter(c.Cluster(), collector.ReporterConfig{})
+//	req, err := col.Collect(ctx, clusterID)
+//	sent, err := rep.Report(ctx, clusterID, req.GetWorkloads())
+//
+// # Testing
+//
+// Config takes client-go interfaces, so a Collector runs unchanged against
+// k8s.io/client-go/kubernetes/fake and k8s.io/metrics/pkg/client/clientset/versioned/fake.
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	metricsclient "k8s.io/metrics/pkg/client/clientset/versioned"
+
+	"go.admiral.io/sdk/agent"
+	"go.admiral.io/sdk/client"
+	agentv1 "go.admiral.io/sdk/proto/agent/v1"
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+)
+
+const (
+	// DefaultMaxEvents caps the number of events in a single report.
+	DefaultMaxEvents = 1000
+
+	// pendingTooLong is how long a pod may stay Pending before its workload
+	// is considered degraded.
+	pendingTooLong = 5 * time.Minute
+)
+
+// workloadEventKinds are the involved object kinds whose events are
+// reported as WorkloadEvents.
+var workloadEventKinds = map[string]bool{
+	"Pod":         true,
+	"ReplicaSet":  true,
+	"Deployment":  true,
+	"StatefulSet": true,
+	"DaemonSet":   true,
+}
+
+// Config configures a Collector.
+type Config struct {
+	// Kubernetes is the client for the cluster being reported. Required.
+	Kubernetes kubernetes.Interface
+	// Metrics reads node and pod usage from metrics-server. When nil, usage
+	// fields are left zero.
+	Metrics metricsclient.Interface
+	// Namespaces limits workloads, pods and events to these namespaces.
+	// Empty means all namespaces. Nodes are always reported.
+	Namespaces []string
+	// MaxEvents caps the number of events per report; the most recent are
+	// kept. Defaults to DefaultMaxEvents.
+	MaxEvents int
+	// Logger for the collector. Silent by default.
+	Logger client.Logger
+}
+
+// CheckAndSetDefaults validates the config and fills in defaults.
+func (c *Config) CheckAndSetDefaults() error {
+	if c.Kubernetes == nil {
+		return errors.New("collector: Kubernetes client is required")
+	}
+	if c.MaxEvents <= 0 {
+		c.MaxEvents = DefaultMaxEvents
+	}
+	if c.Logger == nil {
+		c.Logger = client.NewNoOpLogger()
+	}
+	return nil
+}
+
+// Collector builds status reports for one Kubernetes cluster. It remembers
+// restart counts between collections, to spot restarts that keep
+// increasing, and which events it has already pushed.
+type Collector struct {
+	cfg Config
+	now func() time.Time
+
+	mu       sync.Mutex
+	restarts map[string]int32
+	sent     map[types.UID]int32
+}
+
+// New returns a Collector for cfg.
+func New(cfg Config) (*Collector, error) {
+	if err := cfg.CheckAndSetDefaults(); err != nil {
+		return nil, err
+	}
+	return &Collector{
+		cfg:      cfg,
+		now:      time.Now,
+		restarts: map[string]int32{},
+		sent:     map[types.UID]int32{},
+	}, nil
+}
+
+// Collect reads the cluster and returns a status report for clusterID.
+// Events are those not yet acknowledged by a successful Push.
+func (c *Collector) Collect(ctx context.Context, clusterID string) (*clusterv1.ReportClusterStatusRequest, error) {
+	kube := c.cfg.Kubernetes
+	status := &clusterv1.ClusterStatus{}
+
+	if v, err := kube.Discovery().ServerVersion(); err != nil {
+		c.cfg.Logger.Warnf("failed to get Kubernetes version: %v", err)
+	} else {
+		status.K8SVersion = strings.TrimPrefix(v.GitVersion, "v")
+	}
+
+	nodes, err := kube.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("collector: list nodes: %w", err)
+	}
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		status.NodeCount++
+		if nodeReady(node) {
+			status.NodesReady++
+		}
+		status.PodCapacity += int32(node.Status.Allocatable.Pods().Value())
+		status.CpuCapacityMillicores += node.Status.Capacity.Cpu().MilliValue()
+		status.MemoryCapacityBytes += node.Status.Capacity.Memory().Value()
+	}
+	if c.cfg.Metrics != nil {
+		usage, err := c.cfg.Metrics.MetricsV1beta1().NodeMetricses().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			c.cfg.Logger.Warnf("failed to get node metrics: %v", err)
+		} else {
+			for _, m := range usage.Items {
+				status.CpuUsedMillicores += m.Usage.Cpu().MilliValue()
+				status.MemoryUsedBytes += m.Usage.Memory().Value()
+			}
+		}
+	}
+
+	pods, err := eachNamespace(ctx, c.cfg.Namespaces, func(ctx context.Context, ns string) ([]corev1.Pod, error) {
+		l, err := kube.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return l.Items, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("collector: list pods: %w", err)
+	}
+	for i := range pods {
+		status.PodCount++
+		switch pods[i].Status.Phase {
+		case corev1.PodRunning:
+			status.PodsRunning++
+		case corev1.PodPending:
+			status.PodsPending++
+		case corev1.PodFailed:
+			status.PodsFailed++
+		}
+	}
+
+	workloads, err := c.collectWorkloads(ctx, pods)
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range workloads {
+		status.WorkloadsTotal++
+		switch w.GetHealthStatus() {
+		case clusterv1.WorkloadHealthStatus_WORKLOAD_HEALTH_STATUS_HEALTHY:
+			status.WorkloadsHealthy++
+		case clusterv1.WorkloadHealthStatus_WORKLOAD_HEALTH_STATUS_DEGRADED:
+			status.WorkloadsDegraded++
+		case clusterv1.WorkloadHealthStatus_WORKLOAD_HEALTH_STATUS_ERROR:
+			status.WorkloadsError++
+		}
+	}
+
+	events, err := c.collectEvents(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &clusterv1.ReportClusterStatusRequest{
+		ClusterId:  clusterID,
+		Status:     status,
+		Workloads:  workloads,
+		Events:     events,
+		ReportedAt: timestamppb.New(c.now()),
+	}, nil
+}
+
+// Push collects a report for clusterID and sends it with
+// ReportClusterStatus. Events in the report are not sent again once the
+// server has accepted it.
+func (c *Collector) Push(ctx context.Context, api clusterv1.ClusterAPIClient, clusterID string) (*clusterv1.ReportClusterStatusResponse, error) {
+	req, err := c.Collect(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := api.ReportClusterStatus(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	c.markSent(req.GetEvents())
+	return resp, nil
+}
+
+// StatusPusher returns an agent.StatusPusher that pushes a report for the
+// agent's cluster.
+func (c *Collector) StatusPusher(api clusterv1.ClusterAPIClient) agent.StatusPusher {
+	return agent.StatusPusherFunc(func(ctx context.Context, a *agentv1.Agent) error {
+		if a.GetClusterId() == "" {
+			return errors.New("collector: agent is not bound to a cluster")
+		}
+		_, err := c.Push(ctx, api, a.GetClusterId())
+		return err
+	})
+}
+
+// ClusterUID returns the UID of the kube-system namespace, the stable
+// cluster identifier expected in KubernetesAgentMetadata.
+func ClusterUID(ctx context.Context, kube kubernetes.Interface) (string, error) {
+	ns, err := kube.CoreV1().Namespaces().Get(ctx, metav1.NamespaceSystem, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("collector: get kube-system namespace: %w", err)
+	}
+	return string(ns.UID), nil
+}
+
+// collectEvents returns workload events that are new, or whose count has
+// changed, since the last successful push.
+func (c *Collector) collectEvents(ctx context.Context) ([]*clusterv1.WorkloadEvent, error) {
+	items, err := eachNamespace(ctx, c.cfg.Namespaces, func(ctx context.Context, ns string) ([]corev1.Event, error) {
+		l, err := c.cfg.Kubernetes.CoreV1().Events(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return l.Items, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("collector: list events: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	present := make(map[types.UID]bool, len(items))
+	var events []*clusterv1.WorkloadEvent
+	for i := range items {
+		ev := &items[i]
+		present[ev.UID] = true
+		if !workloadEventKinds[ev.InvolvedObject.Kind] {
+			continue
+		}
+		we := workloadEvent(ev)
+		if sent, ok := c.sent[ev.UID]; ok && sent == we.GetCount() {
+			continue
+		}
+		events = append(events, we)
+	}
+	// Forget events the API server has expired.
+	for uid := range c.sent {
+		if !present[uid] {
+			delete(c.sent, uid)
+		}
+	}
+
+	slices.SortFunc(events, func(a, b *clusterv1.WorkloadEvent) int {
+		return a.GetLastSeen().AsTime().Compare(b.GetLastSeen().AsTime())
+	})
+	if len(events) > c.cfg.MaxEvents {
+		events = events[len(events)-c.cfg.MaxEvents:]
+	}
+	return events, nil
+}
+
+func (c *Collector) markSent(events []*clusterv1.WorkloadEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, ev := range events {
+		c.sent[types.UID(ev.GetUid())] = ev.GetCount()
+	}
+}
+
+// workloadEvent converts a core/v1 Event, preferring series data for
+// events recorded with the events.k8s.io API.
+func workloadEvent(ev *corev1.Event) *clusterv1.WorkloadEvent {
+	first := ev.FirstTimestamp.Time
+	last := ev.LastTimestamp.Time
+	count := ev.Count
+	if first.IsZero() {
+		first = ev.EventTime.Time
+	}
+	if ev.Series != nil {
+		count = ev.Series.Count
+		last = ev.Series.LastObservedTime.Time
+	}
+	if last.IsZero() {
+		last = first
+	}
+	if count == 0 {
+		count = 1
+	}
+	return &clusterv1.WorkloadEvent{
+		Uid:    string(ev.UID),
+		Type:   ev.Type,
+		Reason: ev.Reason,
+		Regarding: &clusterv1.ObjectReference{
+			Kind:      ev.InvolvedObject.Kind,
+			Namespace: ev.InvolvedObject.Namespace,
+			Name:      ev.InvolvedObject.Name,
+		},
+		Message:   ev.Message,
+		FirstSeen: timestamppb.New(first),
+		LastSeen:  timestamppb.New(last),
+		Count:     count,
+	}
+}
+
+func nodeReady(node *corev1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// eachNamespace runs list for every namespace, or once across all
+// namespaces when none are configured.
+func eachNamespace[T any](ctx context.Context, namespaces []string, list func(context.Context, string) ([]T, error)) ([]T, error) {
+	if len(namespaces) == 0 {
+		return list(ctx, metav1.NamespaceAll)
+	}
+	var all []T
+	for _, ns := range namespaces {
+		items, err := list(ctx, ns)
+		if err != nil {
+			return nil, fmt.Errorf("namespace %s: %w", ns, err)
+		}
+		all = append(all, items...)
+	}
+	return all, nil
+}
@@ -0,0 +1,373 @@
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+
+	agentv1 "go.admiral.io/sdk/proto/agent/v1"
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+)
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func ptr[T any](v T) *T { return &v }
+
+func resources(cpu, mem string) corev1.ResourceList {
+	return corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(mem),
+	}
+}
+
+func node(name string, ready corev1.ConditionStatus, cpu, mem string) *corev1.Node {
+	capacity := resources(cpu, mem)
+	capacity[corev1.ResourcePods] = resource.MustParse("110")
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Capacity:    capacity,
+			Allocatable: capacity,
+			Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+		},
+	}
+}
+
+func owned(kind, name string) []metav1.OwnerReference {
+	return []metav1.OwnerReference{{Kind: kind, Name: name, Controller: ptr(true)}}
+}
+
+func pod(name, ownerKind, ownerName string, phase corev1.PodPhase, state corev1.ContainerState, restarts int32) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "prod",
+			OwnerReferences:   owned(ownerKind, ownerName),
+			CreationTimestamp: metav1.NewTime(testNow.Add(-10 * time.Minute)),
+		},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name:      "app",
+			Image:     "registry.example.com/" + ownerName + ":v1",
+			Resources: corev1.ResourceRequirements{Requests: resources("100m", "128Mi"), Limits: resources("200m", "256Mi")},
+		}}},
+		Status: corev1.PodStatus{
+			Phase: phase,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "app",
+				Ready:        state.Running != nil,
+				RestartCount: restarts,
+				State:        state,
+			}},
+		},
+	}
+}
+
+var (
+	running      = corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	crashLooping = corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}
+	creating     = corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}}
+)
+
+// newTestCluster returns a fake cluster with one healthy Deployment, a
+// crash-looping StatefulSet and a DaemonSet with a pod stuck in Pending.
+func newTestCluster(t *testing.T) (*fake.Clientset, *metricsfake.Clientset) {
+	t.Helper()
+	kube := fake.NewClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system", UID: "kube-system-uid"}},
+		node("n1", corev1.ConditionTrue, "4", "8Gi"),
+		node("n2", corev1.ConditionFalse, "2", "4Gi"),
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "prod", Labels: map[string]string{"app": "api"}},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr(int32(2))},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: 2, AvailableReplicas: 2},
+		},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "api-7d9f", Namespace: "prod", OwnerReferences: owned("Deployment", "api")}},
+		pod("api-7d9f-a", "ReplicaSet", "api-7d9f", corev1.PodRunning, running, 0),
+		pod("api-7d9f-b", "ReplicaSet", "api-7d9f", corev1.PodRunning, running, 1),
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "prod"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: ptr(int32(1))},
+		},
+		pod("db-0", "StatefulSet", "db", corev1.PodRunning, crashLooping, 5),
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "prod"},
+			Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: 2, NumberReady: 1, NumberAvailable: 1},
+		},
+		pod("logs-a", "DaemonSet", "logs", corev1.PodRunning, running, 0),
+		pod("logs-b", "DaemonSet", "logs", corev1.PodPending, creating, 0),
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "db-0.1", Namespace: "prod", UID: "ev-1"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "prod", Name: "db-0"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "BackOff",
+			Message:        "Back-off restarting failed container",
+			FirstTimestamp: metav1.NewTime(testNow.Add(-5 * time.Minute)),
+			LastTimestamp:  metav1.NewTime(testNow.Add(-time.Minute)),
+			Count:          5,
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "n2.1", Namespace: "default", UID: "ev-2"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Node", Name: "n2"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "NodeNotReady",
+		},
+	)
+	kube.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: "v1.29.2"}
+
+	// The metrics fake cannot map NodeMetrics and PodMetrics to their list
+	// resources, so lists are answered by reactors.
+	metrics := metricsfake.NewSimpleClientset()
+	metrics.PrependReactor("list", "nodes", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &metricsv1beta1.NodeMetricsList{Items: []metricsv1beta1.NodeMetrics{
+			{ObjectMeta: metav1.ObjectMeta{Name: "n1"}, Usage: resources("1500m", "3Gi")},
+			{ObjectMeta: metav1.ObjectMeta{Name: "n2"}, Usage: resources("500m", "1Gi")},
+		}}, nil
+	})
+	metrics.PrependReactor("list", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &metricsv1beta1.PodMetricsList{Items: []metricsv1beta1.PodMetrics{
+			{ObjectMeta: metav1.ObjectMeta{Name: "api-7d9f-a", Namespace: "prod"}, Containers: []metricsv1beta1.ContainerMetrics{{Name: "app", Usage: resources("50m", "100Mi")}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "api-7d9f-b", Namespace: "prod"}, Containers: []metricsv1beta1.ContainerMetrics{{Name: "app", Usage: resources("70m", "100Mi")}}},
+		}}, nil
+	})
+	return kube, metrics
+}
+
+func newTestCollector(t *testing.T, cfg Config) *Collector {
+	t.Helper()
+	c, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	c.now = func() time.Time { return testNow }
+	return c
+}
+
+func workloadNamed(t *testing.T, req *clusterv1.ReportClusterStatusRequest, name string) *clusterv1.WorkloadStatus {
+	t.Helper()
+	for _, w := range req.GetWorkloads() {
+		if w.GetName() == name {
+			return w
+		}
+	}
+	t.Fatalf("workload %q not reported", name)
+	return nil
+}
+
+func TestCollect_ClusterStatus(t *testing.T) {
+	kube, metrics := newTestCluster(t)
+	c := newTestCollector(t, Config{Kubernetes: kube, Metrics: metrics})
+
+	req, err := c.Collect(context.Background(), "cluster-1")
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	s := req.GetStatus()
+	checks := []struct {
+		name      string
+		got, want int64
+	}{
+		{"NodeCount", int64(s.GetNodeCount()), 2},
+		{"NodesReady", int64(s.GetNodesReady()), 1},
+		{"PodCapacity", int64(s.GetPodCapacity()), 220},
+		{"PodCount", int64(s.GetPodCount()), 5},
+		{"PodsRunning", int64(s.GetPodsRunning()), 4},
+		{"PodsPending", int64(s.GetPodsPending()), 1},
+		{"CpuCapacityMillicores", s.GetCpuCapacityMillicores(), 6000},
+		{"CpuUsedMillicores", s.GetCpuUsedMillicores(), 2000},
+		{"MemoryCapacityBytes", s.GetMemoryCapacityBytes(), 12 << 30},
+		{"MemoryUsedBytes", s.GetMemoryUsedBytes(), 4 << 30},
+		{"WorkloadsTotal", int64(s.GetWorkloadsTotal()), 3},
+		{"WorkloadsHealthy", int64(s.GetWorkloadsHealthy()), 1},
+		{"WorkloadsDegraded", int64(s.GetWorkloadsDegraded()), 1},
+		{"WorkloadsError", int64(s.GetWorkloadsError()), 1},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %d, want %d", c.name, c.got, c.want)
+		}
+	}
+	if s.GetK8SVersion() != "1.29.2" {
+		t.Errorf("K8SVersion = %q, want 1.29.2", s.GetK8SVersion())
+	}
+	if req.GetClusterId() != "cluster-1" || !req.GetReportedAt().AsTime().Equal(testNow) {
+		t.Errorf("ClusterId = %q, ReportedAt = %v", req.GetClusterId(), req.GetReportedAt().AsTime())
+	}
+}
+
+func TestCollect_Workloads(t *testing.T) {
+	kube, metrics := newTestCluster(t)
+	c := newTestCollector(t, Config{Kubernetes: kube, Metrics: metrics})
+
+	req, err := c.Collect(context.Background(), "cluster-1")
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	api := workloadNamed(t, req, "api")
+	if api.GetKind() != "Deployment" || api.GetLabels()["app"] != "api" {
+		t.Errorf("api kind = %q, labels = %v", api.GetKind(), api.GetLabels())
+	}
+	if api.GetHealthStatus() != clusterv1.WorkloadHealthStatus_WORKLOAD_HEALTH_STATUS_HEALTHY {
+		t.Errorf("api health = %v, want HEALTHY", api.GetHealthStatus())
+	}
+	if api.GetCpuRequestsMillicores() != 200 || api.GetCpuLimitsMillicores() != 400 || api.GetCpuUsedMillicores() != 120 {
+		t.Errorf("api cpu requests/limits/used = %d/%d/%d, want 200/400/120",
+			api.GetCpuRequestsMillicores(), api.GetCpuLimitsMillicores(), api.GetCpuUsedMillicores())
+	}
+	if api.GetMemoryRequestsBytes() != 256<<20 || api.GetMemoryUsedBytes() != 200<<20 {
+		t.Errorf("api memory requests/used = %d/%d", api.GetMemoryRequestsBytes(), api.GetMemoryUsedBytes())
+	}
+	if len(api.GetContainers()) != 1 {
+		t.Fatalf("api containers = %v, want one aggregated entry", api.GetContainers())
+	}
+	ct := api.GetContainers()[0]
+	if ct.GetName() != "app" || ct.GetImage() != "registry.example.com/api-7d9f:v1" || ct.GetRestartCount() != 1 || ct.GetState() != "running" || !ct.GetReady() {
+		t.Errorf("api container = %v", ct)
+	}
+
+	db := workloadNamed(t, req, "db")
+	if db.GetHealthStatus() != clusterv1.WorkloadHealthStatus_WORKLOAD_HEALTH_STATUS_ERROR {
+		t.Errorf("db health = %v, want ERROR", db.GetHealthStatus())
+	}
+	if got := db.GetContainers()[0]; got.GetState() != "waiting" || got.GetReady() {
+		t.Errorf("db container = %v, want waiting and not ready", got)
+	}
+
+	logs := workloadNamed(t, req, "logs")
+	if logs.GetHealthStatus() != clusterv1.WorkloadHealthStatus_WORKLOAD_HEALTH_STATUS_DEGRADED {
+		t.Errorf("logs health = %v, want DEGRADED", logs.GetHealthStatus())
+	}
+}
+
+func TestCollect_RestartsIncreasing(t *testing.T) {
+	kube, _ := newTestCluster(t)
+	c := newTestCollector(t, Config{Kubernetes: kube})
+	ctx := context.Background()
+
+	if _, err := c.Collect(ctx, "cluster-1"); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	p, err := kube.CoreV1().Pods("prod").Get(ctx, "api-7d9f-a", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Status.ContainerStatuses[0].RestartCount = 2
+	if _, err := kube.CoreV1().Pods("prod").UpdateStatus(ctx, p, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := c.Collect(ctx, "cluster-1")
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if got := workloadNamed(t, req, "api").GetHealthStatus(); got != clusterv1.WorkloadHealthStatus_WORKLOAD_HEALTH_STATUS_DEGRADED {
+		t.Errorf("api health = %v after restarts increased, want DEGRADED", got)
+	}
+}
+
+func TestCollect_Namespaces(t *testing.T) {
+	kube, _ := newTestCluster(t)
+	c := newTestCollector(t, Config{Kubernetes: kube, Namespaces: []string{"staging"}})
+
+	req, err := c.Collect(context.Background(), "cluster-1")
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if len(req.GetWorkloads()) != 0 || req.GetStatus().GetPodCount() != 0 || len(req.GetEvents()) != 0 {
+		t.Errorf("Collect() reported objects outside the configured namespaces: %v", req)
+	}
+	if req.GetStatus().GetNodeCount() != 2 {
+		t.Errorf("NodeCount = %d, want nodes reported regardless of namespaces", req.GetStatus().GetNodeCount())
+	}
+}
+
+// recordingClusterAPI records ReportClusterStatus requests.
+type recordingClusterAPI struct {
+	clusterv1.ClusterAPIClient
+	reports []*clusterv1.ReportClusterStatusRequest
+	err     error
+}
+
+func (r *recordingClusterAPI) ReportClusterStatus(_ context.Context, req *clusterv1.ReportClusterStatusRequest, _ ...grpc.CallOption) (*clusterv1.ReportClusterStatusResponse, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	r.reports = append(r.reports, req)
+	return &clusterv1.ReportClusterStatusResponse{Ack: true}, nil
+}
+
+func TestPush_EventsSentOnce(t *testing.T) {
+	kube, _ := newTestCluster(t)
+	c := newTestCollector(t, Config{Kubernetes: kube})
+	api := &recordingClusterAPI{}
+	ctx := context.Background()
+
+	if _, err := c.Push(ctx, api, "cluster-1"); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	events := api.reports[0].GetEvents()
+	if len(events) != 1 {
+		t.Fatalf("events = %v, want only the Pod event", events)
+	}
+	ev := events[0]
+	if ev.GetUid() != "ev-1" || ev.GetReason() != "BackOff" || ev.GetCount() != 5 || ev.GetRegarding().GetName() != "db-0" {
+		t.Errorf("event = %v", ev)
+	}
+
+	if _, err := c.Push(ctx, api, "cluster-1"); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	if n := len(api.reports[1].GetEvents()); n != 0 {
+		t.Errorf("second push sent %d events, want 0", n)
+	}
+
+	e, _ := kube.CoreV1().Events("prod").Get(ctx, "db-0.1", metav1.GetOptions{})
+	e.Count = 6
+	if _, err := kube.CoreV1().Events("prod").Update(ctx, e, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Push(ctx, api, "cluster-1"); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	if events := api.reports[2].GetEvents(); len(events) != 1 || events[0].GetCount() != 6 {
+		t.Errorf("third push events = %v, want the recurring event again", events)
+	}
+}
+
+func TestStatusPusher(t *testing.T) {
+	kube, _ := newTestCluster(t)
+	c := newTestCollector(t, Config{Kubernetes: kube})
+	api := &recordingClusterAPI{}
+	pusher := c.StatusPusher(api)
+
+	if err := pusher.PushStatus(context.Background(), &agentv1.Agent{ClusterId: "cluster-1"}); err != nil {
+		t.Fatalf("PushStatus() error = %v", err)
+	}
+	if len(api.reports) != 1 || api.reports[0].GetClusterId() != "cluster-1" {
+		t.Errorf("reports = %v, want one for cluster-1", api.reports)
+	}
+	if err := pusher.PushStatus(context.Background(), &agentv1.Agent{RunnerId: "runner-1"}); err == nil {
+		t.Error("PushStatus() succeeded for a runner agent")
+	}
+}
+
+func TestClusterUID(t *testing.T) {
+	kube, _ := newTestCluster(t)
+	uid, err := ClusterUID(context.Background(), kube)
+	if err != nil || uid != "kube-system-uid" {
+		t.Errorf("ClusterUID() = %q, %v, want kube-system-uid", uid, err)
+	}
+}
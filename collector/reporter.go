@@ -0,0 +1,158 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.admiral.io/sdk/client"
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+)
+
+// DefaultResyncInterval is how often a WorkloadReporter sends every
+// workload, even if none have changed.
+const DefaultResyncInterval = 10 * time.Minute
+
+// ErrNotAcknowledged is returned by WorkloadReporter.Report when the server
+// does not acknowledge a full push.
+var ErrNotAcknowledged = errors.New("collector: workload report not acknowledged")
+
+// ReporterConfig configures a WorkloadReporter.
+type ReporterConfig struct {
+	// ResyncInterval is the time between full pushes. Defaults to
+	// DefaultResyncInterval.
+	ResyncInterval time.Duration
+	// Equal reports whether a workload is unchanged since it was last
+	// acknowledged. Defaults to proto.Equal. Supply a coarser comparison to
+	// skip workloads whose only change is, for example, CPU usage.
+	Equal func(acked, current *clusterv1.WorkloadStatus) bool
+	// Logger for the reporter. Silent by default.
+	Logger client.Logger
+}
+
+// WorkloadReporter sends ReportWorkloadStatus pushes that contain only the
+// workloads that changed since the server last acknowledged them. Every
+// ResyncInterval, and whenever the server answers with Ack false, it sends
+// the full list instead.
+//
+// Workloads are identified by namespace, kind and name. A delta cannot
+// express a removal, so a report missing an acknowledged workload is sent
+// as a full push, which replaces the server's list.
+type WorkloadReporter struct {
+	api clusterv1.ClusterAPIClient
+	cfg ReporterConfig
+	now func() time.Time
+
+	mu        sync.Mutex
+	clusterID string
+	acked     map[string]*clusterv1.WorkloadStatus
+	lastFull  time.Time
+	needFull  bool
+}
+
+// NewWorkloadReporter returns a reporter that pushes through api.
+func NewWorkloadReporter(api clusterv1.ClusterAPIClient, cfg ReporterConfig) *WorkloadReporter {
+	if cfg.ResyncInterval <= 0 {
+		cfg.ResyncInterval = DefaultResyncInterval
+	}
+	if cfg.Equal == nil {
+		cfg.Equal = func(a, b *clusterv1.WorkloadStatus) bool { return proto.Equal(a, b) }
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = client.NewNoOpLogger()
+	}
+	return &WorkloadReporter{api: api, cfg: cfg, now: time.Now, needFull: true}
+}
+
+// Report pushes the current workloads of clusterID, sending only those that
+// changed unless a full push is due. It returns the number of workloads
+// sent; zero means nothing changed and no call was made.
+func (r *WorkloadReporter) Report(ctx context.Context, clusterID string, workloads []*clusterv1.WorkloadStatus) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := make(map[string]*clusterv1.WorkloadStatus, len(workloads))
+	for _, w := range workloads {
+		current[workloadKey(w.GetKind(), w.GetNamespace(), w.GetName())] = w
+	}
+
+	full := r.needFull || clusterID != r.clusterID || r.now().Sub(r.lastFull) >= r.cfg.ResyncInterval || r.removed(current)
+	if !full {
+		var changed []*clusterv1.WorkloadStatus
+		for _, w := range workloads {
+			acked, ok := r.acked[workloadKey(w.GetKind(), w.GetNamespace(), w.GetName())]
+			if !ok || !r.cfg.Equal(acked, w) {
+				changed = append(changed, w)
+			}
+		}
+		if len(changed) == 0 {
+			return 0, nil
+		}
+		ack, err := r.send(ctx, clusterID, changed)
+		if err != nil {
+			return 0, err
+		}
+		if ack {
+			r.remember(changed)
+			return len(changed), nil
+		}
+		r.cfg.Logger.Warnf("workload delta for cluster %s not acknowledged, sending full report", clusterID)
+	}
+
+	ack, err := r.send(ctx, clusterID, workloads)
+	if err != nil {
+		r.needFull = true
+		return 0, err
+	}
+	if !ack {
+		r.needFull = true
+		return 0, ErrNotAcknowledged
+	}
+	r.clusterID = clusterID
+	r.acked = make(map[string]*clusterv1.WorkloadStatus, len(workloads))
+	r.remember(workloads)
+	r.lastFull = r.now()
+	r.needFull = false
+	return len(workloads), nil
+}
+
+// Resync makes the next Report send every workload.
+func (r *WorkloadReporter) Resync() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.needFull = true
+}
+
+func (r *WorkloadReporter) send(ctx context.Context, clusterID string, workloads []*clusterv1.WorkloadStatus) (bool, error) {
+	resp, err := r.api.ReportWorkloadStatus(ctx, &clusterv1.ReportWorkloadStatusRequest{
+		ClusterId:  clusterID,
+		Workloads:  workloads,
+		ReportedAt: timestamppb.New(r.now()),
+	})
+	if err != nil {
+		return false, err
+	}
+	return resp.GetAck(), nil
+}
+
+// remember records workloads as acknowledged. The caller must hold mu.
+func (r *WorkloadReporter) remember(workloads []*clusterv1.WorkloadStatus) {
+	for _, w := range workloads {
+		r.acked[workloadKey(w.GetKind(), w.GetNamespace(), w.GetName())] = proto.Clone(w).(*clusterv1.WorkloadStatus)
+	}
+}
+
+// removed reports whether an acknowledged workload is missing from current.
+// The caller must hold mu.
+func (r *WorkloadReporter) removed(current map[string]*clusterv1.WorkloadStatus) bool {
+	for key := range r.acked {
+		if _, ok := current[key]; !ok {
+			return true
+		}
+	}
+	return false
+}
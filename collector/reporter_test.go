@@ -0,0 +1,167 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+)
+
+// workloadAPI records ReportWorkloadStatus requests and answers with the
+// queued acks, then true.
+type workloadAPI struct {
+	clusterv1.ClusterAPIClient
+	pushes [][]string
+	acks   []bool
+	err    error
+}
+
+func (a *workloadAPI) ReportWorkloadStatus(_ context.Context, req *clusterv1.ReportWorkloadStatusRequest, _ ...grpc.CallOption) (*clusterv1.ReportWorkloadStatusResponse, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+	var names []string
+	for _, w := range req.GetWorkloads() {
+		names = append(names, w.GetName())
+	}
+	a.pushes = append(a.pushes, names)
+	ack := true
+	if len(a.acks) > 0 {
+		ack, a.acks = a.acks[0], a.acks[1:]
+	}
+	return &clusterv1.ReportWorkloadStatusResponse{Ack: ack}, nil
+}
+
+func (a *workloadAPI) last() []string {
+	if len(a.pushes) == 0 {
+		return nil
+	}
+	return a.pushes[len(a.pushes)-1]
+}
+
+func testWorkloads(ready ...int32) []*clusterv1.WorkloadStatus {
+	names := []string{"api", "db", "web"}
+	out := make([]*clusterv1.WorkloadStatus, len(ready))
+	for i, r := range ready {
+		out[i] = &clusterv1.WorkloadStatus{Namespace: "prod", Kind: "Deployment", Name: names[i], ReplicasDesired: 2, ReplicasReady: r}
+	}
+	return out
+}
+
+func newTestReporter(api *workloadAPI, now *time.Time) *WorkloadReporter {
+	r := NewWorkloadReporter(api, ReporterConfig{ResyncInterval: 10 * time.Minute})
+	r.now = func() time.Time { return *now }
+	return r
+}
+
+func TestWorkloadReporter_SendsOnlyChanges(t *testing.T) {
+	api := &workloadAPI{}
+	now := testNow
+	r := newTestReporter(api, &now)
+	ctx := context.Background()
+
+	if n, err := r.Report(ctx, "c1", testWorkloads(2, 2, 2)); err != nil || n != 3 {
+		t.Fatalf("first Report() = %d, %v, want full push of 3", n, err)
+	}
+	if n, err := r.Report(ctx, "c1", testWorkloads(2, 1, 2)); err != nil || n != 1 || api.last()[0] != "db" {
+		t.Fatalf("Report() = %d, %v, sent %v, want only db", n, err, api.last())
+	}
+	if n, err := r.Report(ctx, "c1", testWorkloads(2, 1, 2)); err != nil || n != 0 || len(api.pushes) != 2 {
+		t.Fatalf("Report() = %d, %v after %d pushes, want no call when unchanged", n, err, len(api.pushes))
+	}
+
+	now = now.Add(10 * time.Minute)
+	if n, err := r.Report(ctx, "c1", testWorkloads(2, 1, 2)); err != nil || n != 3 {
+		t.Errorf("Report() = %d, %v, want full resync after ResyncInterval", n, err)
+	}
+}
+
+func TestWorkloadReporter_FullPushWhenNotAcked(t *testing.T) {
+	api := &workloadAPI{}
+	now := testNow
+	r := newTestReporter(api, &now)
+	ctx := context.Background()
+
+	if _, err := r.Report(ctx, "c1", testWorkloads(2, 2, 2)); err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+	api.acks = []bool{false}
+	n, err := r.Report(ctx, "c1", testWorkloads(1, 2, 2))
+	if err != nil || n != 3 {
+		t.Fatalf("Report() = %d, %v, want fallback full push of 3", n, err)
+	}
+	if len(api.pushes) != 3 || len(api.pushes[1]) != 1 || len(api.pushes[2]) != 3 {
+		t.Errorf("pushes = %v, want delta then full", api.pushes)
+	}
+
+	api.acks = []bool{false, false}
+	if _, err := r.Report(ctx, "c1", testWorkloads(0, 2, 2)); !errors.Is(err, ErrNotAcknowledged) {
+		t.Fatalf("Report() error = %v, want ErrNotAcknowledged", err)
+	}
+	if n, _ := r.Report(ctx, "c1", testWorkloads(0, 2, 2)); n != 3 {
+		t.Errorf("Report() = %d after an unacknowledged full push, want 3", n)
+	}
+}
+
+func TestWorkloadReporter_FullPushOnRemoval(t *testing.T) {
+	api := &workloadAPI{}
+	now := testNow
+	r := newTestReporter(api, &now)
+	ctx := context.Background()
+
+	if _, err := r.Report(ctx, "c1", testWorkloads(2, 2, 2)); err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+	if n, err := r.Report(ctx, "c1", testWorkloads(2, 1, 2)); err != nil || n != 1 {
+		t.Fatalf("Report() = %d, %v, want delta of 1", n, err)
+	}
+	// web is deleted; nothing else changed.
+	n, err := r.Report(ctx, "c1", testWorkloads(2, 1))
+	if err != nil || n != 2 {
+		t.Fatalf("Report() = %d, %v after deletion, want full push of 2", n, err)
+	}
+	if got := api.last(); len(got) != 2 || got[0] != "api" || got[1] != "db" {
+		t.Errorf("sent %v, want [api db]", got)
+	}
+	if n, _ := r.Report(ctx, "c1", testWorkloads(2, 1)); n != 0 {
+		t.Errorf("Report() = %d after the removal was sent, want 0", n)
+	}
+}
+
+func TestWorkloadReporter_ErrorsResendChanges(t *testing.T) {
+	api := &workloadAPI{}
+	now := testNow
+	r := newTestReporter(api, &now)
+	ctx := context.Background()
+
+	if _, err := r.Report(ctx, "c1", testWorkloads(2, 2)); err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+	api.err = status.Error(codes.Unavailable, "down")
+	if _, err := r.Report(ctx, "c1", testWorkloads(1, 2)); status.Code(err) != codes.Unavailable {
+		t.Fatalf("Report() error = %v, want Unavailable", err)
+	}
+	api.err = nil
+	if n, _ := r.Report(ctx, "c1", testWorkloads(1, 2)); n != 1 {
+		t.Errorf("Report() = %d, want the unsent change again", n)
+	}
+
+	// A workload that disappears and comes back is sent again.
+	if n, _ := r.Report(ctx, "c1", testWorkloads(1)); n != 1 {
+		t.Errorf("Report() = %d after removal, want a full push of 1", n)
+	}
+	if n, _ := r.Report(ctx, "c1", testWorkloads(1, 2)); n != 1 || api.last()[0] != "db" {
+		t.Errorf("Report() = %d, sent %v, want db re-sent", n, api.last())
+	}
+
+	r.Resync()
+	if n, _ := r.Report(ctx, "c1", testWorkloads(1, 2)); n != 2 {
+		t.Errorf("Report() = %d after Resync, want 2", n)
+	}
+}
@@ -0,0 +1,295 @@
+package collector
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+)
+
+// workload accumulates the status of one Deployment, StatefulSet or
+// DaemonSet from its pods.
+type workload struct {
+	status     *clusterv1.WorkloadStatus
+	containers map[string]*clusterv1.ContainerStatus
+	pods       int
+	crashing   int
+	stuck      bool
+	restarts   int32
+}
+
+func newWorkload(kind string, meta metav1.ObjectMeta, desired, ready, available int32) *workload {
+	return &workload{
+		status: &clusterv1.WorkloadStatus{
+			Namespace:         meta.Namespace,
+			Name:              meta.Name,
+			Kind:              kind,
+			Labels:            maps.Clone(meta.Labels),
+			ReplicasDesired:   desired,
+			ReplicasReady:     ready,
+			ReplicasAvailable: available,
+		},
+		containers: map[string]*clusterv1.ContainerStatus{},
+	}
+}
+
+// workloadKey identifies a workload across collections.
+func workloadKey(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+// collectWorkloads lists workload controllers and attributes pods, their
+// containers and their resource usage to the workload that owns them.
+func (c *Collector) collectWorkloads(ctx context.Context, pods []corev1.Pod) ([]*clusterv1.WorkloadStatus, error) {
+	apps := c.cfg.Kubernetes.AppsV1()
+	workloads := map[string]*workload{}
+
+	deployments, err := eachNamespace(ctx, c.cfg.Namespaces, func(ctx context.Context, ns string) ([]appsv1.Deployment, error) {
+		l, err := apps.Deployments(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return l.Items, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("collector: list deployments: %w", err)
+	}
+	for _, d := range deployments {
+		w := newWorkload("Deployment", d.ObjectMeta, replicas(d.Spec.Replicas), d.Status.ReadyReplicas, d.Status.AvailableReplicas)
+		workloads[workloadKey("Deployment", d.Namespace, d.Name)] = w
+	}
+
+	statefulSets, err := eachNamespace(ctx, c.cfg.Namespaces, func(ctx context.Context, ns string) ([]appsv1.StatefulSet, error) {
+		l, err := apps.StatefulSets(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return l.Items, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("collector: list statefulsets: %w", err)
+	}
+	for _, s := range statefulSets {
+		w := newWorkload("StatefulSet", s.ObjectMeta, replicas(s.Spec.Replicas), s.Status.ReadyReplicas, s.Status.AvailableReplicas)
+		workloads[workloadKey("StatefulSet", s.Namespace, s.Name)] = w
+	}
+
+	daemonSets, err := eachNamespace(ctx, c.cfg.Namespaces, func(ctx context.Context, ns string) ([]appsv1.DaemonSet, error) {
+		l, err := apps.DaemonSets(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return l.Items, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("collector: list daemonsets: %w", err)
+	}
+	for _, d := range daemonSets {
+		w := newWorkload("DaemonSet", d.ObjectMeta, d.Status.DesiredNumberScheduled, d.Status.NumberReady, d.Status.NumberAvailable)
+		workloads[workloadKey("DaemonSet", d.Namespace, d.Name)] = w
+	}
+
+	// Deployments own pods through ReplicaSets.
+	replicaSets, err := eachNamespace(ctx, c.cfg.Namespaces, func(ctx context.Context, ns string) ([]appsv1.ReplicaSet, error) {
+		l, err := apps.ReplicaSets(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return l.Items, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("collector: list replicasets: %w", err)
+	}
+	deploymentOf := map[string]string{}
+	for _, rs := range replicaSets {
+		if ref := metav1.GetControllerOf(&rs); ref != nil && ref.Kind == "Deployment" {
+			deploymentOf[rs.Namespace+"/"+rs.Name] = ref.Name
+		}
+	}
+
+	usage := c.podUsage(ctx)
+	now := c.now()
+	for i := range pods {
+		pod := &pods[i]
+		ref := metav1.GetControllerOf(pod)
+		if ref == nil {
+			continue
+		}
+		kind, name := ref.Kind, ref.Name
+		if kind == "ReplicaSet" {
+			kind, name = "Deployment", deploymentOf[pod.Namespace+"/"+ref.Name]
+		}
+		w, ok := workloads[workloadKey(kind, pod.Namespace, name)]
+		if !ok {
+			continue
+		}
+		w.addPod(pod, usage[pod.Namespace+"/"+pod.Name], now)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]*clusterv1.WorkloadStatus, 0, len(workloads))
+	for key, w := range workloads {
+		prev, seen := c.restarts[key]
+		w.status.Containers = slices.SortedFunc(maps.Values(w.containers), func(a, b *clusterv1.ContainerStatus) int {
+			return cmp.Compare(a.GetName(), b.GetName())
+		})
+		w.status.HealthStatus = w.health(seen && w.restarts > prev)
+		out = append(out, w.status)
+	}
+	// Only remember workloads that still exist.
+	restarts := make(map[string]int32, len(workloads))
+	for key, w := range workloads {
+		restarts[key] = w.restarts
+	}
+	c.restarts = restarts
+
+	slices.SortFunc(out, func(a, b *clusterv1.WorkloadStatus) int {
+		return cmp.Or(
+			cmp.Compare(a.GetNamespace(), b.GetNamespace()),
+			cmp.Compare(a.GetKind(), b.GetKind()),
+			cmp.Compare(a.GetName(), b.GetName()),
+		)
+	})
+	return out, nil
+}
+
+// addPod folds a pod's requests, limits, usage and container states into
+// the workload.
+func (w *workload) addPod(pod *corev1.Pod, used corev1.ResourceList, now time.Time) {
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return
+	}
+	w.pods++
+	if pod.Status.Phase == corev1.PodPending && now.Sub(pod.CreationTimestamp.Time) > pendingTooLong {
+		w.stuck = true
+	}
+
+	s := w.status
+	for _, ct := range pod.Spec.Containers {
+		s.CpuRequestsMillicores += ct.Resources.Requests.Cpu().MilliValue()
+		s.CpuLimitsMillicores += ct.Resources.Limits.Cpu().MilliValue()
+		s.MemoryRequestsBytes += ct.Resources.Requests.Memory().Value()
+		s.MemoryLimitsBytes += ct.Resources.Limits.Memory().Value()
+		if _, ok := w.containers[ct.Name]; !ok {
+			w.containers[ct.Name] = &clusterv1.ContainerStatus{Name: ct.Name, Image: ct.Image, Ready: true}
+		}
+	}
+	s.CpuUsedMillicores += used.Cpu().MilliValue()
+	s.MemoryUsedBytes += used.Memory().Value()
+
+	statuses := make(map[string]corev1.ContainerStatus, len(pod.Status.ContainerStatuses))
+	for _, cs := range pod.Status.ContainerStatuses {
+		statuses[cs.Name] = cs
+	}
+	crashing := false
+	for _, ct := range pod.Spec.Containers {
+		agg := w.containers[ct.Name]
+		cs, ok := statuses[ct.Name]
+		if !ok {
+			agg.Ready = false
+			agg.State = worseState(agg.State, "waiting")
+			continue
+		}
+		agg.RestartCount += cs.RestartCount
+		w.restarts += cs.RestartCount
+		agg.Ready = agg.Ready && cs.Ready
+		agg.State = worseState(agg.State, containerState(cs.State))
+		if cs.State.Waiting != nil && cs.State.Waiting.Reason == "CrashLoopBackOff" {
+			crashing = true
+		}
+	}
+	if crashing {
+		w.crashing++
+	}
+}
+
+// health derives the workload's health from its replicas and pods, as
+// documented on WorkloadHealthStatus.
+func (w *workload) health(restartsIncreasing bool) clusterv1.WorkloadHealthStatus {
+	s := w.status
+	switch {
+	case s.GetReplicasDesired() == 0:
+		return clusterv1.WorkloadHealthStatus_WORKLOAD_HEALTH_STATUS_HEALTHY
+	case s.GetReplicasReady() == 0, w.pods > 0 && w.crashing == w.pods:
+		return clusterv1.WorkloadHealthStatus_WORKLOAD_HEALTH_STATUS_ERROR
+	case s.GetReplicasReady() < s.GetReplicasDesired(), w.crashing > 0, w.stuck, restartsIncreasing:
+		return clusterv1.WorkloadHealthStatus_WORKLOAD_HEALTH_STATUS_DEGRADED
+	default:
+		return clusterv1.WorkloadHealthStatus_WORKLOAD_HEALTH_STATUS_HEALTHY
+	}
+}
+
+// containerState returns "running", "waiting" or "terminated".
+func containerState(s corev1.ContainerState) string {
+	switch {
+	case s.Running != nil:
+		return "running"
+	case s.Terminated != nil:
+		return "terminated"
+	default:
+		return "waiting"
+	}
+}
+
+// worseState returns whichever of a and b indicates the less healthy
+// container, so one crashing replica shows through the aggregate.
+func worseState(a, b string) string {
+	rank := map[string]int{"": 0, "running": 1, "terminated": 2, "waiting": 3}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
+
+// podUsage returns CPU and memory usage per pod, keyed by namespace/name.
+// It returns nil when no metrics client is configured or metrics-server
+// is unavailable.
+func (c *Collector) podUsage(ctx context.Context) map[string]corev1.ResourceList {
+	if c.cfg.Metrics == nil {
+		return nil
+	}
+	usage := map[string]corev1.ResourceList{}
+	namespaces := c.cfg.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+	for _, ns := range namespaces {
+		l, err := c.cfg.Metrics.MetricsV1beta1().PodMetricses(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			c.cfg.Logger.Warnf("failed to get pod metrics: %v", err)
+			return nil
+		}
+		for _, m := range l.Items {
+			total := corev1.ResourceList{}
+			for _, ct := range m.Containers {
+				addResources(total, ct.Usage)
+			}
+			usage[m.Namespace+"/"+m.Name] = total
+		}
+	}
+	return usage
+}
+
+func addResources(total, add corev1.ResourceList) {
+	for name, q := range add {
+		sum := total[name]
+		sum.Add(q)
+		total[name] = sum
+	}
+}
+
+func replicas(r *int32) int32 {
+	if r == nil {
+		return 1
+	}
+	return *r
+}
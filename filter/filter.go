@@ -0,0 +1,291 @@
+// Package filter builds and validates expressions in the PEG filter DSL
+// accepted by the Filter field of every Admiral List request.
+//
+// # Building Filters
+//
+// Expressions are composed with a typed builder and rendered with String:
+//
+//	f := filter.And(
+//	    filter.Field("health_status").Eq(clusterv1.ClusterHealthStatus_CLUSTER_HEALTH_STATUS_HEALTHY),
+//	    filter.Field("labels.region").Eq("us-east-1"),
+//	    filter.Field("display_name").Glob("prod-*"),
+//	)
+//	req := &clusterv1.ListClustersRequest{Filter: f.String()}
+//	// health_status = "HEALTHY" AND labels.region = "us-east-1" AND display_name = "prod-*"
+//
+// # Validating Filters
+//
+// Parse checks the syntax of a filter string. A Schema additionally checks
+// field names and value types against the resource returned by a List RPC:
+//
+//	schema, _ := filter.SchemaFor(&clusterv1.ListClustersRequest{})
+//	if err := schema.Validate(`helth_status = "healthy"`); err != nil {
+//	    // filter: unknown field "helth_status" for admiral.api.cluster.v1.Cluster
+//	}
+//
+// # Grammar
+//
+//	expr       = term { "OR" term }
+//	term       = factor { "AND" factor }
+//	factor     = "NOT" factor | "(" expr ")" | comparison
+//	comparison = field operator value
+//	field      = ident { "." ident }
+//	operator   = "=" | "!=" | "<" | "<=" | ">" | ">="
+//	value      = string | number | "true" | "false"
+//
+// Keywords are case-insensitive. A string compared with "=" that contains
+// "*" is a glob pattern, where "*" matches any run of characters.
+package filter
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Operator is a comparison operator.
+type Operator int
+
+const (
+	// OpEq matches values equal to the operand.
+	OpEq Operator = iota
+	// OpNe matches values not equal to the operand.
+	OpNe
+	// OpLt matches values less than the operand.
+	OpLt
+	// OpLe matches values less than or equal to the operand.
+	OpLe
+	// OpGt matches values greater than the operand.
+	OpGt
+	// OpGe matches values greater than or equal to the operand.
+	OpGe
+	// OpGlob matches strings against a pattern where "*" is a wildcard.
+	// It is written as "=" in the DSL.
+	OpGlob
+)
+
+// String returns the DSL token for the operator.
+func (o Operator) String() string {
+	switch o {
+	case OpEq, OpGlob:
+		return "="
+	case OpNe:
+		return "!="
+	case OpLt:
+		return "<"
+	case OpLe:
+		return "<="
+	case OpGt:
+		return ">"
+	case OpGe:
+		return ">="
+	default:
+		return fmt.Sprintf("Operator(%d)", int(o))
+	}
+}
+
+// Expr is a node in a filter expression.
+type Expr interface {
+	// String renders the expression in the filter DSL.
+	String() string
+	// precedence orders nodes for parenthesization when rendering.
+	precedence() int
+}
+
+const (
+	precOr = iota + 1
+	precAnd
+	precUnary
+)
+
+// Comparison compares a field with a literal value. Value is a string,
+// int64, float64 or bool.
+type Comparison struct {
+	Field string
+	Op    Operator
+	Value any
+}
+
+func (c *Comparison) String() string {
+	return c.Field + " " + c.Op.String() + " " + formatValue(c.Value)
+}
+
+func (c *Comparison) precedence() int { return precUnary }
+
+// AndExpr matches when every operand matches.
+type AndExpr struct {
+	Exprs []Expr
+}
+
+func (a *AndExpr) String() string { return joinExprs(a.Exprs, " AND ", precAnd) }
+
+func (a *AndExpr) precedence() int { return precAnd }
+
+// OrExpr matches when any operand matches.
+type OrExpr struct {
+	Exprs []Expr
+}
+
+func (o *OrExpr) String() string { return joinExprs(o.Exprs, " OR ", precOr) }
+
+func (o *OrExpr) precedence() int { return precOr }
+
+// NotExpr negates its operand.
+type NotExpr struct {
+	Expr Expr
+}
+
+func (n *NotExpr) String() string {
+	if n.Expr.precedence() < precUnary {
+		return "NOT (" + n.Expr.String() + ")"
+	}
+	return "NOT " + n.Expr.String()
+}
+
+func (n *NotExpr) precedence() int { return precUnary }
+
+func joinExprs(exprs []Expr, sep string, prec int) string {
+	parts := make([]string, len(exprs))
+	for i, e := range exprs {
+		if e.precedence() < prec {
+			parts[i] = "(" + e.String() + ")"
+		} else {
+			parts[i] = e.String()
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+// FieldRef starts a comparison on a field.
+type FieldRef string
+
+// Field returns a reference to a field path such as "display_name" or
+// "labels.region".
+func Field(path string) FieldRef {
+	return FieldRef(path)
+}
+
+// Eq builds `field = value`.
+func (f FieldRef) Eq(value any) *Comparison { return f.compare(OpEq, value) }
+
+// Ne builds `field != value`.
+func (f FieldRef) Ne(value any) *Comparison { return f.compare(OpNe, value) }
+
+// Lt builds `field < value`.
+func (f FieldRef) Lt(value any) *Comparison { return f.compare(OpLt, value) }
+
+// Le builds `field <= value`.
+func (f FieldRef) Le(value any) *Comparison { return f.compare(OpLe, value) }
+
+// Gt builds `field > value`.
+func (f FieldRef) Gt(value any) *Comparison { return f.compare(OpGt, value) }
+
+// Ge builds `field >= value`.
+func (f FieldRef) Ge(value any) *Comparison { return f.compare(OpGe, value) }
+
+// Glob builds `field = "pattern"`, where "*" in pattern matches any run of
+// characters.
+func (f FieldRef) Glob(pattern string) *Comparison {
+	return &Comparison{Field: string(f), Op: OpGlob, Value: pattern}
+}
+
+func (f FieldRef) compare(op Operator, value any) *Comparison {
+	return &Comparison{Field: string(f), Op: op, Value: normalizeValue(value)}
+}
+
+// And combines expressions so that all must match. A single expression is
+// returned unchanged.
+func And(exprs ...Expr) Expr {
+	if len(exprs) == 1 {
+		return exprs[0]
+	}
+	return &AndExpr{Exprs: exprs}
+}
+
+// Or combines expressions so that any may match. A single expression is
+// returned unchanged.
+func Or(exprs ...Expr) Expr {
+	if len(exprs) == 1 {
+		return exprs[0]
+	}
+	return &OrExpr{Exprs: exprs}
+}
+
+// Not returns `NOT expr`.
+func Not(expr Expr) Expr {
+	return &NotExpr{Expr: expr}
+}
+
+// normalizeValue converts builder arguments into the literal types used by
+// the AST. Enums become their short value name (e.g. "HEALTHY" for
+// CLUSTER_HEALTH_STATUS_HEALTHY) and times are rendered as RFC 3339.
+func normalizeValue(v any) any {
+	switch v := v.(type) {
+	case string, bool, int64, float64:
+		return v
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case uint64:
+		if v > math.MaxInt64 {
+			return float64(v)
+		}
+		return int64(v)
+	case float32:
+		return float64(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case protoreflect.Enum:
+		return EnumValueName(v.Descriptor(), v.Number())
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// EnumValueName returns the name of an enum value with the enum's type
+// prefix removed, e.g. "HEALTHY" for CLUSTER_HEALTH_STATUS_HEALTHY. Unknown
+// numbers are rendered as decimal.
+func EnumValueName(ed protoreflect.EnumDescriptor, n protoreflect.EnumNumber) string {
+	ev := ed.Values().ByNumber(n)
+	if ev == nil {
+		return strconv.Itoa(int(n))
+	}
+	return strings.TrimPrefix(string(ev.Name()), enumPrefix(ed))
+}
+
+// enumPrefix returns the UPPER_SNAKE form of the enum type name followed by
+// an underscore, e.g. "CLUSTER_HEALTH_STATUS_" for ClusterHealthStatus.
+func enumPrefix(ed protoreflect.EnumDescriptor) string {
+	var b strings.Builder
+	for i, r := range string(ed.Name()) {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			b.WriteByte('_')
+		}
+		b.WriteRune(r)
+	}
+	return strings.ToUpper(b.String()) + "_"
+}
+
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return strconv.Quote(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return strconv.Quote(fmt.Sprint(v))
+	}
+}
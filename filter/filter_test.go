@@ -0,0 +1,76 @@
+package filter
+
+import (
+	"testing"
+	"time"
+
+	agentv1 "go.admiral.io/sdk/proto/agent/v1"
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+)
+
+func TestBuilder_String(t *testing.T) {
+	tests := []struct {
+		name string
+		expr Expr
+		want string
+	}{
+		{
+			name: "string equality",
+			expr: Field("status").Eq("active"),
+			want: `status = "active"`,
+		},
+		{
+			name: "glob",
+			expr: Field("display_name").Glob("ci-*"),
+			want: `display_name = "ci-*"`,
+		},
+		{
+			name: "numbers and bools",
+			expr: And(Field("node_count").Ge(3), Field("ready").Eq(true)),
+			want: `node_count >= 3 AND ready = true`,
+		},
+		{
+			name: "enum uses short name",
+			expr: Field("status").Eq(agentv1.AgentStatus_AGENT_STATUS_ONLINE),
+			want: `status = "ONLINE"`,
+		},
+		{
+			name: "time is RFC 3339",
+			expr: Field("created_at").Gt(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)),
+			want: `created_at > "2026-01-02T03:04:05Z"`,
+		},
+		{
+			name: "or inside and is parenthesized",
+			expr: And(Field("a").Eq("1"), Or(Field("b").Eq("2"), Field("c").Ne("3"))),
+			want: `a = "1" AND (b = "2" OR c != "3")`,
+		},
+		{
+			name: "not of compound",
+			expr: Not(And(Field("a").Eq("1"), Field("b").Eq("2"))),
+			want: `NOT (a = "1" AND b = "2")`,
+		},
+		{
+			name: "quotes are escaped",
+			expr: Field("display_name").Eq(`say "hi"`),
+			want: `display_name = "say \"hi\""`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.expr.String(); got != tt.want {
+				t.Errorf("String() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnumValueName(t *testing.T) {
+	healthy := clusterv1.ClusterHealthStatus_CLUSTER_HEALTH_STATUS_HEALTHY
+	if got := EnumValueName(healthy.Descriptor(), healthy.Number()); got != "HEALTHY" {
+		t.Errorf("EnumValueName() = %q, want %q", got, "HEALTHY")
+	}
+	if got := EnumValueName(healthy.Descriptor(), 99); got != "99" {
+		t.Errorf("EnumValueName(99) = %q, want %q", got, "99")
+	}
+}
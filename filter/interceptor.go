@@ -0,0 +1,59 @@
+package filter
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// schemas caches the Schema for each List request type.
+var schemas sync.Map // protoreflect.FullName -> *Schema
+
+// ValidateRequest checks the Filter field of a List request against the
+// resource it lists. Messages without a string "filter" field, and requests
+// with an empty filter, are accepted.
+func ValidateRequest(req proto.Message) error {
+	msg := req.ProtoReflect()
+	fd := msg.Descriptor().Fields().ByName("filter")
+	if fd == nil || fd.Kind() != protoreflect.StringKind || fd.IsList() {
+		return nil
+	}
+	filter := msg.Get(fd).String()
+	if filter == "" {
+		return nil
+	}
+
+	name := msg.Descriptor().FullName()
+	cached, ok := schemas.Load(name)
+	if !ok {
+		schema, err := SchemaFor(req)
+		if err != nil {
+			return err
+		}
+		cached, _ = schemas.LoadOrStore(name, schema)
+	}
+	return cached.(*Schema).Validate(filter)
+}
+
+// UnaryClientInterceptor returns an interceptor that runs ValidateRequest
+// on every outgoing request and fails with codes.InvalidArgument, without
+// contacting the server, when the filter is invalid.
+//
+// It is for plain gRPC connections only. Admiral clients should set
+// client.ConnectionOptions.ValidateRequests instead, which runs the same
+// check on every transport.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if msg, ok := req.(proto.Message); ok {
+			if err := ValidateRequest(msg); err != nil {
+				return status.Error(codes.InvalidArgument, err.Error())
+			}
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
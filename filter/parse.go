@@ -0,0 +1,316 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrSyntax is returned, wrapped in a *SyntaxError, when a filter string
+// cannot be parsed.
+var ErrSyntax = errors.New("filter: syntax error")
+
+// SyntaxError describes where and why a filter string failed to parse.
+type SyntaxError struct {
+	// Offset is the byte offset in the input where the error was detected.
+	Offset int
+	// Msg describes the problem.
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("filter: syntax error at offset %d: %s", e.Offset, e.Msg)
+}
+
+// Unwrap allows errors.Is(err, ErrSyntax).
+func (e *SyntaxError) Unwrap() error {
+	return ErrSyntax
+}
+
+// Parse parses a filter string into an expression tree. An empty or
+// whitespace-only string returns a nil Expr and no error.
+func Parse(input string) (Expr, error) {
+	p := &parser{lex: lexer{input: input}}
+	p.next()
+	if p.tok.kind == tokEOF {
+		return nil, p.err
+	}
+	expr := p.parseOr()
+	if p.err == nil && p.tok.kind != tokEOF {
+		p.fail(p.tok.pos, "unexpected %s", p.tok)
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return expr, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOp
+	tokLParen
+	tokRParen
+	tokAnd
+	tokOr
+	tokNot
+	tokTrue
+	tokFalse
+)
+
+type token struct {
+	kind tokenKind
+	pos  int
+	text string
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of input"
+	case tokString:
+		return "string " + t.text
+	default:
+		return strconv.Quote(t.text)
+	}
+}
+
+type lexer struct {
+	input string
+	pos   int
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.input) {
+		r, size := utf8.DecodeRuneInString(l.input[l.pos:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		l.pos += size
+	}
+	if l.pos >= len(l.input) {
+		return token{kind: tokEOF, pos: l.pos}, nil
+	}
+
+	start := l.pos
+	c := l.input[l.pos]
+	switch {
+	case c == '(':
+		l.pos++
+		return token{kind: tokLParen, pos: start, text: "("}, nil
+	case c == ')':
+		l.pos++
+		return token{kind: tokRParen, pos: start, text: ")"}, nil
+	case c == '=':
+		l.pos++
+		return token{kind: tokOp, pos: start, text: "="}, nil
+	case c == '!' || c == '<' || c == '>':
+		l.pos++
+		if l.pos < len(l.input) && l.input[l.pos] == '=' {
+			l.pos++
+		} else if c == '!' {
+			return token{}, &SyntaxError{Offset: start, Msg: `expected "!="`}
+		}
+		return token{kind: tokOp, pos: start, text: l.input[start:l.pos]}, nil
+	case c == '"':
+		return l.lexString()
+	case c == '-' || (c >= '0' && c <= '9'):
+		return l.lexNumber()
+	case c == '_' || isLetter(c):
+		for l.pos < len(l.input) && isIdentChar(l.input[l.pos]) {
+			l.pos++
+		}
+		text := l.input[start:l.pos]
+		tok := token{kind: tokIdent, pos: start, text: text}
+		switch strings.ToUpper(text) {
+		case "AND":
+			tok.kind = tokAnd
+		case "OR":
+			tok.kind = tokOr
+		case "NOT":
+			tok.kind = tokNot
+		case "TRUE":
+			tok.kind = tokTrue
+		case "FALSE":
+			tok.kind = tokFalse
+		}
+		return tok, nil
+	default:
+		return token{}, &SyntaxError{Offset: start, Msg: fmt.Sprintf("unexpected character %q", c)}
+	}
+}
+
+func (l *lexer) lexString() (token, error) {
+	start := l.pos
+	l.pos++ // opening quote
+	for l.pos < len(l.input) {
+		switch l.input[l.pos] {
+		case '\\':
+			l.pos += 2
+		case '"':
+			l.pos++
+			text := l.input[start:l.pos]
+			if _, err := strconv.Unquote(text); err != nil {
+				return token{}, &SyntaxError{Offset: start, Msg: "invalid escape in string"}
+			}
+			return token{kind: tokString, pos: start, text: text}, nil
+		default:
+			l.pos++
+		}
+	}
+	return token{}, &SyntaxError{Offset: start, Msg: "unterminated string"}
+}
+
+func (l *lexer) lexNumber() (token, error) {
+	start := l.pos
+	if l.input[l.pos] == '-' {
+		l.pos++
+	}
+	for l.pos < len(l.input) && (isDigit(l.input[l.pos]) || l.input[l.pos] == '.') {
+		l.pos++
+	}
+	text := l.input[start:l.pos]
+	if _, err := strconv.ParseFloat(text, 64); err != nil {
+		return token{}, &SyntaxError{Offset: start, Msg: fmt.Sprintf("invalid number %q", text)}
+	}
+	return token{kind: tokNumber, pos: start, text: text}, nil
+}
+
+func isLetter(c byte) bool { return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+// isIdentChar allows dotted field paths and label keys such as
+// labels.app.kubernetes.io/name.
+func isIdentChar(c byte) bool {
+	return isLetter(c) || isDigit(c) || c == '_' || c == '.' || c == '-' || c == '/'
+}
+
+// parser is a recursive-descent parser over the lexer's token stream. The
+// first error stops parsing; subsequent calls are no-ops.
+type parser struct {
+	lex lexer
+	tok token
+	err error
+}
+
+func (p *parser) next() {
+	if p.err != nil {
+		return
+	}
+	tok, err := p.lex.next()
+	if err != nil {
+		p.err = err
+		p.tok = token{kind: tokEOF, pos: p.lex.pos}
+		return
+	}
+	p.tok = tok
+}
+
+func (p *parser) fail(offset int, format string, args ...any) {
+	if p.err == nil {
+		p.err = &SyntaxError{Offset: offset, Msg: fmt.Sprintf(format, args...)}
+	}
+	p.tok = token{kind: tokEOF, pos: offset}
+}
+
+func (p *parser) parseOr() Expr {
+	exprs := []Expr{p.parseAnd()}
+	for p.err == nil && p.tok.kind == tokOr {
+		p.next()
+		exprs = append(exprs, p.parseAnd())
+	}
+	return Or(exprs...)
+}
+
+func (p *parser) parseAnd() Expr {
+	exprs := []Expr{p.parseFactor()}
+	for p.err == nil && p.tok.kind == tokAnd {
+		p.next()
+		exprs = append(exprs, p.parseFactor())
+	}
+	return And(exprs...)
+}
+
+func (p *parser) parseFactor() Expr {
+	switch p.tok.kind {
+	case tokNot:
+		p.next()
+		return Not(p.parseFactor())
+	case tokLParen:
+		open := p.tok.pos
+		p.next()
+		expr := p.parseOr()
+		if p.err == nil && p.tok.kind != tokRParen {
+			p.fail(open, "unclosed parenthesis")
+		}
+		p.next()
+		return expr
+	case tokIdent:
+		return p.parseComparison()
+	default:
+		p.fail(p.tok.pos, "expected field name, got %s", p.tok)
+		return nil
+	}
+}
+
+func (p *parser) parseComparison() Expr {
+	field := p.tok.text
+	p.next()
+	if p.tok.kind != tokOp {
+		p.fail(p.tok.pos, "expected operator after %q, got %s", field, p.tok)
+		return nil
+	}
+	op := parseOperator(p.tok.text)
+	p.next()
+
+	var value any
+	switch p.tok.kind {
+	case tokString:
+		s, _ := strconv.Unquote(p.tok.text)
+		value = s
+		if op == OpEq && strings.Contains(s, "*") {
+			op = OpGlob
+		}
+	case tokNumber:
+		if n, err := strconv.ParseInt(p.tok.text, 10, 64); err == nil {
+			value = n
+		} else {
+			f, _ := strconv.ParseFloat(p.tok.text, 64)
+			value = f
+		}
+	case tokTrue:
+		value = true
+	case tokFalse:
+		value = false
+	default:
+		p.fail(p.tok.pos, "expected value after %q %s, got %s", field, op, p.tok)
+		return nil
+	}
+	p.next()
+	return &Comparison{Field: field, Op: op, Value: value}
+}
+
+func parseOperator(s string) Operator {
+	switch s {
+	case "!=":
+		return OpNe
+	case "<":
+		return OpLt
+	case "<=":
+		return OpLe
+	case ">":
+		return OpGt
+	case ">=":
+		return OpGe
+	default:
+		return OpEq
+	}
+}
@@ -0,0 +1,86 @@
+package filter
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse_RoundTrip(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`status = "active"`, `status = "active"`},
+		{`display_name = "ci-*"`, `display_name = "ci-*"`},
+		{`labels.region = "us-east-1"`, `labels.region = "us-east-1"`},
+		{`a = 1 and b != -2.5 OR c <= true`, `a = 1 AND b != -2.5 OR c <= true`},
+		{`a = "1" AND (b = "2" OR c = "3")`, `a = "1" AND (b = "2" OR c = "3")`},
+		{`not a = "1"`, `NOT a = "1"`},
+		{`NOT (a = "1" OR b = "2")`, `NOT (a = "1" OR b = "2")`},
+		{`  x >= 10  `, `x >= 10`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := expr.String(); got != tt.want {
+				t.Errorf("String() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Glob(t *testing.T) {
+	expr, err := Parse(`display_name = "ci-*"`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	c, ok := expr.(*Comparison)
+	if !ok || c.Op != OpGlob {
+		t.Errorf("Parse() = %#v, want glob comparison", expr)
+	}
+}
+
+func TestParse_Empty(t *testing.T) {
+	expr, err := Parse("   ")
+	if err != nil || expr != nil {
+		t.Errorf("Parse() = %v, %v; want nil, nil", expr, err)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		input  string
+		offset int
+	}{
+		{`status`, 6},
+		{`status =`, 8},
+		{`status = "active`, 9},
+		{`status == "x"`, 8},
+		{`= "x"`, 0},
+		{`(a = 1`, 0},
+		{`a = 1 b = 2`, 6},
+		{`a ! 1`, 2},
+		{`a = 1 AND`, 9},
+		{`a = @`, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			if !errors.Is(err, ErrSyntax) {
+				t.Fatalf("Parse() error = %v, want ErrSyntax", err)
+			}
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("Parse() error %T is not a *SyntaxError", err)
+			}
+			if syntaxErr.Offset != tt.offset {
+				t.Errorf("Offset = %d, want %d (%v)", syntaxErr.Offset, tt.offset, err)
+			}
+		})
+	}
+}
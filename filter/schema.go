@@ -0,0 +1,230 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var (
+	// ErrUnknownField is returned when a filter references a field that the
+	// resource does not have.
+	ErrUnknownField = errors.New("filter: unknown field")
+	// ErrInvalidValue is returned when a literal does not match the type of
+	// the field it is compared with.
+	ErrInvalidValue = errors.New("filter: invalid value")
+	// ErrInvalidOperator is returned when an operator cannot be applied to
+	// the field's type, such as a glob on a number.
+	ErrInvalidOperator = errors.New("filter: invalid operator")
+)
+
+// Schema is the set of fields a filter may reference for one resource type.
+// Fields are taken from the resource's proto message: scalars and enums by
+// name, map entries as "map.key", and nested messages as dotted paths.
+type Schema struct {
+	message protoreflect.MessageDescriptor
+}
+
+// NewSchema returns a schema for filters over the given resource message,
+// such as admiral.api.cluster.v1.Cluster.
+func NewSchema(md protoreflect.MessageDescriptor) *Schema {
+	return &Schema{message: md}
+}
+
+// SchemaFor returns the schema for the Filter field of a List request. The
+// resource is the element type of the repeated message field in the
+// matching List response, e.g. Cluster for ListClustersRequest.
+func SchemaFor(req proto.Message) (*Schema, error) {
+	md := req.ProtoReflect().Descriptor()
+	services := md.ParentFile().Services()
+	for i := 0; i < services.Len(); i++ {
+		methods := services.Get(i).Methods()
+		for j := 0; j < methods.Len(); j++ {
+			m := methods.Get(j)
+			if m.Input().FullName() != md.FullName() {
+				continue
+			}
+			fields := m.Output().Fields()
+			for k := 0; k < fields.Len(); k++ {
+				fd := fields.Get(k)
+				if fd.IsList() && fd.Message() != nil {
+					return NewSchema(fd.Message()), nil
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("filter: %s is not a List request", md.FullName())
+}
+
+// Message returns the resource message the schema describes.
+func (s *Schema) Message() protoreflect.MessageDescriptor {
+	return s.message
+}
+
+// Validate parses filter and checks it against the schema. An empty filter
+// is valid.
+func (s *Schema) Validate(filter string) error {
+	expr, err := Parse(filter)
+	if err != nil {
+		return err
+	}
+	return s.Check(expr)
+}
+
+// Check verifies that every comparison in expr names a field of the
+// resource and uses a value and operator compatible with its type.
+func (s *Schema) Check(expr Expr) error {
+	switch e := expr.(type) {
+	case nil:
+		return nil
+	case *Comparison:
+		return s.checkComparison(e)
+	case *AndExpr:
+		return s.checkAll(e.Exprs)
+	case *OrExpr:
+		return s.checkAll(e.Exprs)
+	case *NotExpr:
+		return s.Check(e.Expr)
+	default:
+		return fmt.Errorf("filter: unsupported expression %T", expr)
+	}
+}
+
+func (s *Schema) checkAll(exprs []Expr) error {
+	for _, e := range exprs {
+		if err := s.Check(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) checkComparison(c *Comparison) error {
+	fd, err := s.resolve(c.Field)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case fd.Message() != nil && fd.Message().FullName() == "google.protobuf.Timestamp":
+		str, ok := c.Value.(string)
+		if !ok {
+			return fmt.Errorf("%w: %s expects an RFC 3339 timestamp, got %s", ErrInvalidValue, c.Field, formatValue(c.Value))
+		}
+		if _, err := time.Parse(time.RFC3339Nano, str); err != nil {
+			return fmt.Errorf("%w: %s expects an RFC 3339 timestamp, got %q", ErrInvalidValue, c.Field, str)
+		}
+		return checkOperator(c, true, false)
+	case fd.Message() != nil:
+		return fmt.Errorf("%w: %s is a message; compare one of its fields instead", ErrUnknownField, c.Field)
+	}
+
+	switch fd.Kind() {
+	case protoreflect.StringKind, protoreflect.BytesKind:
+		if _, ok := c.Value.(string); !ok {
+			return fmt.Errorf("%w: %s expects a string, got %s", ErrInvalidValue, c.Field, formatValue(c.Value))
+		}
+		return checkOperator(c, true, true)
+	case protoreflect.BoolKind:
+		if _, ok := c.Value.(bool); !ok {
+			return fmt.Errorf("%w: %s expects true or false, got %s", ErrInvalidValue, c.Field, formatValue(c.Value))
+		}
+		return checkOperator(c, false, false)
+	case protoreflect.EnumKind:
+		str, ok := c.Value.(string)
+		if !ok || !enumHasValue(fd.Enum(), str) {
+			return fmt.Errorf("%w: %s expects one of %s, got %s", ErrInvalidValue, c.Field, enumValueNames(fd.Enum()), formatValue(c.Value))
+		}
+		return checkOperator(c, false, false)
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		switch c.Value.(type) {
+		case int64, float64:
+		default:
+			return fmt.Errorf("%w: %s expects a number, got %s", ErrInvalidValue, c.Field, formatValue(c.Value))
+		}
+		return checkOperator(c, true, false)
+	default: // integer kinds
+		if _, ok := c.Value.(int64); !ok {
+			return fmt.Errorf("%w: %s expects an integer, got %s", ErrInvalidValue, c.Field, formatValue(c.Value))
+		}
+		return checkOperator(c, true, false)
+	}
+}
+
+// checkOperator rejects ordering comparisons and globs on types that do
+// not support them.
+func checkOperator(c *Comparison, ordered, glob bool) error {
+	switch c.Op {
+	case OpLt, OpLe, OpGt, OpGe:
+		if !ordered {
+			return fmt.Errorf("%w: %s does not support %q", ErrInvalidOperator, c.Field, c.Op)
+		}
+	case OpGlob:
+		if !glob {
+			return fmt.Errorf("%w: %s does not support glob patterns", ErrInvalidOperator, c.Field)
+		}
+	}
+	return nil
+}
+
+// resolve walks a dotted field path and returns the leaf field. For map
+// fields, the remainder of the path is the key and the map value field is
+// returned.
+func (s *Schema) resolve(path string) (protoreflect.FieldDescriptor, error) {
+	md := s.message
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		fd := md.Fields().ByName(protoreflect.Name(part))
+		if fd == nil {
+			fd = md.Fields().ByJSONName(part)
+		}
+		if fd == nil {
+			return nil, fmt.Errorf("%w %q for %s", ErrUnknownField, path, s.message.FullName())
+		}
+		last := i == len(parts)-1
+		switch {
+		case fd.IsMap():
+			if last {
+				return nil, fmt.Errorf("%w: %s is a map; use %s.<key>", ErrUnknownField, path, path)
+			}
+			return fd.MapValue(), nil
+		case last:
+			return fd, nil
+		case fd.Message() == nil || fd.Message().FullName() == "google.protobuf.Timestamp":
+			return nil, fmt.Errorf("%w %q for %s", ErrUnknownField, path, s.message.FullName())
+		}
+		md = fd.Message()
+	}
+	return nil, fmt.Errorf("%w %q for %s", ErrUnknownField, path, s.message.FullName())
+}
+
+// enumHasValue reports whether s names a value of ed, matching either the
+// full name (CLUSTER_HEALTH_STATUS_HEALTHY) or the short name (HEALTHY),
+// case-insensitively.
+func enumHasValue(ed protoreflect.EnumDescriptor, s string) bool {
+	values := ed.Values()
+	for i := 0; i < values.Len(); i++ {
+		v := values.Get(i)
+		if strings.EqualFold(s, string(v.Name())) || strings.EqualFold(s, EnumValueName(ed, v.Number())) {
+			return true
+		}
+	}
+	return false
+}
+
+func enumValueNames(ed protoreflect.EnumDescriptor) string {
+	values := ed.Values()
+	names := make([]string, 0, values.Len())
+	for i := 0; i < values.Len(); i++ {
+		v := values.Get(i)
+		if v.Number() == 0 {
+			continue // UNSPECIFIED
+		}
+		names = append(names, EnumValueName(ed, v.Number()))
+	}
+	return "[" + strings.Join(names, ", ") + "]"
+}
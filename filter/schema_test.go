@@ -0,0 +1,137 @@
+package filter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	agentv1 "go.admiral.io/sdk/proto/agent/v1"
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+	serviceaccountv1 "go.admiral.io/sdk/proto/serviceaccount/v1"
+	userv1 "go.admiral.io/sdk/proto/user/v1"
+)
+
+func TestSchemaFor(t *testing.T) {
+	tests := []struct {
+		req  proto.Message
+		want string
+	}{
+		{&clusterv1.ListClustersRequest{}, "admiral.api.cluster.v1.Cluster"},
+		{&clusterv1.ListWorkloadsRequest{}, "admiral.api.cluster.v1.Workload"},
+		{&clusterv1.ListClusterTokensRequest{}, "admiral.api.accesstoken.v1.AccessToken"},
+		{&agentv1.ListAgentsRequest{}, "admiral.api.agent.v1.Agent"},
+		{&userv1.ListPersonalAccessTokensRequest{}, "admiral.api.accesstoken.v1.AccessToken"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			schema, err := SchemaFor(tt.req)
+			if err != nil {
+				t.Fatalf("SchemaFor() error = %v", err)
+			}
+			if got := string(schema.Message().FullName()); got != tt.want {
+				t.Errorf("Message() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := SchemaFor(&clusterv1.GetClusterRequest{}); err == nil {
+		t.Error("SchemaFor(GetClusterRequest) expected error")
+	}
+}
+
+func TestSchema_Validate(t *testing.T) {
+	schema, err := SchemaFor(&clusterv1.ListClustersRequest{})
+	if err != nil {
+		t.Fatalf("SchemaFor() error = %v", err)
+	}
+
+	tests := []struct {
+		filter  string
+		wantErr error
+	}{
+		{``, nil},
+		{`health_status = "healthy"`, nil},
+		{`health_status = "CLUSTER_HEALTH_STATUS_DEGRADED"`, nil},
+		{`labels.region = "us-east-1"`, nil},
+		{`labels.app.kubernetes.io/name = "api"`, nil},
+		{`display_name = "prod-*" AND NOT cluster_uid = ""`, nil},
+		{`created_at >= "2026-01-01T00:00:00Z"`, nil},
+		{`helth_status = "healthy"`, ErrUnknownField},
+		{`labels = "x"`, ErrUnknownField},
+		{`display_name.first = "x"`, ErrUnknownField},
+		{`health_status = "sparkly"`, ErrInvalidValue},
+		{`display_name = 3`, ErrInvalidValue},
+		{`created_at > "yesterday"`, ErrInvalidValue},
+		{`health_status = "HEALTH*"`, ErrInvalidValue},
+		{`health_status > "healthy"`, ErrInvalidOperator},
+		{`display_name = "x" OR`, ErrSyntax},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			err := schema.Validate(tt.filter)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSchema_CheckBuiltExpr(t *testing.T) {
+	schema, _ := SchemaFor(&agentv1.ListAgentsRequest{})
+	expr := And(
+		Field("status").Eq(agentv1.AgentStatus_AGENT_STATUS_ONLINE),
+		Field("cluster_id").Eq("c1"),
+	)
+	if err := schema.Check(expr); err != nil {
+		t.Errorf("Check() error = %v", err)
+	}
+}
+
+func TestValidateRequest(t *testing.T) {
+	if err := ValidateRequest(&serviceaccountv1.ListServiceAccountsRequest{Filter: `status = "active"`}); err != nil {
+		t.Errorf("ValidateRequest() error = %v", err)
+	}
+	if err := ValidateRequest(&serviceaccountv1.ListServiceAccountsRequest{Filter: `colour = "red"`}); !errors.Is(err, ErrUnknownField) {
+		t.Errorf("ValidateRequest() error = %v, want ErrUnknownField", err)
+	}
+	if err := ValidateRequest(&clusterv1.GetClusterRequest{ClusterId: "c1"}); err != nil {
+		t.Errorf("ValidateRequest() on request without filter error = %v", err)
+	}
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	interceptor := UnaryClientInterceptor()
+	called := false
+	invoker := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+		called = true
+		return nil
+	}
+
+	err := interceptor(context.Background(), "/admiral.api.cluster.v1.ClusterAPI/ListClusters",
+		&clusterv1.ListClustersRequest{Filter: `nope = "x"`}, &clusterv1.ListClustersResponse{}, nil, invoker)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("interceptor error = %v, want InvalidArgument", err)
+	}
+	if called {
+		t.Error("invoker was called for an invalid filter")
+	}
+
+	err = interceptor(context.Background(), "/admiral.api.cluster.v1.ClusterAPI/ListClusters",
+		&clusterv1.ListClustersRequest{Filter: `display_name = "x"`}, &clusterv1.ListClustersResponse{}, nil, invoker)
+	if err != nil || !called {
+		t.Errorf("interceptor error = %v, called = %v; want nil, true", err, called)
+	}
+}
@@ -0,0 +1,299 @@
+package printer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	accesstokenv1 "go.admiral.io/sdk/proto/accesstoken/v1"
+	agentv1 "go.admiral.io/sdk/proto/agent/v1"
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+	runnerv1 "go.admiral.io/sdk/proto/runner/v1"
+	serviceaccountv1 "go.admiral.io/sdk/proto/serviceaccount/v1"
+)
+
+// Column is a table or CSV column.
+type Column struct {
+	// Header is the column title, such as "NAME".
+	Header string
+
+	// Field is the proto field path of the value, such as "display_name"
+	// or "status.node_count". It is ignored when Value is set.
+	Field string
+
+	// Value computes the cell from the message, for values that are not a
+	// single field.
+	Value func(m protoreflect.Message) string
+}
+
+func (c Column) value(m protoreflect.Message) string {
+	if c.Value != nil {
+		return c.Value(m)
+	}
+	return fieldValue(m, c.Field)
+}
+
+var (
+	columnsMu sync.RWMutex
+	columns   = map[protoreflect.FullName][]Column{}
+)
+
+// RegisterColumns sets the table and CSV columns for messages of type name,
+// replacing any set before.
+func RegisterColumns(name protoreflect.FullName, cols ...Column) {
+	columnsMu.Lock()
+	defer columnsMu.Unlock()
+	columns[name] = cols
+}
+
+func registeredColumns(name protoreflect.FullName) ([]Column, bool) {
+	columnsMu.RLock()
+	defer columnsMu.RUnlock()
+	cols, ok := columns[name]
+	return cols, ok
+}
+
+func init() {
+	RegisterColumns((*clusterv1.Cluster)(nil).ProtoReflect().Descriptor().FullName(),
+		Column{Header: "ID", Field: "id"},
+		Column{Header: "NAME", Field: "display_name"},
+		Column{Header: "HEALTH", Field: "health_status"},
+		Column{Header: "LABELS", Field: "labels"},
+		Column{Header: "CREATED", Field: "created_at"},
+	)
+	RegisterColumns((*clusterv1.ClusterStatus)(nil).ProtoReflect().Descriptor().FullName(),
+		Column{Header: "VERSION", Field: "k8s_version"},
+		Column{Header: "NODES", Value: ratio("nodes_ready", "node_count")},
+		Column{Header: "PODS", Value: ratio("pods_running", "pod_count")},
+		Column{Header: "WORKLOADS", Value: ratio("workloads_healthy", "workloads_total")},
+		Column{Header: "DEGRADED", Field: "workloads_degraded"},
+		Column{Header: "ERROR", Field: "workloads_error"},
+	)
+	RegisterColumns((*clusterv1.Workload)(nil).ProtoReflect().Descriptor().FullName(),
+		Column{Header: "NAMESPACE", Field: "namespace"},
+		Column{Header: "NAME", Field: "name"},
+		Column{Header: "KIND", Field: "kind"},
+		Column{Header: "HEALTH", Field: "health_status"},
+		Column{Header: "READY", Value: ratio("replicas_ready", "replicas_desired")},
+		Column{Header: "REASON", Field: "status_reason"},
+		Column{Header: "UPDATED", Field: "last_updated_at"},
+	)
+	RegisterColumns((*accesstokenv1.AccessToken)(nil).ProtoReflect().Descriptor().FullName(),
+		Column{Header: "ID", Field: "id"},
+		Column{Header: "NAME", Field: "display_name"},
+		Column{Header: "PREFIX", Field: "token_prefix"},
+		Column{Header: "STATUS", Field: "status"},
+		Column{Header: "SCOPES", Field: "scopes"},
+		Column{Header: "EXPIRES", Field: "expires_at"},
+		Column{Header: "LAST USED", Field: "last_used_at"},
+	)
+	RegisterColumns((*agentv1.Agent)(nil).ProtoReflect().Descriptor().FullName(),
+		Column{Header: "ID", Field: "id"},
+		Column{Header: "NAME", Field: "display_name"},
+		Column{Header: "STATUS", Field: "status"},
+		Column{Header: "VERSION", Field: "version"},
+		Column{Header: "CLUSTER", Field: "cluster_id"},
+		Column{Header: "RUNNER", Field: "runner_id"},
+		Column{Header: "LAST HEARTBEAT", Field: "last_heartbeat_at"},
+	)
+	RegisterColumns((*runnerv1.Runner)(nil).ProtoReflect().Descriptor().FullName(),
+		Column{Header: "ID", Field: "id"},
+		Column{Header: "NAME", Field: "display_name"},
+		Column{Header: "KIND", Field: "kind"},
+		Column{Header: "LABELS", Field: "labels"},
+		Column{Header: "CREATED", Field: "created_at"},
+	)
+	RegisterColumns((*serviceaccountv1.ServiceAccount)(nil).ProtoReflect().Descriptor().FullName(),
+		Column{Header: "ID", Field: "id"},
+		Column{Header: "NAME", Field: "display_name"},
+		Column{Header: "STATUS", Field: "status"},
+		Column{Header: "SCOPES", Field: "scopes"},
+		Column{Header: "DESCRIPTION", Field: "description"},
+	)
+}
+
+// ratio returns a Value func formatting two fields as "a/b".
+func ratio(a, b string) func(protoreflect.Message) string {
+	return func(m protoreflect.Message) string {
+		return fieldValue(m, a) + "/" + fieldValue(m, b)
+	}
+}
+
+// listColumns returns the columns for a list of messages of type desc.
+func listColumns(desc protoreflect.MessageDescriptor) []Column {
+	if cols, ok := registeredColumns(desc.FullName()); ok {
+		return cols
+	}
+	return defaultColumns(desc)
+}
+
+// columnsFor returns the columns for a single message. A message without
+// registered columns that wraps exactly one resource, such as
+// GetClusterResponse, gets the resource's columns followed by its other
+// populated fields.
+func columnsFor(m protoreflect.Message) []Column {
+	desc := m.Descriptor()
+	if cols, ok := registeredColumns(desc.FullName()); ok {
+		return cols
+	}
+
+	var wrapped, rest []Column
+	resources := 0
+	fields := desc.Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		if !m.Has(fd) {
+			continue
+		}
+		if fd.Message() != nil && fd.Cardinality() != protoreflect.Repeated {
+			if cols, ok := registeredColumns(fd.Message().FullName()); ok {
+				resources++
+				wrapped = prefixed(string(fd.Name()), cols)
+				continue
+			}
+		}
+		rest = append(rest, Column{Header: header(fd), Field: string(fd.Name())})
+	}
+	if resources == 1 {
+		return append(wrapped, rest...)
+	}
+	return defaultColumns(desc)
+}
+
+// defaultColumns returns a column for every field of desc.
+func defaultColumns(desc protoreflect.MessageDescriptor) []Column {
+	fields := desc.Fields()
+	cols := make([]Column, 0, fields.Len())
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		cols = append(cols, Column{Header: header(fd), Field: string(fd.Name())})
+	}
+	return cols
+}
+
+// prefixed returns cols with their fields nested under field.
+func prefixed(field string, cols []Column) []Column {
+	out := make([]Column, len(cols))
+	for i, c := range cols {
+		out[i] = Column{Header: c.Header}
+		if c.Value != nil {
+			value := c.Value
+			out[i].Value = func(m protoreflect.Message) string {
+				fd := m.Descriptor().Fields().ByName(protoreflect.Name(field))
+				return value(m.Get(fd).Message())
+			}
+			continue
+		}
+		out[i].Field = field + "." + c.Field
+	}
+	return out
+}
+
+// header turns a field name such as "health_status" into "HEALTH STATUS".
+func header(fd protoreflect.FieldDescriptor) string {
+	return strings.ToUpper(strings.ReplaceAll(string(fd.Name()), "_", " "))
+}
+
+// fieldValue formats the field at a dot-separated path of m.
+func fieldValue(m protoreflect.Message, path string) string {
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		fd := m.Descriptor().Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			return ""
+		}
+		if i == len(names)-1 {
+			return formatField(m, fd)
+		}
+		if fd.Message() == nil || fd.Cardinality() == protoreflect.Repeated || !m.Has(fd) {
+			return ""
+		}
+		m = m.Get(fd).Message()
+	}
+	return ""
+}
+
+func formatField(m protoreflect.Message, fd protoreflect.FieldDescriptor) string {
+	v := m.Get(fd)
+	switch {
+	case fd.IsMap():
+		var pairs []string
+		v.Map().Range(func(k protoreflect.MapKey, val protoreflect.Value) bool {
+			pairs = append(pairs, k.String()+"="+formatValue(fd.MapValue(), val))
+			return true
+		})
+		slices.Sort(pairs)
+		return strings.Join(pairs, ",")
+	case fd.IsList():
+		list := v.List()
+		items := make([]string, list.Len())
+		for i := range list.Len() {
+			items[i] = formatValue(fd, list.Get(i))
+		}
+		return strings.Join(items, ",")
+	case fd.Message() != nil && !m.Has(fd):
+		return ""
+	}
+	return formatValue(fd, v)
+}
+
+// formatValue formats a single value: enums without their type prefix,
+// timestamps in RFC 3339 and other messages as compact JSON.
+func formatValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		return enumName(fd.Enum(), v.Enum())
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		switch msg := v.Message().Interface().(type) {
+		case *timestamppb.Timestamp:
+			return msg.AsTime().UTC().Format(time.RFC3339)
+		case *durationpb.Duration:
+			return msg.AsDuration().String()
+		}
+		b, err := protojson.Marshal(v.Message().Interface())
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	case protoreflect.StringKind:
+		return strings.NewReplacer("\t", " ", "\n", " ").Replace(v.String())
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// enumName returns the name of n without the prefix shared by all values of
+// the enum, e.g. HEALTHY for CLUSTER_HEALTH_STATUS_HEALTHY.
+func enumName(ed protoreflect.EnumDescriptor, n protoreflect.EnumNumber) string {
+	value := ed.Values().ByNumber(n)
+	if value == nil {
+		return fmt.Sprint(int32(n))
+	}
+	return strings.TrimPrefix(string(value.Name()), enumPrefix(ed))
+}
+
+// enumPrefix returns the longest prefix ending in "_" that all values of ed
+// share.
+func enumPrefix(ed protoreflect.EnumDescriptor) string {
+	values := ed.Values()
+	if values.Len() < 2 {
+		return ""
+	}
+	prefix := string(values.Get(0).Name())
+	for i := 1; i < values.Len(); i++ {
+		name := string(values.Get(i).Name())
+		for !strings.HasPrefix(name, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	if i := strings.LastIndexByte(prefix, '_'); i >= 0 {
+		return prefix[:i+1]
+	}
+	return ""
+}
@@ -0,0 +1,212 @@
+// Package printer renders Admiral API messages for command-line tools.
+//
+// A Printer is created from an output flag value and prints single messages
+// or lists in one of these formats:
+//
+//	table               aligned columns chosen per resource (the default)
+//	json                protojson, as the REST API returns it
+//	yaml                the JSON form as YAML
+//	csv                 the table columns as CSV
+//	template=<tmpl>     a text/template executed on the JSON form
+//	jsonpath=<expr>     a kubectl-style JSONPath expression on the JSON form
+//
+// For example:
+//
+//	p, err := printer.New("table")
+//	if err != nil {
+//	    return err
+//	}
+//	clusters, err := client.CollectAll(client.ListClusters(ctx, c.Cluster()))
+//	if err != nil {
+//	    return err
+//	}
+//	return p.PrintList(os.Stdout, printer.Messages(clusters))
+//
+// Tables and CSV show enums without their type prefix, e.g. HEALTHY rather
+// than CLUSTER_HEALTH_STATUS_HEALTHY, and timestamps in RFC 3339. Clusters,
+// workloads, agents, runners, service accounts and access tokens have
+// predefined columns; RegisterColumns adds or replaces columns for any
+// message type. A response that wraps one such resource, like
+// GetClusterResponse, is shown with the resource's columns.
+//
+// Templates and JSONPath expressions use the JSON field names, e.g.
+// {{.displayName}} or {.cluster.id}. For lists they see a JSON array.
+package printer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/yaml"
+)
+
+// Output formats accepted by New. Templates and JSONPath expressions are
+// given as "template=<tmpl>" and "jsonpath=<expr>".
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+	FormatCSV   = "csv"
+)
+
+// ErrUnknownFormat is returned by New for an unsupported output format.
+var ErrUnknownFormat = errors.New("unknown output format")
+
+// Printer renders messages in one output format. It is safe for concurrent
+// use.
+type Printer struct {
+	format   string
+	template *template.Template
+	jsonPath *jsonpath.JSONPath
+}
+
+// New returns a Printer for an output flag value. An empty value selects
+// FormatTable.
+func New(output string) (*Printer, error) {
+	format, arg, _ := strings.Cut(output, "=")
+	switch format {
+	case "":
+		return &Printer{format: FormatTable}, nil
+	case FormatTable, FormatJSON, FormatYAML, FormatCSV:
+		return &Printer{format: format}, nil
+	case "template", "go-template":
+		tmpl, err := template.New("output").Parse(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template: %w", err)
+		}
+		return &Printer{format: "template", template: tmpl}, nil
+	case "jsonpath":
+		if !strings.Contains(arg, "{") {
+			arg = "{" + arg + "}"
+		}
+		jp := jsonpath.New("output").AllowMissingKeys(true)
+		if err := jp.Parse(arg); err != nil {
+			return nil, fmt.Errorf("invalid jsonpath: %w", err)
+		}
+		return &Printer{format: "jsonpath", jsonPath: jp}, nil
+	}
+	return nil, fmt.Errorf("%w %q (want table, json, yaml, csv, template=... or jsonpath=...)", ErrUnknownFormat, output)
+}
+
+// Messages converts a slice of generated messages for PrintList.
+func Messages[T proto.Message](items []T) []proto.Message {
+	out := make([]proto.Message, len(items))
+	for i, item := range items {
+		out[i] = item
+	}
+	return out
+}
+
+// Print writes one message.
+func (p *Printer) Print(w io.Writer, m proto.Message) error {
+	switch p.format {
+	case FormatTable, FormatCSV:
+		return p.writeRows(w, columnsFor(m.ProtoReflect()), []proto.Message{m})
+	}
+	b, err := protojson.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return p.writeJSON(w, b)
+}
+
+// PrintList writes a list of messages of one type. An empty list prints no
+// table or CSV rows.
+func (p *Printer) PrintList(w io.Writer, items []proto.Message) error {
+	switch p.format {
+	case FormatTable, FormatCSV:
+		if len(items) == 0 {
+			return nil
+		}
+		return p.writeRows(w, listColumns(items[0].ProtoReflect().Descriptor()), items)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("[")
+	for i, item := range items {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		out, err := protojson.Marshal(item)
+		if err != nil {
+			return err
+		}
+		b.Write(out)
+	}
+	b.WriteString("]")
+	return p.writeJSON(w, b.Bytes())
+}
+
+// writeJSON writes the JSON form of a message or list in the JSON-based
+// formats. protojson varies its whitespace between builds on purpose, so
+// the json format is re-indented here to keep the output stable.
+func (p *Printer) writeJSON(w io.Writer, b []byte) error {
+	switch p.format {
+	case FormatJSON:
+		var out bytes.Buffer
+		if err := json.Indent(&out, b, "", "  "); err != nil {
+			return err
+		}
+		out.WriteString("\n")
+		_, err := out.WriteTo(w)
+		return err
+	case FormatYAML:
+		y, err := yaml.JSONToYAML(b)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(y)
+		return err
+	}
+
+	var data any
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	if p.template != nil {
+		return p.template.Execute(w, data)
+	}
+	return p.jsonPath.Execute(w, data)
+}
+
+// writeRows writes messages as a table or CSV.
+func (p *Printer) writeRows(w io.Writer, columns []Column, items []proto.Message) error {
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.Header
+	}
+	rows := [][]string{header}
+	for _, item := range items {
+		m := item.ProtoReflect()
+		row := make([]string, len(columns))
+		for i, c := range columns {
+			row[i] = c.value(m)
+		}
+		rows = append(rows, row)
+	}
+
+	if p.format == FormatCSV {
+		cw := csv.NewWriter(w)
+		if err := cw.WriteAll(rows); err != nil {
+			return err
+		}
+		return cw.Error()
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	for _, row := range rows {
+		if _, err := fmt.Fprintln(tw, strings.Join(row, "\t")); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,120 @@
+package printer
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	accesstokenv1 "go.admiral.io/sdk/proto/accesstoken/v1"
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+)
+
+var testCreated = timestamppb.New(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+
+func testClusters() []*clusterv1.Cluster {
+	return []*clusterv1.Cluster{
+		{Id: "c1", DisplayName: "prod", HealthStatus: clusterv1.ClusterHealthStatus_CLUSTER_HEALTH_STATUS_HEALTHY, Labels: map[string]string{"env": "prod", "region": "us"}, CreatedAt: testCreated},
+		{Id: "c2", DisplayName: "staging-eu", HealthStatus: clusterv1.ClusterHealthStatus_CLUSTER_HEALTH_STATUS_DEGRADED, CreatedAt: testCreated},
+	}
+}
+
+func render(t *testing.T, output string, list bool) string {
+	t.Helper()
+	p, err := New(output)
+	if err != nil {
+		t.Fatalf("New(%q) error = %v", output, err)
+	}
+	var buf bytes.Buffer
+	if list {
+		err = p.PrintList(&buf, Messages(testClusters()))
+	} else {
+		err = p.Print(&buf, &clusterv1.GetClusterResponse{Cluster: testClusters()[0]})
+	}
+	if err != nil {
+		t.Fatalf("print %q error = %v", output, err)
+	}
+	return buf.String()
+}
+
+func TestPrinter_Table(t *testing.T) {
+	want := "" +
+		"ID   NAME         HEALTH     LABELS               CREATED\n" +
+		"c1   prod         HEALTHY    env=prod,region=us   2026-03-01T12:00:00Z\n" +
+		"c2   staging-eu   DEGRADED                        2026-03-01T12:00:00Z\n"
+	if got := render(t, "table", true); got != want {
+		t.Errorf("table =\n%s\nwant\n%s", got, want)
+	}
+
+	// A response wrapping a cluster is shown with the cluster's columns.
+	if got := render(t, "", false); !strings.HasPrefix(got, "ID   NAME   HEALTH") || !strings.Contains(got, "c1   prod   HEALTHY") {
+		t.Errorf("table for GetClusterResponse =\n%s", got)
+	}
+}
+
+func TestPrinter_WrappedResourceWithExtraFields(t *testing.T) {
+	p, _ := New("csv")
+	var buf bytes.Buffer
+	err := p.Print(&buf, &clusterv1.CreateClusterTokenResponse{
+		AccessToken:    &accesstokenv1.AccessToken{Id: "t1", DisplayName: "ci", Status: accesstokenv1.AccessTokenStatus_ACCESS_TOKEN_STATUS_ACTIVE},
+		PlainTextToken: "adm_agt_secret",
+	})
+	if err != nil {
+		t.Fatalf("Print() error = %v", err)
+	}
+	want := "ID,NAME,PREFIX,STATUS,SCOPES,EXPIRES,LAST USED,PLAIN TEXT TOKEN\nt1,ci,,ACTIVE,,,,adm_agt_secret\n"
+	if got := buf.String(); got != want {
+		t.Errorf("csv =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestPrinter_Formats(t *testing.T) {
+	tests := []struct {
+		output string
+		list   bool
+		want   string
+	}{
+		{"json", false, "{\n  \"cluster\": {\n    \"id\": \"c1\","},
+		{"json", true, "[\n  {\n    \"id\": \"c1\","},
+		{"yaml", false, "cluster:\n  createdAt: \"2026-03-01T12:00:00Z\"\n  displayName: prod\n"},
+		{"yaml", true, "- createdAt: \"2026-03-01T12:00:00Z\"\n  displayName: prod\n"},
+		{"csv", true, "ID,NAME,HEALTH,LABELS,CREATED\nc1,prod,HEALTHY,\"env=prod,region=us\",2026-03-01T12:00:00Z\n"},
+		{"template={{.cluster.displayName}}", false, "prod"},
+		{"template={{range .}}{{.id}} {{end}}", true, "c1 c2 "},
+		{"jsonpath={.cluster.healthStatus}", false, "CLUSTER_HEALTH_STATUS_HEALTHY"},
+		{"jsonpath={[*].displayName}", true, "prod staging-eu"},
+		{"jsonpath=.cluster.id", false, "c1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			if got := render(t, tt.output, tt.list); !strings.HasPrefix(got, tt.want) {
+				t.Errorf("output =\n%s\nwant prefix\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	if _, err := New("xml"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("New(xml) error = %v, want ErrUnknownFormat", err)
+	}
+	if _, err := New("template={{.x"); err == nil {
+		t.Error("New(bad template) error = nil")
+	}
+	if _, err := New("jsonpath={.x"); err == nil {
+		t.Error("New(bad jsonpath) error = nil")
+	}
+}
+
+func TestEnumName(t *testing.T) {
+	ed := clusterv1.ClusterHealthStatus(0).Descriptor()
+	if got := enumName(ed, 0); got != "UNSPECIFIED" {
+		t.Errorf("enumName(0) = %q, want UNSPECIFIED", got)
+	}
+	if got := enumName(ed, 99); got != "99" {
+		t.Errorf("enumName(99) = %q, want 99", got)
+	}
+}
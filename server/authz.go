@@ -0,0 +1,220 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"connectrpc.com/connect"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"go.admiral.io/sdk/client"
+)
+
+// ErrInvalidToken is returned by a TokenIntrospector for tokens that are
+// unknown, expired or revoked. The caller gets Unauthenticated.
+var ErrInvalidToken = errors.New("invalid token")
+
+// TokenInfo describes an authenticated token.
+type TokenInfo struct {
+	// Subject identifies the caller, such as a user or agent ID.
+	Subject string
+
+	// Type is one of the client.TokenType constants. Methods restricted to
+	// certain token types reject tokens with an empty Type.
+	Type string
+
+	// Scopes are the scopes granted to the token. "*" and "resource:*"
+	// grant every scope, or every scope on a resource.
+	Scopes []string
+}
+
+// TokenIntrospector resolves a bearer token to the caller it identifies.
+// It returns ErrInvalidToken if the token is not valid. Other errors with a
+// gRPC status are returned to the caller as they are; the rest become
+// Internal.
+type TokenIntrospector interface {
+	Introspect(ctx context.Context, token string) (*TokenInfo, error)
+}
+
+// TokenIntrospectorFunc adapts a function to a TokenIntrospector.
+type TokenIntrospectorFunc func(ctx context.Context, token string) (*TokenInfo, error)
+
+// Introspect calls f(ctx, token).
+func (f TokenIntrospectorFunc) Introspect(ctx context.Context, token string) (*TokenInfo, error) {
+	return f(ctx, token)
+}
+
+type tokenInfoKey struct{}
+
+// TokenInfoFromContext returns the token that authorized the current call.
+// It reports false for methods that need no authentication.
+func TokenInfoFromContext(ctx context.Context) (*TokenInfo, bool) {
+	info, ok := ctx.Value(tokenInfoKey{}).(*TokenInfo)
+	return info, ok
+}
+
+// Authorizer enforces the AuthRule annotated on each Admiral method, as the
+// real API does. Methods without a rule, such as the health check, are let
+// through unauthenticated.
+type Authorizer struct {
+	tokens TokenIntrospector
+}
+
+// NewAuthorizer returns an Authorizer that resolves tokens with tokens.
+func NewAuthorizer(tokens TokenIntrospector) *Authorizer {
+	return &Authorizer{tokens: tokens}
+}
+
+// Authorize checks a call to method made with the given authorization
+// header value. It returns a context carrying the TokenInfo, or an
+// Unauthenticated or PermissionDenied status error.
+func (a *Authorizer) Authorize(ctx context.Context, method, authorization string) (context.Context, error) {
+	rule, ok := client.AuthRuleFor(method)
+	if !ok {
+		return ctx, nil
+	}
+
+	token := bearerToken(authorization)
+	if token == "" {
+		return nil, status.Error(codes.Unauthenticated, "missing authorization")
+	}
+	info, err := a.tokens.Introspect(ctx, token)
+	switch {
+	case errors.Is(err, ErrInvalidToken) || (err == nil && info == nil):
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	case err != nil:
+		if st, ok := status.FromError(err); ok {
+			return nil, st.Err()
+		}
+		return nil, status.Errorf(codes.Internal, "token introspection failed: %v", err)
+	}
+
+	if allowed := rule.GetAllowedTokenTypes(); len(allowed) > 0 && !slices.Contains(allowed, info.Type) {
+		return nil, status.Error(codes.PermissionDenied, "token type not allowed")
+	}
+	if scope := rule.GetScope(); scope != "" && !client.HasScope(info.Scopes, scope) {
+		return nil, insufficientScope(scope)
+	}
+	return context.WithValue(ctx, tokenInfoKey{}, info), nil
+}
+
+// UnaryInterceptor returns a gRPC unary server interceptor.
+func (a *Authorizer) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := a.Authorize(ctx, info.FullMethod, incomingAuthorization(ctx))
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor returns a gRPC stream server interceptor.
+func (a *Authorizer) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.Authorize(ss.Context(), info.FullMethod, incomingAuthorization(ss.Context()))
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authorizedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// ConnectInterceptor returns a Connect handler interceptor.
+func (a *Authorizer) ConnectInterceptor() connect.Interceptor {
+	return &connectAuthorizer{a}
+}
+
+type connectAuthorizer struct {
+	*Authorizer
+}
+
+func (c *connectAuthorizer) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient {
+			return next(ctx, req)
+		}
+		ctx, err := c.Authorize(ctx, req.Spec().Procedure, req.Header().Get("Authorization"))
+		if err != nil {
+			return nil, toConnectError(err)
+		}
+		return next(ctx, req)
+	}
+}
+
+func (c *connectAuthorizer) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (c *connectAuthorizer) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		ctx, err := c.Authorize(ctx, conn.Spec().Procedure, conn.RequestHeader().Get("Authorization"))
+		if err != nil {
+			return toConnectError(err)
+		}
+		return next(ctx, conn)
+	}
+}
+
+// authorizedStream carries the authorized context into a stream handler.
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
+
+// insufficientScope returns the PermissionDenied error for a token that
+// lacks scope, with the ErrorInfo decoded into client.Error.MissingScope.
+func insufficientScope(scope string) error {
+	st := status.New(codes.PermissionDenied, fmt.Sprintf("token is missing scope %q", scope))
+	if withInfo, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   client.ErrorReasonInsufficientScope,
+		Metadata: map[string]string{"scope": scope},
+	}); err == nil {
+		st = withInfo
+	}
+	return st.Err()
+}
+
+// toConnectError converts a status error to a Connect error, keeping its
+// details.
+func toConnectError(err error) *connect.Error {
+	st := status.Convert(err)
+	cerr := connect.NewError(connect.Code(st.Code()), errors.New(st.Message()))
+	for _, d := range st.Proto().GetDetails() {
+		if detail, err := connect.NewErrorDetail(d); err == nil {
+			cerr.AddDetail(detail)
+		}
+	}
+	return cerr
+}
+
+// incomingAuthorization returns the authorization metadata of a gRPC call.
+func incomingAuthorization(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("authorization"); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// bearerToken strips the "Bearer" or "Token" scheme from an authorization
+// header value.
+func bearerToken(authorization string) string {
+	for _, scheme := range []string{"Bearer ", "Token "} {
+		if len(authorization) > len(scheme) && strings.EqualFold(authorization[:len(scheme)], scheme) {
+			return strings.TrimSpace(authorization[len(scheme):])
+		}
+	}
+	return strings.TrimSpace(authorization)
+}
@@ -0,0 +1,159 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"connectrpc.com/connect"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"go.admiral.io/sdk/client"
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+	"go.admiral.io/sdk/proto/cluster/v1/clusterv1connect"
+	healthcheckv1 "go.admiral.io/sdk/proto/healthcheck/v1"
+)
+
+var testTokens = TokenIntrospectorFunc(func(_ context.Context, token string) (*TokenInfo, error) {
+	switch token {
+	case "adm_pat_reader":
+		return &TokenInfo{Subject: "user-1", Type: client.TokenTypePersonalAccess, Scopes: []string{"clusters:read"}}, nil
+	case "adm_pat_admin":
+		return &TokenInfo{Subject: "user-2", Type: client.TokenTypePersonalAccess, Scopes: []string{"*"}}, nil
+	case "adm_agt_agent":
+		return &TokenInfo{Subject: "agent-1", Type: client.TokenTypeAgent, Scopes: []string{"clusters:*"}}, nil
+	case "broken":
+		return nil, errors.New("database unavailable")
+	}
+	return nil, ErrInvalidToken
+})
+
+func TestAuthorize(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		authorization string
+		want          codes.Code
+	}{
+		{"no rule", healthcheckv1.HealthcheckAPI_Healthcheck_FullMethodName, "", codes.OK},
+		{"missing token", clusterv1.ClusterAPI_GetCluster_FullMethodName, "", codes.Unauthenticated},
+		{"invalid token", clusterv1.ClusterAPI_GetCluster_FullMethodName, "Bearer adm_pat_unknown", codes.Unauthenticated},
+		{"introspection error", clusterv1.ClusterAPI_GetCluster_FullMethodName, "Bearer broken", codes.Internal},
+		{"granted scope", clusterv1.ClusterAPI_GetCluster_FullMethodName, "Bearer adm_pat_reader", codes.OK},
+		{"token scheme", clusterv1.ClusterAPI_GetCluster_FullMethodName, "Token adm_pat_reader", codes.OK},
+		{"missing scope", clusterv1.ClusterAPI_CreateCluster_FullMethodName, "Bearer adm_pat_reader", codes.PermissionDenied},
+		{"wildcard scope", clusterv1.ClusterAPI_CreateCluster_FullMethodName, "Bearer adm_pat_admin", codes.OK},
+		{"token type not allowed", clusterv1.ClusterAPI_ReportClusterStatus_FullMethodName, "Bearer adm_pat_admin", codes.PermissionDenied},
+		{"token type allowed", clusterv1.ClusterAPI_ReportClusterStatus_FullMethodName, "Bearer adm_agt_agent", codes.OK},
+	}
+	a := NewAuthorizer(testTokens)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := a.Authorize(context.Background(), tt.method, tt.authorization)
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("Authorize() error = %v, want %v", err, tt.want)
+			}
+			if err != nil || tt.authorization == "" {
+				return
+			}
+			if _, ok := TokenInfoFromContext(ctx); !ok {
+				t.Error("TokenInfoFromContext() = false, want the caller")
+			}
+		})
+	}
+}
+
+type testClusterServer struct {
+	clusterv1.UnimplementedClusterAPIServer
+}
+
+func (testClusterServer) GetCluster(ctx context.Context, req *clusterv1.GetClusterRequest) (*clusterv1.GetClusterResponse, error) {
+	info, _ := TokenInfoFromContext(ctx)
+	return &clusterv1.GetClusterResponse{Cluster: &clusterv1.Cluster{Id: req.GetClusterId(), DisplayName: info.Subject}}, nil
+}
+
+type testClusterHandler struct {
+	clusterv1connect.UnimplementedClusterAPIHandler
+}
+
+func (testClusterHandler) GetCluster(ctx context.Context, req *connect.Request[clusterv1.GetClusterRequest]) (*connect.Response[clusterv1.GetClusterResponse], error) {
+	resp, err := testClusterServer{}.GetCluster(ctx, req.Msg)
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(resp), nil
+}
+
+func TestAuthorizer_Transports(t *testing.T) {
+	a := NewAuthorizer(testTokens)
+
+	lis := bufconn.Listen(1024 * 1024)
+	gs := grpc.NewServer(grpc.ChainUnaryInterceptor(a.UnaryInterceptor()))
+	clusterv1.RegisterClusterAPIServer(gs, testClusterServer{})
+	go func() { _ = gs.Serve(lis) }()
+	t.Cleanup(gs.Stop)
+
+	mux := http.NewServeMux()
+	mux.Handle(clusterv1connect.NewClusterAPIHandler(testClusterHandler{}, connect.WithInterceptors(a.ConnectInterceptor())))
+	httpSrv := httptest.NewServer(mux)
+	t.Cleanup(httpSrv.Close)
+
+	configs := map[string]client.Config{
+		"grpc": {
+			HostPort: "bufconn:0",
+			ConnectionOptions: client.ConnectionOptions{
+				DialOptions: []grpc.DialOption{grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+					return lis.DialContext(ctx)
+				})},
+			},
+		},
+		"connect": {
+			HostPort:  strings.TrimPrefix(httpSrv.URL, "http://"),
+			Transport: client.TransportConnect,
+		},
+	}
+	for name, cfg := range configs {
+		t.Run(name, func(t *testing.T) {
+			newClient := func(token string) *client.Client {
+				cfg := cfg
+				cfg.AuthToken = token
+				cfg.ConnectionOptions.Insecure = true
+				c, err := client.New(context.Background(), cfg)
+				if err != nil {
+					t.Fatalf("New() error = %v", err)
+				}
+				t.Cleanup(func() { _ = c.Close() })
+				return c
+			}
+
+			resp, err := newClient("adm_pat_reader").Cluster().GetCluster(context.Background(), &clusterv1.GetClusterRequest{ClusterId: "c1"})
+			if err != nil {
+				t.Fatalf("GetCluster() error = %v", err)
+			}
+			if got := resp.GetCluster().GetDisplayName(); got != "user-1" {
+				t.Errorf("caller = %q, want user-1", got)
+			}
+
+			_, err = newClient("adm_pat_reader").Cluster().CreateCluster(context.Background(), &clusterv1.CreateClusterRequest{DisplayName: "prod"})
+			var e *client.Error
+			if !errors.Is(err, client.ErrPermissionDenied) || !errors.As(err, &e) {
+				t.Fatalf("CreateCluster() error = %v, want ErrPermissionDenied", err)
+			}
+			if e.MissingScope != "clusters:write" {
+				t.Errorf("MissingScope = %q, want clusters:write", e.MissingScope)
+			}
+
+			_, err = newClient("adm_pat_unknown").Cluster().GetCluster(context.Background(), &clusterv1.GetClusterRequest{ClusterId: "c1"})
+			if !errors.Is(err, client.ErrUnauthenticated) {
+				t.Errorf("GetCluster() error = %v, want ErrUnauthenticated", err)
+			}
+		})
+	}
+}
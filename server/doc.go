@@ -0,0 +1,50 @@
+// Package server provides building blocks for servers that implement the
+// Admiral API, such as mocks, proxies and on-prem stand-ins.
+//
+// # Serving the API
+//
+// NewHandler mounts the *APIServer implementations on one http.Handler that
+// speaks Connect, gRPC and gRPC-Web at the procedure paths and HTTP/JSON
+// under "/api", matching the public API surface:
+//
+//	h, err := server.NewHandler(server.Services{
+//	    Cluster:     clusters,
+//	    Healthcheck: health,
+//	}, server.WithAuthorizer(authz), server.WithInterceptors(logging))
+//	if err != nil {
+//	    return err
+//	}
+//	return server.NewHTTPServer(":8080", h).ListenAndServe()
+//
+// Interceptors run for every call on every protocol. Connect and HTTP/JSON
+// request headers reach them, and the implementations, as incoming gRPC
+// metadata. NewHTTPServer accepts HTTP/2 without TLS, which gRPC needs.
+//
+// # Authorization
+//
+// Every Admiral method is annotated with an AuthRule naming the scope and
+// token types it requires. An Authorizer enforces those rules the way the
+// real API does, given a TokenIntrospector that resolves bearer tokens:
+//
+//	authz := server.NewAuthorizer(server.TokenIntrospectorFunc(
+//	    func(ctx context.Context, token string) (*server.TokenInfo, error) {
+//	        t, ok := tokens[token]
+//	        if !ok {
+//	            return nil, server.ErrInvalidToken
+//	        }
+//	        return &server.TokenInfo{Subject: t.Owner, Type: client.TokenTypePersonalAccess, Scopes: t.Scopes}, nil
+//	    }))
+//
+//	grpcServer := grpc.NewServer(
+//	    grpc.ChainUnaryInterceptor(authz.UnaryInterceptor()),
+//	    grpc.ChainStreamInterceptor(authz.StreamInterceptor()),
+//	)
+//	path, handler := clusterv1connect.NewClusterAPIHandler(impl,
+//	    connect.WithInterceptors(authz.ConnectInterceptor()))
+//
+// Calls without a token, or with one the introspector rejects, fail with
+// Unauthenticated. Calls with a disallowed token type or a missing scope
+// fail with PermissionDenied; the latter carries the ErrorInfo the client
+// decodes into client.Error.MissingScope. Handlers read the caller with
+// TokenInfoFromContext.
+package server
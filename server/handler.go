@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+
+	"go.admiral.io/sdk/client"
+	agentv1 "go.admiral.io/sdk/proto/agent/v1"
+	"go.admiral.io/sdk/proto/agent/v1/agentv1connect"
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+	"go.admiral.io/sdk/proto/cluster/v1/clusterv1connect"
+	healthcheckv1 "go.admiral.io/sdk/proto/healthcheck/v1"
+	"go.admiral.io/sdk/proto/healthcheck/v1/healthcheckv1connect"
+	runnerv1 "go.admiral.io/sdk/proto/runner/v1"
+	"go.admiral.io/sdk/proto/runner/v1/runnerv1connect"
+	serviceaccountv1 "go.admiral.io/sdk/proto/serviceaccount/v1"
+	"go.admiral.io/sdk/proto/serviceaccount/v1/serviceaccountv1connect"
+	userv1 "go.admiral.io/sdk/proto/user/v1"
+	"go.admiral.io/sdk/proto/user/v1/userv1connect"
+)
+
+// DefaultReadHeaderTimeout bounds how long NewHTTPServer waits for request
+// headers.
+const DefaultReadHeaderTimeout = 10 * time.Second
+
+// Services are the API implementations served by NewHandler. Nil services
+// are not mounted.
+type Services struct {
+	Agent          agentv1.AgentAPIServer
+	Cluster        clusterv1.ClusterAPIServer
+	Healthcheck    healthcheckv1.HealthcheckAPIServer
+	Runner         runnerv1.RunnerAPIServer
+	ServiceAccount serviceaccountv1.ServiceAccountAPIServer
+	User           userv1.UserAPIServer
+}
+
+// Option configures NewHandler.
+type Option func(*options)
+
+type options struct {
+	interceptors   []grpc.UnaryServerInterceptor
+	restPathPrefix string
+	connectOptions []connect.HandlerOption
+	gatewayOptions []runtime.ServeMuxOption
+}
+
+// WithInterceptors adds interceptors run for every call on every protocol,
+// the first being outermost. Connect and REST requests carry their headers
+// as incoming gRPC metadata.
+func WithInterceptors(interceptors ...grpc.UnaryServerInterceptor) Option {
+	return func(o *options) { o.interceptors = append(o.interceptors, interceptors...) }
+}
+
+// WithAuthorizer enforces AuthRule annotations with a. It is shorthand for
+// WithInterceptors(a.UnaryInterceptor()).
+func WithAuthorizer(a *Authorizer) Option {
+	return WithInterceptors(a.UnaryInterceptor())
+}
+
+// WithRESTPathPrefix sets the path the HTTP/JSON gateway is mounted under.
+// Defaults to client.DefaultRESTPathPrefix; set "/" for no prefix.
+func WithRESTPathPrefix(prefix string) Option {
+	return func(o *options) { o.restPathPrefix = prefix }
+}
+
+// WithConnectOptions adds options to every Connect handler, such as
+// compression or read limits.
+func WithConnectOptions(opts ...connect.HandlerOption) Option {
+	return func(o *options) { o.connectOptions = append(o.connectOptions, opts...) }
+}
+
+// WithGatewayOptions adds options to the grpc-gateway mux, such as header
+// matchers or marshalers.
+func WithGatewayOptions(opts ...runtime.ServeMuxOption) Option {
+	return func(o *options) { o.gatewayOptions = append(o.gatewayOptions, opts...) }
+}
+
+// NewHandler returns a handler serving svcs over Connect, gRPC and gRPC-Web
+// at their procedure paths, and over HTTP/JSON under the REST path prefix.
+// gRPC clients need HTTP/2; use NewHTTPServer to accept it without TLS.
+func NewHandler(svcs Services, opts ...Option) (http.Handler, error) {
+	o := options{restPathPrefix: client.DefaultRESTPathPrefix}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	chain := chainUnary(o.interceptors)
+
+	mux := http.NewServeMux()
+	gw := runtime.NewServeMux(o.gatewayOptions...)
+	ctx := context.Background()
+	var errs []error
+	register := func(name string, err error) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to register %s gateway: %w", name, err))
+		}
+	}
+
+	if svcs.Agent != nil {
+		s := &agentService{impl: svcs.Agent, chain: chain}
+		mux.Handle(agentv1connect.NewAgentAPIHandler(&connectAgentHandler{s: s}, o.connectOptions...))
+		register("AgentAPI", agentv1.RegisterAgentAPIHandlerServer(ctx, gw, s))
+	}
+	if svcs.Cluster != nil {
+		s := &clusterService{impl: svcs.Cluster, chain: chain}
+		mux.Handle(clusterv1connect.NewClusterAPIHandler(&connectClusterHandler{s: s}, o.connectOptions...))
+		register("ClusterAPI", clusterv1.RegisterClusterAPIHandlerServer(ctx, gw, s))
+	}
+	if svcs.Healthcheck != nil {
+		s := &healthcheckService{impl: svcs.Healthcheck, chain: chain}
+		mux.Handle(healthcheckv1connect.NewHealthcheckAPIHandler(&connectHealthcheckHandler{s: s}, o.connectOptions...))
+		register("HealthcheckAPI", healthcheckv1.RegisterHealthcheckAPIHandlerServer(ctx, gw, s))
+	}
+	if svcs.Runner != nil {
+		s := &runnerService{impl: svcs.Runner, chain: chain}
+		mux.Handle(runnerv1connect.NewRunnerAPIHandler(&connectRunnerHandler{s: s}, o.connectOptions...))
+		register("RunnerAPI", runnerv1.RegisterRunnerAPIHandlerServer(ctx, gw, s))
+	}
+	if svcs.ServiceAccount != nil {
+		s := &serviceAccountService{impl: svcs.ServiceAccount, chain: chain}
+		mux.Handle(serviceaccountv1connect.NewServiceAccountAPIHandler(&connectServiceAccountHandler{s: s}, o.connectOptions...))
+		register("ServiceAccountAPI", serviceaccountv1.RegisterServiceAccountAPIHandlerServer(ctx, gw, s))
+	}
+	if svcs.User != nil {
+		s := &userService{impl: svcs.User, chain: chain}
+		mux.Handle(userv1connect.NewUserAPIHandler(&connectUserHandler{s: s}, o.connectOptions...))
+		register("UserAPI", userv1.RegisterUserAPIHandlerServer(ctx, gw, s))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	if prefix := strings.TrimSuffix(o.restPathPrefix, "/"); prefix == "" {
+		mux.Handle("/", gw)
+	} else {
+		mux.Handle(prefix+"/", http.StripPrefix(prefix, gw))
+	}
+	return mux, nil
+}
+
+// NewHTTPServer returns an http.Server for h that accepts HTTP/1.1, and
+// HTTP/2 with or without TLS, so gRPC clients can connect in plaintext.
+func NewHTTPServer(addr string, h http.Handler) *http.Server {
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(true)
+	return &http.Server{
+		Addr:              addr,
+		Handler:           h,
+		Protocols:         &protocols,
+		ReadHeaderTimeout: DefaultReadHeaderTimeout,
+	}
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+
+	"connectrpc.com/connect"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"go.admiral.io/sdk/client"
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+	healthcheckv1 "go.admiral.io/sdk/proto/healthcheck/v1"
+)
+
+type testHealthcheckServer struct {
+	healthcheckv1.UnimplementedHealthcheckAPIServer
+}
+
+func (testHealthcheckServer) Healthcheck(context.Context, *healthcheckv1.HealthcheckRequest) (*healthcheckv1.HealthcheckResponse, error) {
+	return &healthcheckv1.HealthcheckResponse{}, nil
+}
+
+func startTestHandler(t *testing.T, opts ...Option) string {
+	t.Helper()
+	h, err := NewHandler(Services{Cluster: testClusterServer{}, Healthcheck: testHealthcheckServer{}}, opts...)
+	if err != nil {
+		t.Fatalf("NewHandler() error = %v", err)
+	}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	srv := NewHTTPServer("", h)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(func() { _ = srv.Close() })
+	return lis.Addr().String()
+}
+
+func TestNewHandler_Protocols(t *testing.T) {
+	var methods []string
+	record := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		methods = append(methods, info.FullMethod)
+		return handler(ctx, req)
+	}
+	addr := startTestHandler(t, WithInterceptors(record), WithAuthorizer(NewAuthorizer(testTokens)))
+
+	configs := map[string]client.Config{
+		"grpc":         {},
+		"rest":         {Transport: client.TransportREST},
+		"connect":      {Transport: client.TransportConnect},
+		"connect-json": {Transport: client.TransportConnect, ConnectionOptions: client.ConnectionOptions{Encoding: client.EncodingJSON}},
+		"grpc-web": {Transport: client.TransportConnect, ConnectionOptions: client.ConnectionOptions{
+			ConnectOptions: []connect.ClientOption{connect.WithGRPCWeb()},
+		}},
+	}
+	for name, cfg := range configs {
+		t.Run(name, func(t *testing.T) {
+			methods = nil
+			cfg.HostPort = addr
+			cfg.AuthToken = "adm_pat_reader"
+			cfg.ConnectionOptions.Insecure = true
+			c, err := client.New(context.Background(), cfg)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			t.Cleanup(func() { _ = c.Close() })
+
+			resp, err := c.Cluster().GetCluster(context.Background(), &clusterv1.GetClusterRequest{ClusterId: "c1"})
+			if err != nil {
+				t.Fatalf("GetCluster() error = %v", err)
+			}
+			if resp.GetCluster().GetId() != "c1" || resp.GetCluster().GetDisplayName() != "user-1" {
+				t.Errorf("GetCluster() = %v", resp.GetCluster())
+			}
+			if _, err := c.Healthcheck().Healthcheck(context.Background(), &healthcheckv1.HealthcheckRequest{}); err != nil {
+				t.Errorf("Healthcheck() error = %v", err)
+			}
+
+			var e *client.Error
+			_, err = c.Cluster().CreateCluster(context.Background(), &clusterv1.CreateClusterRequest{DisplayName: "prod"})
+			if !errors.As(err, &e) || !errors.Is(err, client.ErrPermissionDenied) || e.MissingScope != "clusters:write" {
+				t.Errorf("CreateCluster() error = %v, want ErrPermissionDenied missing clusters:write", err)
+			}
+
+			want := []string{
+				clusterv1.ClusterAPI_GetCluster_FullMethodName,
+				healthcheckv1.HealthcheckAPI_Healthcheck_FullMethodName,
+				clusterv1.ClusterAPI_CreateCluster_FullMethodName,
+			}
+			if len(methods) != len(want) {
+				t.Fatalf("intercepted %v, want %v", methods, want)
+			}
+			for i := range want {
+				if methods[i] != want[i] {
+					t.Errorf("intercepted %v, want %v", methods, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewHandler_UnmountedService(t *testing.T) {
+	addr := startTestHandler(t)
+	resp, err := http.Get("http://" + addr + "/admiral.api.runner.v1.RunnerAPI/ListRunners")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestConnectUnary_Metadata(t *testing.T) {
+	req := connect.NewRequest(&clusterv1.GetClusterRequest{ClusterId: "c1"})
+	req.Header().Set("X-Request-Id", "abc")
+	_, err := connectUnary(context.Background(), req, func(ctx context.Context, _ *clusterv1.GetClusterRequest) (*clusterv1.GetClusterResponse, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if got := md.Get("x-request-id"); len(got) != 1 || got[0] != "abc" {
+			t.Errorf("x-request-id = %v, want [abc]", got)
+		}
+		return &clusterv1.GetClusterResponse{}, nil
+	})
+	if err != nil {
+		t.Fatalf("connectUnary() error = %v", err)
+	}
+}
@@ -0,0 +1,504 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"connectrpc.com/connect"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	agentv1 "go.admiral.io/sdk/proto/agent/v1"
+	"go.admiral.io/sdk/proto/agent/v1/agentv1connect"
+	clusterv1 "go.admiral.io/sdk/proto/cluster/v1"
+	"go.admiral.io/sdk/proto/cluster/v1/clusterv1connect"
+	healthcheckv1 "go.admiral.io/sdk/proto/healthcheck/v1"
+	"go.admiral.io/sdk/proto/healthcheck/v1/healthcheckv1connect"
+	runnerv1 "go.admiral.io/sdk/proto/runner/v1"
+	"go.admiral.io/sdk/proto/runner/v1/runnerv1connect"
+	serviceaccountv1 "go.admiral.io/sdk/proto/serviceaccount/v1"
+	"go.admiral.io/sdk/proto/serviceaccount/v1/serviceaccountv1connect"
+	userv1 "go.admiral.io/sdk/proto/user/v1"
+	"go.admiral.io/sdk/proto/user/v1/userv1connect"
+)
+
+// invoke calls fn through chain, as a grpc.Server would call a method
+// handler. A nil chain calls fn directly.
+func invoke[Req, Res any](
+	ctx context.Context,
+	chain grpc.UnaryServerInterceptor,
+	srv any,
+	method string,
+	in *Req,
+	fn func(context.Context, *Req) (*Res, error),
+) (*Res, error) {
+	if chain == nil {
+		return fn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: method}
+	resp, err := chain(ctx, in, info, func(ctx context.Context, req any) (any, error) {
+		return fn(ctx, req.(*Req))
+	})
+	if err != nil {
+		return nil, err
+	}
+	out, _ := resp.(*Res)
+	return out, nil
+}
+
+// chainUnary combines interceptors into one, the first being outermost.
+func chainUnary(interceptors []grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	switch len(interceptors) {
+	case 0:
+		return nil
+	case 1:
+		return interceptors[0]
+	}
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		next := handler
+		for i := len(interceptors) - 1; i > 0; i-- {
+			interceptor, inner := interceptors[i], next
+			next = func(ctx context.Context, req any) (any, error) {
+				return interceptor(ctx, req, info, inner)
+			}
+		}
+		return interceptors[0](ctx, req, info, next)
+	}
+}
+
+// connectUnary calls a gRPC-style method for a Connect request. Request
+// headers become incoming metadata, and status errors become Connect
+// errors, so implementations see the same thing on every protocol.
+func connectUnary[Req, Res any](
+	ctx context.Context,
+	req *connect.Request[Req],
+	fn func(context.Context, *Req) (*Res, error),
+) (*connect.Response[Res], error) {
+	ctx = metadata.NewIncomingContext(ctx, headerToMD(req.Header()))
+	resp, err := fn(ctx, req.Msg)
+	if err != nil {
+		var cerr *connect.Error
+		if errors.As(err, &cerr) {
+			return nil, cerr
+		}
+		return nil, toConnectError(err)
+	}
+	return connect.NewResponse(resp), nil
+}
+
+// headerToMD converts HTTP request headers to gRPC metadata.
+func headerToMD(h http.Header) metadata.MD {
+	md := make(metadata.MD, len(h))
+	for k, v := range h {
+		md[strings.ToLower(k)] = v
+	}
+	return md
+}
+
+// agentService runs the AgentAPI methods through the interceptor chain.
+type agentService struct {
+	agentv1.UnimplementedAgentAPIServer
+	impl  agentv1.AgentAPIServer
+	chain grpc.UnaryServerInterceptor
+}
+
+func (s *agentService) RegisterAgent(ctx context.Context, in *agentv1.RegisterAgentRequest) (*agentv1.RegisterAgentResponse, error) {
+	return invoke(ctx, s.chain, s.impl, agentv1.AgentAPI_RegisterAgent_FullMethodName, in, s.impl.RegisterAgent)
+}
+
+func (s *agentService) GetAgent(ctx context.Context, in *agentv1.GetAgentRequest) (*agentv1.GetAgentResponse, error) {
+	return invoke(ctx, s.chain, s.impl, agentv1.AgentAPI_GetAgent_FullMethodName, in, s.impl.GetAgent)
+}
+
+func (s *agentService) ListAgents(ctx context.Context, in *agentv1.ListAgentsRequest) (*agentv1.ListAgentsResponse, error) {
+	return invoke(ctx, s.chain, s.impl, agentv1.AgentAPI_ListAgents_FullMethodName, in, s.impl.ListAgents)
+}
+
+func (s *agentService) Heartbeat(ctx context.Context, in *agentv1.HeartbeatRequest) (*agentv1.HeartbeatResponse, error) {
+	return invoke(ctx, s.chain, s.impl, agentv1.AgentAPI_Heartbeat_FullMethodName, in, s.impl.Heartbeat)
+}
+
+// connectAgentHandler serves AgentAPI over Connect, gRPC and gRPC-Web.
+type connectAgentHandler struct {
+	agentv1connect.UnimplementedAgentAPIHandler
+	s agentv1.AgentAPIServer
+}
+
+func (h *connectAgentHandler) RegisterAgent(ctx context.Context, req *connect.Request[agentv1.RegisterAgentRequest]) (*connect.Response[agentv1.RegisterAgentResponse], error) {
+	return connectUnary(ctx, req, h.s.RegisterAgent)
+}
+
+func (h *connectAgentHandler) GetAgent(ctx context.Context, req *connect.Request[agentv1.GetAgentRequest]) (*connect.Response[agentv1.GetAgentResponse], error) {
+	return connectUnary(ctx, req, h.s.GetAgent)
+}
+
+func (h *connectAgentHandler) ListAgents(ctx context.Context, req *connect.Request[agentv1.ListAgentsRequest]) (*connect.Response[agentv1.ListAgentsResponse], error) {
+	return connectUnary(ctx, req, h.s.ListAgents)
+}
+
+func (h *connectAgentHandler) Heartbeat(ctx context.Context, req *connect.Request[agentv1.HeartbeatRequest]) (*connect.Response[agentv1.HeartbeatResponse], error) {
+	return connectUnary(ctx, req, h.s.Heartbeat)
+}
+
+// clusterService runs the ClusterAPI methods through the interceptor chain.
+type clusterService struct {
+	clusterv1.UnimplementedClusterAPIServer
+	impl  clusterv1.ClusterAPIServer
+	chain grpc.UnaryServerInterceptor
+}
+
+func (s *clusterService) CreateCluster(ctx context.Context, in *clusterv1.CreateClusterRequest) (*clusterv1.CreateClusterResponse, error) {
+	return invoke(ctx, s.chain, s.impl, clusterv1.ClusterAPI_CreateCluster_FullMethodName, in, s.impl.CreateCluster)
+}
+
+func (s *clusterService) GetCluster(ctx context.Context, in *clusterv1.GetClusterRequest) (*clusterv1.GetClusterResponse, error) {
+	return invoke(ctx, s.chain, s.impl, clusterv1.ClusterAPI_GetCluster_FullMethodName, in, s.impl.GetCluster)
+}
+
+func (s *clusterService) GetClusterStatus(ctx context.Context, in *clusterv1.GetClusterStatusRequest) (*clusterv1.GetClusterStatusResponse, error) {
+	return invoke(ctx, s.chain, s.impl, clusterv1.ClusterAPI_GetClusterStatus_FullMethodName, in, s.impl.GetClusterStatus)
+}
+
+func (s *clusterService) ListClusters(ctx context.Context, in *clusterv1.ListClustersRequest) (*clusterv1.ListClustersResponse, error) {
+	return invoke(ctx, s.chain, s.impl, clusterv1.ClusterAPI_ListClusters_FullMethodName, in, s.impl.ListClusters)
+}
+
+func (s *clusterService) UpdateCluster(ctx context.Context, in *clusterv1.UpdateClusterRequest) (*clusterv1.UpdateClusterResponse, error) {
+	return invoke(ctx, s.chain, s.impl, clusterv1.ClusterAPI_UpdateCluster_FullMethodName, in, s.impl.UpdateCluster)
+}
+
+func (s *clusterService) DeleteCluster(ctx context.Context, in *clusterv1.DeleteClusterRequest) (*clusterv1.DeleteClusterResponse, error) {
+	return invoke(ctx, s.chain, s.impl, clusterv1.ClusterAPI_DeleteCluster_FullMethodName, in, s.impl.DeleteCluster)
+}
+
+func (s *clusterService) CreateClusterToken(ctx context.Context, in *clusterv1.CreateClusterTokenRequest) (*clusterv1.CreateClusterTokenResponse, error) {
+	return invoke(ctx, s.chain, s.impl, clusterv1.ClusterAPI_CreateClusterToken_FullMethodName, in, s.impl.CreateClusterToken)
+}
+
+func (s *clusterService) ListClusterTokens(ctx context.Context, in *clusterv1.ListClusterTokensRequest) (*clusterv1.ListClusterTokensResponse, error) {
+	return invoke(ctx, s.chain, s.impl, clusterv1.ClusterAPI_ListClusterTokens_FullMethodName, in, s.impl.ListClusterTokens)
+}
+
+func (s *clusterService) GetClusterToken(ctx context.Context, in *clusterv1.GetClusterTokenRequest) (*clusterv1.GetClusterTokenResponse, error) {
+	return invoke(ctx, s.chain, s.impl, clusterv1.ClusterAPI_GetClusterToken_FullMethodName, in, s.impl.GetClusterToken)
+}
+
+func (s *clusterService) RevokeClusterToken(ctx context.Context, in *clusterv1.RevokeClusterTokenRequest) (*clusterv1.RevokeClusterTokenResponse, error) {
+	return invoke(ctx, s.chain, s.impl, clusterv1.ClusterAPI_RevokeClusterToken_FullMethodName, in, s.impl.RevokeClusterToken)
+}
+
+func (s *clusterService) ReportClusterStatus(ctx context.Context, in *clusterv1.ReportClusterStatusRequest) (*clusterv1.ReportClusterStatusResponse, error) {
+	return invoke(ctx, s.chain, s.impl, clusterv1.ClusterAPI_ReportClusterStatus_FullMethodName, in, s.impl.ReportClusterStatus)
+}
+
+func (s *clusterService) ListWorkloads(ctx context.Context, in *clusterv1.ListWorkloadsRequest) (*clusterv1.ListWorkloadsResponse, error) {
+	return invoke(ctx, s.chain, s.impl, clusterv1.ClusterAPI_ListWorkloads_FullMethodName, in, s.impl.ListWorkloads)
+}
+
+func (s *clusterService) ReportWorkloadStatus(ctx context.Context, in *clusterv1.ReportWorkloadStatusRequest) (*clusterv1.ReportWorkloadStatusResponse, error) {
+	return invoke(ctx, s.chain, s.impl, clusterv1.ClusterAPI_ReportWorkloadStatus_FullMethodName, in, s.impl.ReportWorkloadStatus)
+}
+
+// connectClusterHandler serves ClusterAPI over Connect, gRPC and gRPC-Web.
+type connectClusterHandler struct {
+	clusterv1connect.UnimplementedClusterAPIHandler
+	s clusterv1.ClusterAPIServer
+}
+
+func (h *connectClusterHandler) CreateCluster(ctx context.Context, req *connect.Request[clusterv1.CreateClusterRequest]) (*connect.Response[clusterv1.CreateClusterResponse], error) {
+	return connectUnary(ctx, req, h.s.CreateCluster)
+}
+
+func (h *connectClusterHandler) GetCluster(ctx context.Context, req *connect.Request[clusterv1.GetClusterRequest]) (*connect.Response[clusterv1.GetClusterResponse], error) {
+	return connectUnary(ctx, req, h.s.GetCluster)
+}
+
+func (h *connectClusterHandler) GetClusterStatus(ctx context.Context, req *connect.Request[clusterv1.GetClusterStatusRequest]) (*connect.Response[clusterv1.GetClusterStatusResponse], error) {
+	return connectUnary(ctx, req, h.s.GetClusterStatus)
+}
+
+func (h *connectClusterHandler) ListClusters(ctx context.Context, req *connect.Request[clusterv1.ListClustersRequest]) (*connect.Response[clusterv1.ListClustersResponse], error) {
+	return connectUnary(ctx, req, h.s.ListClusters)
+}
+
+func (h *connectClusterHandler) UpdateCluster(ctx context.Context, req *connect.Request[clusterv1.UpdateClusterRequest]) (*connect.Response[clusterv1.UpdateClusterResponse], error) {
+	return connectUnary(ctx, req, h.s.UpdateCluster)
+}
+
+func (h *connectClusterHandler) DeleteCluster(ctx context.Context, req *connect.Request[clusterv1.DeleteClusterRequest]) (*connect.Response[clusterv1.DeleteClusterResponse], error) {
+	return connectUnary(ctx, req, h.s.DeleteCluster)
+}
+
+func (h *connectClusterHandler) CreateClusterToken(ctx context.Context, req *connect.Request[clusterv1.CreateClusterTokenRequest]) (*connect.Response[clusterv1.CreateClusterTokenResponse], error) {
+	return connectUnary(ctx, req, h.s.CreateClusterToken)
+}
+
+func (h *connectClusterHandler) ListClusterTokens(ctx context.Context, req *connect.Request[clusterv1.ListClusterTokensRequest]) (*connect.Response[clusterv1.ListClusterTokensResponse], error) {
+	return connectUnary(ctx, req, h.s.ListClusterTokens)
+}
+
+func (h *connectClusterHandler) GetClusterToken(ctx context.Context, req *connect.Request[clusterv1.GetClusterTokenRequest]) (*connect.Response[clusterv1.GetClusterTokenResponse], error) {
+	return connectUnary(ctx, req, h.s.GetClusterToken)
+}
+
+func (h *connectClusterHandler) RevokeClusterToken(ctx context.Context, req *connect.Request[clusterv1.RevokeClusterTokenRequest]) (*connect.Response[clusterv1.RevokeClusterTokenResponse], error) {
+	return connectUnary(ctx, req, h.s.RevokeClusterToken)
+}
+
+func (h *connectClusterHandler) ReportClusterStatus(ctx context.Context, req *connect.Request[clusterv1.ReportClusterStatusRequest]) (*connect.Response[clusterv1.ReportClusterStatusResponse], error) {
+	return connectUnary(ctx, req, h.s.ReportClusterStatus)
+}
+
+func (h *connectClusterHandler) ListWorkloads(ctx context.Context, req *connect.Request[clusterv1.ListWorkloadsRequest]) (*connect.Response[clusterv1.ListWorkloadsResponse], error) {
+	return connectUnary(ctx, req, h.s.ListWorkloads)
+}
+
+func (h *connectClusterHandler) ReportWorkloadStatus(ctx context.Context, req *connect.Request[clusterv1.ReportWorkloadStatusRequest]) (*connect.Response[clusterv1.ReportWorkloadStatusResponse], error) {
+	return connectUnary(ctx, req, h.s.ReportWorkloadStatus)
+}
+
+// healthcheckService runs the HealthcheckAPI methods through the interceptor chain.
+type healthcheckService struct {
+	healthcheckv1.UnimplementedHealthcheckAPIServer
+	impl  healthcheckv1.HealthcheckAPIServer
+	chain grpc.UnaryServerInterceptor
+}
+
+func (s *healthcheckService) Healthcheck(ctx context.Context, in *healthcheckv1.HealthcheckRequest) (*healthcheckv1.HealthcheckResponse, error) {
+	return invoke(ctx, s.chain, s.impl, healthcheckv1.HealthcheckAPI_Healthcheck_FullMethodName, in, s.impl.Healthcheck)
+}
+
+// connectHealthcheckHandler serves HealthcheckAPI over Connect, gRPC and gRPC-Web.
+type connectHealthcheckHandler struct {
+	healthcheckv1connect.UnimplementedHealthcheckAPIHandler
+	s healthcheckv1.HealthcheckAPIServer
+}
+
+func (h *connectHealthcheckHandler) Healthcheck(ctx context.Context, req *connect.Request[healthcheckv1.HealthcheckRequest]) (*connect.Response[healthcheckv1.HealthcheckResponse], error) {
+	return connectUnary(ctx, req, h.s.Healthcheck)
+}
+
+// runnerService runs the RunnerAPI methods through the interceptor chain.
+type runnerService struct {
+	runnerv1.UnimplementedRunnerAPIServer
+	impl  runnerv1.RunnerAPIServer
+	chain grpc.UnaryServerInterceptor
+}
+
+func (s *runnerService) CreateRunner(ctx context.Context, in *runnerv1.CreateRunnerRequest) (*runnerv1.CreateRunnerResponse, error) {
+	return invoke(ctx, s.chain, s.impl, runnerv1.RunnerAPI_CreateRunner_FullMethodName, in, s.impl.CreateRunner)
+}
+
+func (s *runnerService) GetRunner(ctx context.Context, in *runnerv1.GetRunnerRequest) (*runnerv1.GetRunnerResponse, error) {
+	return invoke(ctx, s.chain, s.impl, runnerv1.RunnerAPI_GetRunner_FullMethodName, in, s.impl.GetRunner)
+}
+
+func (s *runnerService) ListRunners(ctx context.Context, in *runnerv1.ListRunnersRequest) (*runnerv1.ListRunnersResponse, error) {
+	return invoke(ctx, s.chain, s.impl, runnerv1.RunnerAPI_ListRunners_FullMethodName, in, s.impl.ListRunners)
+}
+
+func (s *runnerService) UpdateRunner(ctx context.Context, in *runnerv1.UpdateRunnerRequest) (*runnerv1.UpdateRunnerResponse, error) {
+	return invoke(ctx, s.chain, s.impl, runnerv1.RunnerAPI_UpdateRunner_FullMethodName, in, s.impl.UpdateRunner)
+}
+
+func (s *runnerService) DeleteRunner(ctx context.Context, in *runnerv1.DeleteRunnerRequest) (*runnerv1.DeleteRunnerResponse, error) {
+	return invoke(ctx, s.chain, s.impl, runnerv1.RunnerAPI_DeleteRunner_FullMethodName, in, s.impl.DeleteRunner)
+}
+
+func (s *runnerService) CreateRunnerToken(ctx context.Context, in *runnerv1.CreateRunnerTokenRequest) (*runnerv1.CreateRunnerTokenResponse, error) {
+	return invoke(ctx, s.chain, s.impl, runnerv1.RunnerAPI_CreateRunnerToken_FullMethodName, in, s.impl.CreateRunnerToken)
+}
+
+func (s *runnerService) ListRunnerTokens(ctx context.Context, in *runnerv1.ListRunnerTokensRequest) (*runnerv1.ListRunnerTokensResponse, error) {
+	return invoke(ctx, s.chain, s.impl, runnerv1.RunnerAPI_ListRunnerTokens_FullMethodName, in, s.impl.ListRunnerTokens)
+}
+
+func (s *runnerService) GetRunnerToken(ctx context.Context, in *runnerv1.GetRunnerTokenRequest) (*runnerv1.GetRunnerTokenResponse, error) {
+	return invoke(ctx, s.chain, s.impl, runnerv1.RunnerAPI_GetRunnerToken_FullMethodName, in, s.impl.GetRunnerToken)
+}
+
+func (s *runnerService) RevokeRunnerToken(ctx context.Context, in *runnerv1.RevokeRunnerTokenRequest) (*runnerv1.RevokeRunnerTokenResponse, error) {
+	return invoke(ctx, s.chain, s.impl, runnerv1.RunnerAPI_RevokeRunnerToken_FullMethodName, in, s.impl.RevokeRunnerToken)
+}
+
+// connectRunnerHandler serves RunnerAPI over Connect, gRPC and gRPC-Web.
+type connectRunnerHandler struct {
+	runnerv1connect.UnimplementedRunnerAPIHandler
+	s runnerv1.RunnerAPIServer
+}
+
+func (h *connectRunnerHandler) CreateRunner(ctx context.Context, req *connect.Request[runnerv1.CreateRunnerRequest]) (*connect.Response[runnerv1.CreateRunnerResponse], error) {
+	return connectUnary(ctx, req, h.s.CreateRunner)
+}
+
+func (h *connectRunnerHandler) GetRunner(ctx context.Context, req *connect.Request[runnerv1.GetRunnerRequest]) (*connect.Response[runnerv1.GetRunnerResponse], error) {
+	return connectUnary(ctx, req, h.s.GetRunner)
+}
+
+func (h *connectRunnerHandler) ListRunners(ctx context.Context, req *connect.Request[runnerv1.ListRunnersRequest]) (*connect.Response[runnerv1.ListRunnersResponse], error) {
+	return connectUnary(ctx, req, h.s.ListRunners)
+}
+
+func (h *connectRunnerHandler) UpdateRunner(ctx context.Context, req *connect.Request[runnerv1.UpdateRunnerRequest]) (*connect.Response[runnerv1.UpdateRunnerResponse], error) {
+	return connectUnary(ctx, req, h.s.UpdateRunner)
+}
+
+func (h *connectRunnerHandler) DeleteRunner(ctx context.Context, req *connect.Request[runnerv1.DeleteRunnerRequest]) (*connect.Response[runnerv1.DeleteRunnerResponse], error) {
+	return connectUnary(ctx, req, h.s.DeleteRunner)
+}
+
+func (h *connectRunnerHandler) CreateRunnerToken(ctx context.Context, req *connect.Request[runnerv1.CreateRunnerTokenRequest]) (*connect.Response[runnerv1.CreateRunnerTokenResponse], error) {
+	return connectUnary(ctx, req, h.s.CreateRunnerToken)
+}
+
+func (h *connectRunnerHandler) ListRunnerTokens(ctx context.Context, req *connect.Request[runnerv1.ListRunnerTokensRequest]) (*connect.Response[runnerv1.ListRunnerTokensResponse], error) {
+	return connectUnary(ctx, req, h.s.ListRunnerTokens)
+}
+
+func (h *connectRunnerHandler) GetRunnerToken(ctx context.Context, req *connect.Request[runnerv1.GetRunnerTokenRequest]) (*connect.Response[runnerv1.GetRunnerTokenResponse], error) {
+	return connectUnary(ctx, req, h.s.GetRunnerToken)
+}
+
+func (h *connectRunnerHandler) RevokeRunnerToken(ctx context.Context, req *connect.Request[runnerv1.RevokeRunnerTokenRequest]) (*connect.Response[runnerv1.RevokeRunnerTokenResponse], error) {
+	return connectUnary(ctx, req, h.s.RevokeRunnerToken)
+}
+
+// serviceAccountService runs the ServiceAccountAPI methods through the interceptor chain.
+type serviceAccountService struct {
+	serviceaccountv1.UnimplementedServiceAccountAPIServer
+	impl  serviceaccountv1.ServiceAccountAPIServer
+	chain grpc.UnaryServerInterceptor
+}
+
+func (s *serviceAccountService) CreateServiceAccount(ctx context.Context, in *serviceaccountv1.CreateServiceAccountRequest) (*serviceaccountv1.CreateServiceAccountResponse, error) {
+	return invoke(ctx, s.chain, s.impl, serviceaccountv1.ServiceAccountAPI_CreateServiceAccount_FullMethodName, in, s.impl.CreateServiceAccount)
+}
+
+func (s *serviceAccountService) GetServiceAccount(ctx context.Context, in *serviceaccountv1.GetServiceAccountRequest) (*serviceaccountv1.GetServiceAccountResponse, error) {
+	return invoke(ctx, s.chain, s.impl, serviceaccountv1.ServiceAccountAPI_GetServiceAccount_FullMethodName, in, s.impl.GetServiceAccount)
+}
+
+func (s *serviceAccountService) ListServiceAccounts(ctx context.Context, in *serviceaccountv1.ListServiceAccountsRequest) (*serviceaccountv1.ListServiceAccountsResponse, error) {
+	return invoke(ctx, s.chain, s.impl, serviceaccountv1.ServiceAccountAPI_ListServiceAccounts_FullMethodName, in, s.impl.ListServiceAccounts)
+}
+
+func (s *serviceAccountService) UpdateServiceAccount(ctx context.Context, in *serviceaccountv1.UpdateServiceAccountRequest) (*serviceaccountv1.UpdateServiceAccountResponse, error) {
+	return invoke(ctx, s.chain, s.impl, serviceaccountv1.ServiceAccountAPI_UpdateServiceAccount_FullMethodName, in, s.impl.UpdateServiceAccount)
+}
+
+func (s *serviceAccountService) DeleteServiceAccount(ctx context.Context, in *serviceaccountv1.DeleteServiceAccountRequest) (*serviceaccountv1.DeleteServiceAccountResponse, error) {
+	return invoke(ctx, s.chain, s.impl, serviceaccountv1.ServiceAccountAPI_DeleteServiceAccount_FullMethodName, in, s.impl.DeleteServiceAccount)
+}
+
+func (s *serviceAccountService) CreateServiceAccountToken(ctx context.Context, in *serviceaccountv1.CreateServiceAccountTokenRequest) (*serviceaccountv1.CreateServiceAccountTokenResponse, error) {
+	return invoke(ctx, s.chain, s.impl, serviceaccountv1.ServiceAccountAPI_CreateServiceAccountToken_FullMethodName, in, s.impl.CreateServiceAccountToken)
+}
+
+func (s *serviceAccountService) ListServiceAccountTokens(ctx context.Context, in *serviceaccountv1.ListServiceAccountTokensRequest) (*serviceaccountv1.ListServiceAccountTokensResponse, error) {
+	return invoke(ctx, s.chain, s.impl, serviceaccountv1.ServiceAccountAPI_ListServiceAccountTokens_FullMethodName, in, s.impl.ListServiceAccountTokens)
+}
+
+func (s *serviceAccountService) GetServiceAccountToken(ctx context.Context, in *serviceaccountv1.GetServiceAccountTokenRequest) (*serviceaccountv1.GetServiceAccountTokenResponse, error) {
+	return invoke(ctx, s.chain, s.impl, serviceaccountv1.ServiceAccountAPI_GetServiceAccountToken_FullMethodName, in, s.impl.GetServiceAccountToken)
+}
+
+func (s *serviceAccountService) RevokeServiceAccountToken(ctx context.Context, in *serviceaccountv1.RevokeServiceAccountTokenRequest) (*serviceaccountv1.RevokeServiceAccountTokenResponse, error) {
+	return invoke(ctx, s.chain, s.impl, serviceaccountv1.ServiceAccountAPI_RevokeServiceAccountToken_FullMethodName, in, s.impl.RevokeServiceAccountToken)
+}
+
+// connectServiceAccountHandler serves ServiceAccountAPI over Connect, gRPC and gRPC-Web.
+type connectServiceAccountHandler struct {
+	serviceaccountv1connect.UnimplementedServiceAccountAPIHandler
+	s serviceaccountv1.ServiceAccountAPIServer
+}
+
+func (h *connectServiceAccountHandler) CreateServiceAccount(ctx context.Context, req *connect.Request[serviceaccountv1.CreateServiceAccountRequest]) (*connect.Response[serviceaccountv1.CreateServiceAccountResponse], error) {
+	return connectUnary(ctx, req, h.s.CreateServiceAccount)
+}
+
+func (h *connectServiceAccountHandler) GetServiceAccount(ctx context.Context, req *connect.Request[serviceaccountv1.GetServiceAccountRequest]) (*connect.Response[serviceaccountv1.GetServiceAccountResponse], error) {
+	return connectUnary(ctx, req, h.s.GetServiceAccount)
+}
+
+func (h *connectServiceAccountHandler) ListServiceAccounts(ctx context.Context, req *connect.Request[serviceaccountv1.ListServiceAccountsRequest]) (*connect.Response[serviceaccountv1.ListServiceAccountsResponse], error) {
+	return connectUnary(ctx, req, h.s.ListServiceAccounts)
+}
+
+func (h *connectServiceAccountHandler) UpdateServiceAccount(ctx context.Context, req *connect.Request[serviceaccountv1.UpdateServiceAccountRequest]) (*connect.Response[serviceaccountv1.UpdateServiceAccountResponse], error) {
+	return connectUnary(ctx, req, h.s.UpdateServiceAccount)
+}
+
+func (h *connectServiceAccountHandler) DeleteServiceAccount(ctx context.Context, req *connect.Request[serviceaccountv1.DeleteServiceAccountRequest]) (*connect.Response[serviceaccountv1.DeleteServiceAccountResponse], error) {
+	return connectUnary(ctx, req, h.s.DeleteServiceAccount)
+}
+
+func (h *connectServiceAccountHandler) CreateServiceAccountToken(ctx context.Context, req *connect.Request[serviceaccountv1.CreateServiceAccountTokenRequest]) (*connect.Response[serviceaccountv1.CreateServiceAccountTokenResponse], error) {
+	return connectUnary(ctx, req, h.s.CreateServiceAccountToken)
+}
+
+func (h *connectServiceAccountHandler) ListServiceAccountTokens(ctx context.Context, req *connect.Request[serviceaccountv1.ListServiceAccountTokensRequest]) (*connect.Response[serviceaccountv1.ListServiceAccountTokensResponse], error) {
+	return connectUnary(ctx, req, h.s.ListServiceAccountTokens)
+}
+
+func (h *connectServiceAccountHandler) GetServiceAccountToken(ctx context.Context, req *connect.Request[serviceaccountv1.GetServiceAccountTokenRequest]) (*connect.Response[serviceaccountv1.GetServiceAccountTokenResponse], error) {
+	return connectUnary(ctx, req, h.s.GetServiceAccountToken)
+}
+
+func (h *connectServiceAccountHandler) RevokeServiceAccountToken(ctx context.Context, req *connect.Request[serviceaccountv1.RevokeServiceAccountTokenRequest]) (*connect.Response[serviceaccountv1.RevokeServiceAccountTokenResponse], error) {
+	return connectUnary(ctx, req, h.s.RevokeServiceAccountToken)
+}
+
+// userService runs the UserAPI methods through the interceptor chain.
+type userService struct {
+	userv1.UnimplementedUserAPIServer
+	impl  userv1.UserAPIServer
+	chain grpc.UnaryServerInterceptor
+}
+
+func (s *userService) GetUser(ctx context.Context, in *userv1.GetUserRequest) (*userv1.GetUserResponse, error) {
+	return invoke(ctx, s.chain, s.impl, userv1.UserAPI_GetUser_FullMethodName, in, s.impl.GetUser)
+}
+
+func (s *userService) CreatePersonalAccessToken(ctx context.Context, in *userv1.CreatePersonalAccessTokenRequest) (*userv1.CreatePersonalAccessTokenResponse, error) {
+	return invoke(ctx, s.chain, s.impl, userv1.UserAPI_CreatePersonalAccessToken_FullMethodName, in, s.impl.CreatePersonalAccessToken)
+}
+
+func (s *userService) ListPersonalAccessTokens(ctx context.Context, in *userv1.ListPersonalAccessTokensRequest) (*userv1.ListPersonalAccessTokensResponse, error) {
+	return invoke(ctx, s.chain, s.impl, userv1.UserAPI_ListPersonalAccessTokens_FullMethodName, in, s.impl.ListPersonalAccessTokens)
+}
+
+func (s *userService) GetPersonalAccessToken(ctx context.Context, in *userv1.GetPersonalAccessTokenRequest) (*userv1.GetPersonalAccessTokenResponse, error) {
+	return invoke(ctx, s.chain, s.impl, userv1.UserAPI_GetPersonalAccessToken_FullMethodName, in, s.impl.GetPersonalAccessToken)
+}
+
+func (s *userService) RevokePersonalAccessToken(ctx context.Context, in *userv1.RevokePersonalAccessTokenRequest) (*userv1.RevokePersonalAccessTokenResponse, error) {
+	return invoke(ctx, s.chain, s.impl, userv1.UserAPI_RevokePersonalAccessToken_FullMethodName, in, s.impl.RevokePersonalAccessToken)
+}
+
+// connectUserHandler serves UserAPI over Connect, gRPC and gRPC-Web.
+type connectUserHandler struct {
+	userv1connect.UnimplementedUserAPIHandler
+	s userv1.UserAPIServer
+}
+
+func (h *connectUserHandler) GetUser(ctx context.Context, req *connect.Request[userv1.GetUserRequest]) (*connect.Response[userv1.GetUserResponse], error) {
+	return connectUnary(ctx, req, h.s.GetUser)
+}
+
+func (h *connectUserHandler) CreatePersonalAccessToken(ctx context.Context, req *connect.Request[userv1.CreatePersonalAccessTokenRequest]) (*connect.Response[userv1.CreatePersonalAccessTokenResponse], error) {
+	return connectUnary(ctx, req, h.s.CreatePersonalAccessToken)
+}
+
+func (h *connectUserHandler) ListPersonalAccessTokens(ctx context.Context, req *connect.Request[userv1.ListPersonalAccessTokensRequest]) (*connect.Response[userv1.ListPersonalAccessTokensResponse], error) {
+	return connectUnary(ctx, req, h.s.ListPersonalAccessTokens)
+}
+
+func (h *connectUserHandler) GetPersonalAccessToken(ctx context.Context, req *connect.Request[userv1.GetPersonalAccessTokenRequest]) (*connect.Response[userv1.GetPersonalAccessTokenResponse], error) {
+	return connectUnary(ctx, req, h.s.GetPersonalAccessToken)
+}
+
+func (h *connectUserHandler) RevokePersonalAccessToken(ctx context.Context, req *connect.Request[userv1.RevokePersonalAccessTokenRequest]) (*connect.Response[userv1.RevokePersonalAccessTokenResponse], error) {
+	return connectUnary(ctx, req, h.s.RevokePersonalAccessToken)
+}